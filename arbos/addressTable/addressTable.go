@@ -8,6 +8,7 @@ package addressTable
 import (
 	"bytes"
 	"errors"
+	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -55,6 +56,20 @@ func (atab *AddressTable) Register(addr common.Address) (uint64, error) {
 	return newNumItems - 1, nil
 }
 
+// SkipTo makes the next registered address get the given index, which mustn't be below the size of
+// the table. The indices skipped over hold the zero address. It's meant for initializing a shard of
+// a table whose lower indices are held elsewhere.
+func (atab *AddressTable) SkipTo(index uint64) error {
+	numItems, err := atab.numItems.Get()
+	if err != nil {
+		return err
+	}
+	if index < numItems {
+		return fmt.Errorf("can't skip to index %d of an address table with %d items", index, numItems)
+	}
+	return atab.numItems.Set(index)
+}
+
 func (atab *AddressTable) Lookup(addr common.Address) (uint64, bool, error) {
 	addrAsHash := common.BytesToHash(addr.Bytes())
 	res, err := atab.byAddress.GetUint64(addrAsHash)
@@ -5,18 +5,29 @@ package arbosState
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
 	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/triedb"
 
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/arbos/util"
 	"github.com/offchainlabs/nitro/cmd/chaininfo"
 	"github.com/offchainlabs/nitro/statetransfer"
 	"github.com/offchainlabs/nitro/util/testhelpers"
@@ -74,12 +85,372 @@ func tryMarshalUnmarshal(input *statetransfer.ArbosInitializationInfo, t *testin
 
 	arbState, err := OpenArbosState(stateDb, &burn.SystemBurner{})
 	Require(t, err)
-	checkAddressTable(arbState, input.AddressTableContents, t)
+	checkAddressTable(arbState, 0, input.AddressTableContents, t)
 	checkRetryables(arbState, input.RetryableData, t)
 	checkAccounts(stateDb, arbState, input.Accounts, t)
 	checkFeatures(t, arbState)
 }
 
+func TestPrecompileStorageOverrides(t *testing.T) {
+	// The per unit reward lives at offset 3 of the L1 pricing subspace.
+	perUnitRewardSlot := storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)).OpenSubStorage(l1PricingSubspace).GetStorageSlot(util.UintToHash(3))
+	initData := statetransfer.ArbosInitializationInfo{
+		PrecompileStorageOverrides: map[common.Address]map[common.Hash]common.Hash{
+			types.ArbosStateAddress: {perUnitRewardSlot: util.UintToHash(12345)},
+		},
+	}
+
+	raw := rawdb.NewMemoryDatabase()
+	chainConfig := chaininfo.ArbitrumDevTestChainConfig()
+	cacheConfig := core.DefaultCacheConfigWithScheme(env.GetTestStateScheme())
+	stateroot, err := InitializeArbosInDatabase(raw, cacheConfig, statetransfer.NewMemoryInitDataReader(&initData), chainConfig, nil, arbostypes.TestInitMessage, 0, 0)
+	Require(t, err)
+	stateDb, err := state.New(stateroot, state.NewDatabase(triedb.NewDatabase(raw, cacheConfig.TriedbConfig()), nil))
+	Require(t, err)
+
+	arbState, err := OpenArbosState(stateDb, &burn.SystemBurner{})
+	Require(t, err)
+	perUnitReward, err := arbState.L1PricingState().PerUnitReward()
+	Require(t, err)
+	if perUnitReward != 12345 {
+		Fail(t, "storage override not applied, got per unit reward", perUnitReward)
+	}
+
+	// Overrides for accounts that aren't precompiles are rejected.
+	initData.PrecompileStorageOverrides[common.HexToAddress("0x1234")] = map[common.Hash]common.Hash{{}: util.UintToHash(1)}
+	_, err = InitializeArbosInDatabase(rawdb.NewMemoryDatabase(), cacheConfig, statetransfer.NewMemoryInitDataReader(&initData), chainConfig, nil, arbostypes.TestInitMessage, 0, 0)
+	if err == nil {
+		Fail(t, "expected override of a non-precompile address to be rejected")
+	}
+}
+
+func TestComputeGenesisStateRoot(t *testing.T) {
+	fixture := func() *statetransfer.ArbosInitializationInfo {
+		prand := testhelpers.NewPseudoRandomDataSource(t, 2)
+		return &statetransfer.ArbosInitializationInfo{
+			AddressTableContents: []common.Address{prand.GetAddress(), prand.GetAddress()},
+			Accounts:             []statetransfer.AccountInitializationInfo{pseudorandomAccountInitInfoForTesting(prand)},
+			ChainOwner:           prand.GetAddress(),
+		}
+	}
+	chainConfig := chaininfo.ArbitrumDevTestChainConfig()
+
+	root, err := ComputeGenesisStateRoot(statetransfer.NewMemoryInitDataReader(fixture()), chainConfig, arbostypes.TestInitMessage)
+	Require(t, err)
+	if root == (common.Hash{}) || root == types.EmptyRootHash {
+		Fail(t, "unexpected genesis state root", root)
+	}
+	again, err := ComputeGenesisStateRoot(statetransfer.NewMemoryInitDataReader(fixture()), chainConfig, arbostypes.TestInitMessage)
+	Require(t, err)
+	if again != root {
+		Fail(t, "genesis state root isn't stable:", root, again)
+	}
+
+	// The root matches the one of a chain actually initialized from the same data.
+	cacheConfig := core.DefaultCacheConfigWithScheme(env.GetTestStateScheme())
+	initialized, err := InitializeArbosInDatabase(rawdb.NewMemoryDatabase(), cacheConfig, statetransfer.NewMemoryInitDataReader(fixture()), chainConfig, nil, arbostypes.TestInitMessage, 0, 0)
+	Require(t, err)
+	if initialized != root {
+		Fail(t, "computed genesis state root", root, "doesn't match initialized database", initialized)
+	}
+
+	changed := fixture()
+	changed.ChainOwner = common.Address{}
+	other, err := ComputeGenesisStateRoot(statetransfer.NewMemoryInitDataReader(changed), chainConfig, arbostypes.TestInitMessage)
+	Require(t, err)
+	if other == root {
+		Fail(t, "expected different init data to produce a different genesis state root")
+	}
+}
+
+// writeJsonList writes the values as a JSON list file of the statetransfer JSON init data reader.
+func writeJsonList[T any](t *testing.T, path string, values []T) {
+	t.Helper()
+	file, err := os.Create(path)
+	Require(t, err)
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, value := range values {
+		Require(t, encoder.Encode(value))
+	}
+}
+
+func TestShardedJsonInitData(t *testing.T) {
+	prand := testhelpers.NewPseudoRandomDataSource(t, 4)
+	var addresses []common.Address
+	var accounts []statetransfer.AccountInitializationInfoJson
+	for i := 0; i < 24; i++ {
+		addresses = append(addresses, prand.GetAddress())
+		account := pseudorandomAccountInitInfoForTesting(prand)
+		accounts = append(accounts, statetransfer.AccountInitializationInfoJson{
+			Addr:         account.Addr,
+			Nonce:        account.Nonce,
+			Balance:      account.EthBalance.String(),
+			ContractInfo: account.ContractInfo,
+		})
+	}
+
+	dir := t.TempDir()
+	writeJsonList(t, filepath.Join(dir, "addresses.json"), addresses)
+	writeJsonList(t, filepath.Join(dir, "accounts.json"), accounts)
+	writeInitFile := func(name string, contents statetransfer.ArbosInitFileContents) string {
+		t.Helper()
+		data, err := json.Marshal(&contents)
+		Require(t, err)
+		initFile := filepath.Join(dir, name)
+		Require(t, os.WriteFile(initFile, data, 0600))
+		return initFile
+	}
+	singleFile := writeInitFile("init.json", statetransfer.ArbosInitFileContents{
+		AddressTableContentsPath: "addresses.json",
+		AccountsPath:             "accounts.json",
+	})
+
+	// Twelve address table shards in a directory, so that the address indices depend on comparing
+	// the numbers in their names, and account shards listed explicitly.
+	Require(t, os.Mkdir(filepath.Join(dir, "addresses"), 0700))
+	for shard := 0; shard < 12; shard++ {
+		writeJsonList(t, filepath.Join(dir, "addresses", fmt.Sprintf("addresses-%d.json", shard)), addresses[shard*2:shard*2+2])
+	}
+	writeJsonList(t, filepath.Join(dir, "accounts-0.json"), accounts[:10])
+	writeJsonList(t, filepath.Join(dir, "accounts-1.json"), accounts[10:])
+	shardedFile := writeInitFile("init-sharded.json", statetransfer.ArbosInitFileContents{
+		AddressTableContentsShards: []string{"addresses"},
+		AccountsShards:             []string{"accounts-0.json", "accounts-1.json"},
+	})
+
+	chainConfig := chaininfo.ArbitrumDevTestChainConfig()
+	stateRoot := func(initFile string) common.Hash {
+		t.Helper()
+		reader, err := statetransfer.NewJsonInitDataReader(initFile)
+		Require(t, err)
+		root, err := ComputeGenesisStateRoot(reader, chainConfig, arbostypes.TestInitMessage)
+		Require(t, err)
+		return root
+	}
+	if single, sharded := stateRoot(singleFile), stateRoot(shardedFile); single != sharded {
+		Fail(t, "sharded init data state root", sharded, "doesn't match single file state root", single)
+	}
+}
+
+func TestInitContractCodeByHash(t *testing.T) {
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+	codeHash := crypto.Keccak256Hash(code)
+	addr := common.HexToAddress("0x1234")
+	account := func(contractInfo statetransfer.AccountInitContractInfo) statetransfer.AccountInitializationInfo {
+		return statetransfer.AccountInitializationInfo{
+			Addr:         addr,
+			EthBalance:   big.NewInt(1),
+			ContractInfo: &contractInfo,
+		}
+	}
+	chainConfig := chaininfo.ArbitrumDevTestChainConfig()
+	inlineRoot, err := ComputeGenesisStateRoot(statetransfer.NewMemoryInitDataReader(&statetransfer.ArbosInitializationInfo{
+		Accounts: []statetransfer.AccountInitializationInfo{account(statetransfer.AccountInitContractInfo{Code: code})},
+	}), chainConfig, arbostypes.TestInitMessage)
+	Require(t, err)
+
+	byHash := &statetransfer.ArbosInitializationInfo{
+		Accounts:  []statetransfer.AccountInitializationInfo{account(statetransfer.AccountInitContractInfo{CodeHash: &codeHash})},
+		CodeBlobs: map[common.Hash][]byte{codeHash: code},
+	}
+	raw := rawdb.NewMemoryDatabase()
+	cacheConfig := core.DefaultCacheConfigWithScheme(env.GetTestStateScheme())
+	root, err := InitializeArbosInDatabase(raw, cacheConfig, statetransfer.NewMemoryInitDataReader(byHash), chainConfig, nil, arbostypes.TestInitMessage, 0, 0)
+	Require(t, err)
+	statedb, err := state.New(root, state.NewDatabase(triedb.NewDatabase(raw, cacheConfig.TriedbConfig()), nil))
+	Require(t, err)
+	if !bytes.Equal(statedb.GetCode(addr), code) {
+		Fail(t, "code referenced by hash not set, got", statedb.GetCode(addr))
+	}
+	if root != inlineRoot {
+		Fail(t, "state root with code referenced by hash", root, "doesn't match inline code state root", inlineRoot)
+	}
+
+	// Code blobs can also be read from a directory next to a JSON init file.
+	dir := t.TempDir()
+	Require(t, os.Mkdir(filepath.Join(dir, "code"), 0700))
+	Require(t, os.WriteFile(filepath.Join(dir, "code", hex.EncodeToString(codeHash.Bytes())), code, 0600))
+	writeJsonList(t, filepath.Join(dir, "accounts.json"), []statetransfer.AccountInitializationInfoJson{{
+		Addr:         addr,
+		Balance:      "1",
+		ContractInfo: &statetransfer.AccountInitContractInfo{CodeHash: &codeHash},
+	}})
+	initFileContents, err := json.Marshal(&statetransfer.ArbosInitFileContents{AccountsPath: "accounts.json", CodeBlobsDir: "code"})
+	Require(t, err)
+	Require(t, os.WriteFile(filepath.Join(dir, "init.json"), initFileContents, 0600))
+	jsonReader, err := statetransfer.NewJsonInitDataReader(filepath.Join(dir, "init.json"))
+	Require(t, err)
+	jsonRoot, err := ComputeGenesisStateRoot(jsonReader, chainConfig, arbostypes.TestInitMessage)
+	Require(t, err)
+	if jsonRoot != inlineRoot {
+		Fail(t, "state root with code blobs read from a directory", jsonRoot, "doesn't match inline code state root", inlineRoot)
+	}
+
+	otherCode := []byte{0x60, 0x01, 0x60, 0x00, 0xf3}
+	failing := map[string]*statetransfer.ArbosInitializationInfo{
+		"mismatched code blob": {
+			Accounts:  byHash.Accounts,
+			CodeBlobs: map[common.Hash][]byte{codeHash: otherCode},
+		},
+		"mismatched inline code": {
+			Accounts: []statetransfer.AccountInitializationInfo{account(statetransfer.AccountInitContractInfo{Code: otherCode, CodeHash: &codeHash})},
+		},
+		"missing code blob": {
+			Accounts: byHash.Accounts,
+		},
+	}
+	for name, initData := range failing {
+		if _, err := ComputeGenesisStateRoot(statetransfer.NewMemoryInitDataReader(initData), chainConfig, arbostypes.TestInitMessage); err == nil {
+			Fail(t, "expected init data with", name, "to be rejected")
+		}
+	}
+}
+
+func TestCompressedJsonInitData(t *testing.T) {
+	prand := testhelpers.NewPseudoRandomDataSource(t, 5)
+	initData := &statetransfer.ArbosInitializationInfo{
+		NextBlockNumber:      3,
+		AddressTableContents: []common.Address{prand.GetAddress(), prand.GetAddress()},
+		RetryableData:        []statetransfer.InitializationDataForRetryable{pseudorandomRetryableInitForTesting(prand)},
+	}
+	for i := 0; i < 5; i++ {
+		// The JSON format doesn't include aggregator info.
+		account := pseudorandomAccountInitInfoForTesting(prand)
+		initData.Accounts = append(initData.Accounts, statetransfer.AccountInitializationInfo{
+			Addr:         account.Addr,
+			Nonce:        account.Nonce,
+			EthBalance:   account.EthBalance,
+			ContractInfo: account.ContractInfo,
+		})
+	}
+	code := prand.GetData(64)
+	codeHash := crypto.Keccak256Hash(code)
+	initData.CodeBlobs = map[common.Hash][]byte{codeHash: code}
+	initData.Accounts[0].ContractInfo = &statetransfer.AccountInitContractInfo{CodeHash: &codeHash}
+
+	plainDir, compressedDir := t.TempDir(), t.TempDir()
+	plainFile, err := statetransfer.WriteJsonInitData(plainDir, initData, statetransfer.NoInitDataCompression)
+	Require(t, err)
+	compressedFile, err := statetransfer.WriteJsonInitData(compressedDir, initData, statetransfer.GzipInitDataCompression)
+	Require(t, err)
+
+	// Apart from the paths the init file references, compressed files decompress to the plain files.
+	for _, name := range []string{"addresses.json", "retryables.json", "accounts.json", filepath.Join("code", hex.EncodeToString(codeHash.Bytes()))} {
+		plain, err := os.ReadFile(filepath.Join(plainDir, name))
+		Require(t, err)
+		compressedName := name
+		if filepath.Ext(name) == ".json" {
+			compressedName += ".gz"
+		}
+		compressed, err := os.ReadFile(filepath.Join(compressedDir, compressedName))
+		Require(t, err)
+		decompressor, err := gzip.NewReader(bytes.NewReader(compressed))
+		Require(t, err)
+		decompressed, err := io.ReadAll(decompressor)
+		Require(t, err)
+		if !bytes.Equal(decompressed, plain) {
+			Fail(t, name, "doesn't decompress to the uncompressed export")
+		}
+	}
+
+	chainConfig := chaininfo.ArbitrumDevTestChainConfig()
+	expected, err := ComputeGenesisStateRoot(statetransfer.NewMemoryInitDataReader(initData), chainConfig, arbostypes.TestInitMessage)
+	Require(t, err)
+	for _, initFile := range []string{plainFile, compressedFile} {
+		reader, err := statetransfer.NewJsonInitDataReader(initFile)
+		Require(t, err)
+		root, err := ComputeGenesisStateRoot(reader, chainConfig, arbostypes.TestInitMessage)
+		Require(t, err)
+		if root != expected {
+			Fail(t, "state root of", initFile, "is", root, "expected", expected)
+		}
+	}
+}
+
+func TestInitializeAddressTableBase(t *testing.T) {
+	prand := testhelpers.NewPseudoRandomDataSource(t, 5)
+	const base = 100
+	initData := &statetransfer.ArbosInitializationInfo{
+		AddressTableContents: []common.Address{prand.GetAddress(), prand.GetAddress(), prand.GetAddress()},
+	}
+	raw := rawdb.NewMemoryDatabase()
+	cacheConfig := core.DefaultCacheConfigWithScheme(env.GetTestStateScheme())
+	root, err := InitializeArbosInDatabaseWithAddressTableBase(raw, cacheConfig, statetransfer.NewMemoryInitDataReader(initData), chaininfo.ArbitrumDevTestChainConfig(), nil, arbostypes.TestInitMessage, 0, 0, base)
+	Require(t, err)
+
+	stateDb, err := state.New(root, state.NewDatabase(triedb.NewDatabase(raw, cacheConfig.TriedbConfig()), nil))
+	Require(t, err)
+	arbState, err := OpenArbosState(stateDb, &burn.SystemBurner{})
+	Require(t, err)
+	checkAddressTable(arbState, base, initData.AddressTableContents, t)
+	// The indices below the base are held by other shards.
+	addr, exists, err := arbState.AddressTable().LookupIndex(base - 1)
+	Require(t, err)
+	if !exists || addr != (common.Address{}) {
+		Fail(t, "expected the index below the base to hold the zero address, got", addr, exists)
+	}
+}
+
+func TestAppendInitDataToDatabase(t *testing.T) {
+	prand := testhelpers.NewPseudoRandomDataSource(t, 3)
+	original := &statetransfer.ArbosInitializationInfo{
+		AddressTableContents: []common.Address{prand.GetAddress()},
+		RetryableData:        []statetransfer.InitializationDataForRetryable{pseudorandomRetryableInitForTesting(prand)},
+		Accounts:             []statetransfer.AccountInitializationInfo{pseudorandomAccountInitInfoForTesting(prand)},
+	}
+	appended := &statetransfer.ArbosInitializationInfo{
+		AddressTableContents: []common.Address{prand.GetAddress(), prand.GetAddress()},
+		RetryableData:        []statetransfer.InitializationDataForRetryable{pseudorandomRetryableInitForTesting(prand)},
+		Accounts:             []statetransfer.AccountInitializationInfo{pseudorandomAccountInitInfoForTesting(prand)},
+	}
+
+	raw := rawdb.NewMemoryDatabase()
+	chainConfig := chaininfo.ArbitrumDevTestChainConfig()
+	cacheConfig := core.DefaultCacheConfigWithScheme(env.GetTestStateScheme())
+	root, err := InitializeArbosInDatabase(raw, cacheConfig, statetransfer.NewMemoryInitDataReader(original), chainConfig, nil, arbostypes.TestInitMessage, 0, 0)
+	Require(t, err)
+	appendedRoot, err := AppendInitDataToDatabase(raw, cacheConfig, root, statetransfer.NewMemoryInitDataReader(appended), chainConfig, 0, 0, AppendConflictError)
+	Require(t, err)
+
+	stateDb, err := state.New(appendedRoot, state.NewDatabase(triedb.NewDatabase(raw, cacheConfig.TriedbConfig()), nil))
+	Require(t, err)
+	arbState, err := OpenArbosState(stateDb, &burn.SystemBurner{})
+	Require(t, err)
+	// The appended addresses extend the address table rather than replacing it.
+	checkAddressTable(arbState, 0, append(original.AddressTableContents, appended.AddressTableContents...), t)
+	checkRetryables(arbState, append(original.RetryableData, appended.RetryableData...), t)
+	checkAccounts(stateDb, arbState, append(original.Accounts, appended.Accounts...), t)
+
+	// Appending data the state already has fails by default.
+	conflicting := &statetransfer.ArbosInitializationInfo{Accounts: original.Accounts}
+	if _, err := AppendInitDataToDatabase(raw, cacheConfig, appendedRoot, statetransfer.NewMemoryInitDataReader(conflicting), chainConfig, 0, 0, AppendConflictError); err == nil {
+		Fail(t, "expected appending an existing account to fail")
+	}
+	conflictingAddress := &statetransfer.ArbosInitializationInfo{AddressTableContents: original.AddressTableContents}
+	if _, err := AppendInitDataToDatabase(raw, cacheConfig, appendedRoot, statetransfer.NewMemoryInitDataReader(conflictingAddress), chainConfig, 0, 0, AppendConflictError); err == nil {
+		Fail(t, "expected appending an address already in the address table to fail")
+	}
+
+	// With overwriting, existing accounts take the appended values and existing addresses keep their index.
+	replacement := pseudorandomAccountInitInfoForTesting(prand)
+	replacement.Addr = original.Accounts[0].Addr
+	overwriting := &statetransfer.ArbosInitializationInfo{
+		AddressTableContents: original.AddressTableContents,
+		Accounts:             []statetransfer.AccountInitializationInfo{replacement},
+	}
+	overwrittenRoot, err := AppendInitDataToDatabase(raw, cacheConfig, appendedRoot, statetransfer.NewMemoryInitDataReader(overwriting), chainConfig, 0, 0, AppendConflictOverwrite)
+	Require(t, err)
+	stateDb, err = state.New(overwrittenRoot, state.NewDatabase(triedb.NewDatabase(raw, cacheConfig.TriedbConfig()), nil))
+	Require(t, err)
+	arbState, err = OpenArbosState(stateDb, &burn.SystemBurner{})
+	Require(t, err)
+	checkAddressTable(arbState, 0, append(original.AddressTableContents, appended.AddressTableContents...), t)
+	if stateDb.GetBalance(replacement.Addr).ToBig().Cmp(replacement.EthBalance) != 0 || stateDb.GetNonce(replacement.Addr) != replacement.Nonce {
+		Fail(t, "existing account wasn't overwritten")
+	}
+}
+
 func checkFeatures(t *testing.T, arbState *ArbosState) {
 	t.Helper()
 	want := false
@@ -154,16 +525,18 @@ func pseudorandomHashHashMapForTesting(prand *testhelpers.PseudoRandomDataSource
 	return ret
 }
 
-func checkAddressTable(arbState *ArbosState, addrTable []common.Address, t *testing.T) {
+// checkAddressTable checks the address table holds addrTable at the indices starting at base.
+func checkAddressTable(arbState *ArbosState, base uint64, addrTable []common.Address, t *testing.T) {
 	atab := arbState.AddressTable()
 	atabSize, err := atab.Size()
 	Require(t, err)
-	if atabSize != uint64(len(addrTable)) {
+	if atabSize != base+uint64(len(addrTable)) {
 		Fail(t)
 	}
 	for i, addr := range addrTable {
 		// #nosec G115
-		res, exists, err := atab.LookupIndex(uint64(i))
+		index := base + uint64(i)
+		res, exists, err := atab.LookupIndex(index)
 		Require(t, err)
 		if !exists {
 			Fail(t)
@@ -171,104 +544,275 @@ func checkAddressTable(arbState *ArbosState, addrTable []common.Address, t *test
 		if res != addr {
 			Fail(t)
 		}
+		lookedUp, exists, err := atab.Lookup(addr)
+		Require(t, err)
+		if !exists || lookedUp != index {
+			Fail(t, "address", addr, "found at index", lookedUp, "expected", index)
+		}
 	}
 }
 
 func checkRetryables(arbState *ArbosState, expected []statetransfer.InitializationDataForRetryable, t *testing.T) {
-	ret := arbState.RetryableState()
+	t.Helper()
 	for _, exp := range expected {
-		found, err := ret.OpenRetryable(exp.Id, 0)
-		Require(t, err)
-		if found == nil {
-			Fail(t)
-		}
+		Require(t, verifyRetryable(arbState, exp))
+	}
+}
 
-		// Detailed comparison
-		from, err := found.From()
-		Require(t, err)
-		if from != exp.From {
-			t.Fatalf("Retryable %v: from mismatch. Expected %v, got %v", exp.Id, exp.From, from)
-		}
+// checkRetryablesConcurrently is checkRetryables, reading the retryables with a pool of workers.
+func checkRetryablesConcurrently(db *state.StateDB, expected []statetransfer.InitializationDataForRetryable, workers int, t *testing.T) {
+	t.Helper()
+	Require(t, verifyConcurrently(db, len(expected), workers, func(_ *state.StateDB, arbState *ArbosState, i int) error {
+		return verifyRetryable(arbState, expected[i])
+	}))
+}
 
-		to, err := found.To()
-		Require(t, err)
-		if (to == nil && exp.To != common.Address{}) || (to != nil && exp.To == common.Address{}) || (to != nil && exp.To != common.Address{} && *to != exp.To) {
-			t.Fatalf("Retryable %v: to mismatch. Expected %v, got %v", exp.Id, exp.To, to)
-		}
+func verifyRetryable(arbState *ArbosState, exp statetransfer.InitializationDataForRetryable) error {
+	found, err := arbState.RetryableState().OpenRetryable(exp.Id, 0)
+	if err != nil {
+		return err
+	}
+	if found == nil {
+		return fmt.Errorf("retryable %v not found", exp.Id)
+	}
 
-		callvalue, err := found.Callvalue()
-		Require(t, err)
-		if callvalue.Cmp(exp.Callvalue) != 0 {
-			t.Fatalf("Retryable %v: callvalue mismatch. Expected %v, got %v", exp.Id, exp.Callvalue, callvalue)
-		}
+	// Detailed comparison
+	from, err := found.From()
+	if err != nil {
+		return err
+	}
+	if from != exp.From {
+		return fmt.Errorf("retryable %v: from mismatch. Expected %v, got %v", exp.Id, exp.From, from)
+	}
 
-		beneficiary, err := found.Beneficiary()
-		Require(t, err)
-		if beneficiary != exp.Beneficiary {
-			t.Fatalf("Retryable %v: beneficiary mismatch. Expected %v, got %v", exp.Id, exp.Beneficiary, beneficiary)
-		}
+	to, err := found.To()
+	if err != nil {
+		return err
+	}
+	if (to == nil && exp.To != common.Address{}) || (to != nil && exp.To == common.Address{}) || (to != nil && exp.To != common.Address{} && *to != exp.To) {
+		return fmt.Errorf("retryable %v: to mismatch. Expected %v, got %v", exp.Id, exp.To, to)
+	}
 
-		calldata, err := found.Calldata()
-		Require(t, err)
-		if !bytes.Equal(calldata, exp.Calldata) {
-			t.Fatalf("Retryable %v: calldata mismatch. Expected %v, got %v", exp.Id, exp.Calldata, calldata)
-		}
+	callvalue, err := found.Callvalue()
+	if err != nil {
+		return err
+	}
+	if callvalue.Cmp(exp.Callvalue) != 0 {
+		return fmt.Errorf("retryable %v: callvalue mismatch. Expected %v, got %v", exp.Id, exp.Callvalue, callvalue)
+	}
 
-		timeout, err := found.CalculateTimeout()
-		Require(t, err)
-		if timeout != exp.Timeout {
-			t.Fatalf("Retryable %v: timeout mismatch. Expected %v, got %v", exp.Id, exp.Timeout, timeout)
-		}
+	beneficiary, err := found.Beneficiary()
+	if err != nil {
+		return err
 	}
+	if beneficiary != exp.Beneficiary {
+		return fmt.Errorf("retryable %v: beneficiary mismatch. Expected %v, got %v", exp.Id, exp.Beneficiary, beneficiary)
+	}
+
+	calldata, err := found.Calldata()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(calldata, exp.Calldata) {
+		return fmt.Errorf("retryable %v: calldata mismatch. Expected %v, got %v", exp.Id, exp.Calldata, calldata)
+	}
+
+	timeout, err := found.CalculateTimeout()
+	if err != nil {
+		return err
+	}
+	if timeout != exp.Timeout {
+		return fmt.Errorf("retryable %v: timeout mismatch. Expected %v, got %v", exp.Id, exp.Timeout, timeout)
+	}
+	return nil
 }
 
 func checkAccounts(db *state.StateDB, arbState *ArbosState, accts []statetransfer.AccountInitializationInfo, t *testing.T) {
-	l1p := arbState.L1PricingState()
-	posterTable := l1p.BatchPosterTable()
+	t.Helper()
 	for _, acct := range accts {
-		addr := acct.Addr
-		if db.GetNonce(addr) != acct.Nonce {
-			t.Fatal()
-		}
-		if db.GetBalance(addr).ToBig().Cmp(acct.EthBalance) != 0 {
-			t.Fatal()
+		Require(t, verifyAccount(db, arbState, acct))
+	}
+}
+
+// checkAccountsConcurrently is checkAccounts, reading the accounts with a pool of workers.
+func checkAccountsConcurrently(db *state.StateDB, accts []statetransfer.AccountInitializationInfo, workers int, t *testing.T) {
+	t.Helper()
+	Require(t, verifyConcurrently(db, len(accts), workers, func(db *state.StateDB, arbState *ArbosState, i int) error {
+		return verifyAccount(db, arbState, accts[i])
+	}))
+}
+
+func verifyAccount(db *state.StateDB, arbState *ArbosState, acct statetransfer.AccountInitializationInfo) error {
+	addr := acct.Addr
+	if nonce := db.GetNonce(addr); nonce != acct.Nonce {
+		return fmt.Errorf("account %v: nonce mismatch. Expected %v, got %v", addr, acct.Nonce, nonce)
+	}
+	if balance := db.GetBalance(addr).ToBig(); balance.Cmp(acct.EthBalance) != 0 {
+		return fmt.Errorf("account %v: balance mismatch. Expected %v, got %v", addr, acct.EthBalance, balance)
+	}
+	if acct.ContractInfo != nil {
+		if !bytes.Equal(acct.ContractInfo.Code, db.GetCode(addr)) {
+			return fmt.Errorf("account %v: code mismatch", addr)
 		}
-		if acct.ContractInfo != nil {
-			if !bytes.Equal(acct.ContractInfo.Code, db.GetCode(addr)) {
-				t.Fatal()
-			}
-			err := state.ForEachStorage(db, addr, func(key common.Hash, value common.Hash) bool {
-				if key == (common.Hash{}) {
-					// Unfortunately, geth doesn't seem capable of giving us storage keys any more.
-					// Even with the triedb Preimages set to true, it doesn't record the necessary
-					// hashed storage key -> raw storage key mapping. This means that geth will always
-					// give us an empty storage key when iterating, which we can't validate.
-					return true
-				}
-				val2, exists := acct.ContractInfo.ContractStorage[key]
-				if !exists {
-					t.Fatal("address", addr, "key", key, "found in storage as", value, "but not in initialization data")
-				}
-				if value != val2 {
-					t.Fatal("address", addr, "key", key, "value", val2, "isn't what was specified in initialization data", value)
-				}
+		var storageErr error
+		err := state.ForEachStorage(db, addr, func(key common.Hash, value common.Hash) bool {
+			if key == (common.Hash{}) {
+				// Unfortunately, geth doesn't seem capable of giving us storage keys any more.
+				// Even with the triedb Preimages set to true, it doesn't record the necessary
+				// hashed storage key -> raw storage key mapping. This means that geth will always
+				// give us an empty storage key when iterating, which we can't validate.
 				return true
-			})
-			if err != nil {
-				t.Fatal(err)
 			}
+			val2, exists := acct.ContractInfo.ContractStorage[key]
+			if !exists {
+				storageErr = fmt.Errorf("address %v key %v found in storage as %v but not in initialization data", addr, key, value)
+				return false
+			}
+			if value != val2 {
+				storageErr = fmt.Errorf("address %v key %v value %v isn't what was specified in initialization data %v", addr, key, val2, value)
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
 		}
-		isPoster, err := posterTable.ContainsPoster(addr)
-		Require(t, err)
-		if acct.AggregatorInfo != nil && isPoster {
-			posterInfo, err := posterTable.OpenPoster(addr, false)
-			Require(t, err)
-			fc, err := posterInfo.PayTo()
-			Require(t, err)
-			if fc != acct.AggregatorInfo.FeeCollector {
-				t.Fatal()
+		if storageErr != nil {
+			return storageErr
+		}
+	}
+	posterTable := arbState.L1PricingState().BatchPosterTable()
+	isPoster, err := posterTable.ContainsPoster(addr)
+	if err != nil {
+		return err
+	}
+	if acct.AggregatorInfo != nil && isPoster {
+		posterInfo, err := posterTable.OpenPoster(addr, false)
+		if err != nil {
+			return err
+		}
+		fc, err := posterInfo.PayTo()
+		if err != nil {
+			return err
+		}
+		if fc != acct.AggregatorInfo.FeeCollector {
+			return fmt.Errorf("account %v: fee collector mismatch. Expected %v, got %v", addr, acct.AggregatorInfo.FeeCollector, fc)
+		}
+	}
+	return nil
+}
+
+// verifyConcurrently calls verify for each of count items with a pool of workers. StateDBs can't be
+// read concurrently, so each worker reads its own copy of db. The error of the first failing item is
+// returned, so the outcome doesn't depend on the order the workers run in.
+func verifyConcurrently(db *state.StateDB, count int, workers int, verify func(*state.StateDB, *ArbosState, int) error) error {
+	errs := make([]error, count)
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		workerDb := db.Copy()
+		arbState, err := OpenArbosState(workerDb, &burn.SystemBurner{})
+		if err != nil {
+			close(indices)
+			wg.Wait()
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				errs[i] = verify(workerDb, arbState, i)
 			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
 		}
 	}
-	_ = l1p
+	return nil
+}
+
+// initializeCheckFixture initializes a database with pseudorandom accounts and retryables.
+func initializeCheckFixture(tb testing.TB, numAccounts int, numRetryables int) (*state.StateDB, *statetransfer.ArbosInitializationInfo) {
+	tb.Helper()
+	prand := testhelpers.NewPseudoRandomDataSource(tb, 4)
+	initData := &statetransfer.ArbosInitializationInfo{}
+	for i := 0; i < numAccounts; i++ {
+		initData.Accounts = append(initData.Accounts, pseudorandomAccountInitInfoForTesting(prand))
+	}
+	for i := 0; i < numRetryables; i++ {
+		initData.RetryableData = append(initData.RetryableData, pseudorandomRetryableInitForTesting(prand))
+	}
+	raw := rawdb.NewMemoryDatabase()
+	cacheConfig := core.DefaultCacheConfigWithScheme(env.GetTestStateScheme())
+	root, err := InitializeArbosInDatabase(raw, cacheConfig, statetransfer.NewMemoryInitDataReader(initData), chaininfo.ArbitrumDevTestChainConfig(), nil, arbostypes.TestInitMessage, 0, 0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	stateDb, err := state.New(root, state.NewDatabase(triedb.NewDatabase(raw, cacheConfig.TriedbConfig()), nil))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return stateDb, initData
+}
+
+func TestConcurrentInitChecks(t *testing.T) {
+	stateDb, initData := initializeCheckFixture(t, 64, 64)
+	checkAccountsConcurrently(stateDb, initData.Accounts, 4, t)
+	checkRetryablesConcurrently(stateDb, initData.RetryableData, 4, t)
+
+	// Mismatches are reported the same way whatever the number of workers.
+	accounts := append([]statetransfer.AccountInitializationInfo{}, initData.Accounts...)
+	accounts[40].Nonce++
+	accounts[50].Nonce++
+	retryables := append([]statetransfer.InitializationDataForRetryable{}, initData.RetryableData...)
+	retryables[30].Beneficiary = common.Address{}
+	for _, workers := range []int{1, 4, 16} {
+		err := verifyConcurrently(stateDb, len(accounts), workers, func(db *state.StateDB, arbState *ArbosState, i int) error {
+			return verifyAccount(db, arbState, accounts[i])
+		})
+		if err == nil || err.Error() != verifyAccount(stateDb, mustOpenArbosState(t, stateDb), accounts[40]).Error() {
+			Fail(t, "workers", workers, "reported the wrong account mismatch", err)
+		}
+		err = verifyConcurrently(stateDb, len(retryables), workers, func(_ *state.StateDB, arbState *ArbosState, i int) error {
+			return verifyRetryable(arbState, retryables[i])
+		})
+		if err == nil || err.Error() != verifyRetryable(mustOpenArbosState(t, stateDb), retryables[30]).Error() {
+			Fail(t, "workers", workers, "reported the wrong retryable mismatch", err)
+		}
+	}
+}
+
+func mustOpenArbosState(t *testing.T, stateDb *state.StateDB) *ArbosState {
+	t.Helper()
+	arbState, err := OpenArbosState(stateDb, &burn.SystemBurner{})
+	Require(t, err)
+	return arbState
+}
+
+func BenchmarkInitChecks(b *testing.B) {
+	stateDb, initData := initializeCheckFixture(b, 2000, 2000)
+	for _, workers := range []int{1, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				err := verifyConcurrently(stateDb, len(initData.Accounts), workers, func(db *state.StateDB, arbState *ArbosState, j int) error {
+					return verifyAccount(db, arbState, initData.Accounts[j])
+				})
+				if err == nil {
+					err = verifyConcurrently(stateDb, len(initData.RetryableData), workers, func(_ *state.StateDB, arbState *ArbosState, j int) error {
+						return verifyRetryable(arbState, initData.RetryableData[j])
+					})
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
 }
@@ -5,6 +5,7 @@ package arbosState
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 	"regexp"
 	"sort"
@@ -17,6 +18,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
@@ -58,15 +60,55 @@ func MakeGenesisBlock(parentHash common.Hash, blockNumber uint64, timestamp uint
 	return types.NewBlock(head, nil, nil, trie.NewStackTrie(nil))
 }
 
-func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig, initData statetransfer.InitDataReader, chainConfig *params.ChainConfig, genesisArbOSInit *params.ArbOSInit, initMessage *arbostypes.ParsedInitMessage, timestamp uint64, accountsPerSync uint) (root common.Hash, err error) {
+// AppendConflictPolicy decides what AppendInitDataToDatabase does when the init data contains
+// something the existing state already has.
+type AppendConflictPolicy uint8
+
+const (
+	// AppendConflictError aborts the append on the first conflict.
+	AppendConflictError AppendConflictPolicy = iota
+	// AppendConflictOverwrite replaces the balance, nonce, code and the given storage slots of
+	// existing accounts, and keeps the existing index of addresses already in the address table.
+	// Retryables can't be replaced without refunding them, so conflicting retryables are still errors.
+	AppendConflictOverwrite
+)
+
+func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig, initData statetransfer.InitDataReader, chainConfig *params.ChainConfig, genesisArbOSInit *params.ArbOSInit, initMessage *arbostypes.ParsedInitMessage, timestamp uint64, accountsPerSync uint) (common.Hash, error) {
+	return InitializeArbosInDatabaseWithAddressTableBase(db, cacheConfig, initData, chainConfig, genesisArbOSInit, initMessage, timestamp, accountsPerSync, 0)
+}
+
+// InitializeArbosInDatabaseWithAddressTableBase is InitializeArbosInDatabase, with the address table
+// contents of the init data registered at the indices starting at addressTableBase rather than zero,
+// for initializing a shard of a table whose lower indices are held elsewhere. The indices below the
+// base hold the zero address.
+func InitializeArbosInDatabaseWithAddressTableBase(db ethdb.Database, cacheConfig *core.CacheConfig, initData statetransfer.InitDataReader, chainConfig *params.ChainConfig, genesisArbOSInit *params.ArbOSInit, initMessage *arbostypes.ParsedInitMessage, timestamp uint64, accountsPerSync uint, addressTableBase uint64) (common.Hash, error) {
+	return importInitData(db, cacheConfig, types.EmptyRootHash, nil, initData, chainConfig, genesisArbOSInit, initMessage, timestamp, accountsPerSync, addressTableBase)
+}
+
+// AppendInitDataToDatabase imports init data on top of the existing, initialized state with the
+// given root instead of a fresh genesis state, returning the new state root. New addresses are
+// appended to the address table after the existing ones, and conflicts with the existing state
+// are handled according to onConflict.
+func AppendInitDataToDatabase(db ethdb.Database, cacheConfig *core.CacheConfig, root common.Hash, initData statetransfer.InitDataReader, chainConfig *params.ChainConfig, timestamp uint64, accountsPerSync uint, onConflict AppendConflictPolicy) (common.Hash, error) {
+	return importInitData(db, cacheConfig, root, &onConflict, initData, chainConfig, nil, nil, timestamp, accountsPerSync, 0)
+}
+
+// importInitData initializes a fresh ArbOS state from the init data if appendConflicts is nil, and
+// appends the init data to the existing state with the given root otherwise. New addresses are
+// registered in the address table from the index addressTableBase, or from its current size if
+// that's larger.
+func importInitData(db ethdb.Database, cacheConfig *core.CacheConfig, root common.Hash, appendConflicts *AppendConflictPolicy, initData statetransfer.InitDataReader, chainConfig *params.ChainConfig, genesisArbOSInit *params.ArbOSInit, initMessage *arbostypes.ParsedInitMessage, timestamp uint64, accountsPerSync uint, addressTableBase uint64) (_ common.Hash, err error) {
 	triedbConfig := cacheConfig.TriedbConfig()
 	triedbConfig.Preimages = false
 	stateDatabase := state.NewDatabase(triedb.NewDatabase(db, triedbConfig), nil)
 	defer func() {
 		err = errors.Join(err, stateDatabase.TrieDB().Close())
 	}()
-	statedb, err := state.New(types.EmptyRootHash, stateDatabase)
+	statedb, err := state.New(root, stateDatabase)
 	if err != nil {
+		if appendConflicts != nil {
+			return common.Hash{}, fmt.Errorf("failed to open state %v to append to: %w", root, err)
+		}
 		panic("failed to init empty statedb :" + err.Error())
 	}
 
@@ -95,9 +137,25 @@ func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig,
 	}
 
 	burner := burn.NewSystemBurner(nil, false)
-	arbosState, err := InitializeArbosState(statedb, burner, chainConfig, genesisArbOSInit, initMessage)
+	var arbosState *ArbosState
+	if appendConflicts != nil {
+		arbosState, err = OpenArbosState(statedb, burner)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to open the ArbOS state to append to: %w", err)
+		}
+	} else {
+		arbosState, err = InitializeArbosState(statedb, burner, chainConfig, genesisArbOSInit, initMessage)
+		if err != nil {
+			panic("failed to open the ArbOS state :" + err.Error())
+		}
+	}
+
+	storageOverrides, err := initData.GetPrecompileStorageOverrides()
 	if err != nil {
-		panic("failed to open the ArbOS state :" + err.Error())
+		return common.Hash{}, err
+	}
+	if err := applyPrecompileStorageOverrides(statedb, storageOverrides); err != nil {
+		return common.Hash{}, err
 	}
 
 	chainOwner, err := initData.GetChainOwner()
@@ -116,18 +174,36 @@ func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig,
 	if err != nil {
 		return common.Hash{}, err
 	}
-	if addrTableSize != 0 {
+	if addrTableSize != 0 && appendConflicts == nil {
 		return common.Hash{}, errors.New("address table must be empty")
 	}
+	if addressTableBase > addrTableSize {
+		if err := addrTable.SkipTo(addressTableBase); err != nil {
+			return common.Hash{}, err
+		}
+		addrTableSize = addressTableBase
+	}
 	addressReader, err := initData.GetAddressTableReader()
 	if err != nil {
 		return common.Hash{}, err
 	}
-	for i := uint64(0); addressReader.More(); i++ {
+	for i := addrTableSize; addressReader.More(); {
 		addr, err := addressReader.GetNext()
 		if err != nil {
 			return common.Hash{}, err
 		}
+		if appendConflicts != nil {
+			exists, err := addrTable.AddressExists(*addr)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			if exists {
+				if *appendConflicts == AppendConflictError {
+					return common.Hash{}, fmt.Errorf("address %v is already in the address table", *addr)
+				}
+				continue
+			}
+		}
 		slot, err := addrTable.Register(*addr)
 		if err != nil {
 			return common.Hash{}, err
@@ -135,6 +211,7 @@ func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig,
 		if i != slot {
 			return common.Hash{}, errors.New("address table slot mismatch")
 		}
+		i++
 	}
 	if err := addressReader.Close(); err != nil {
 		return common.Hash{}, err
@@ -146,7 +223,7 @@ func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig,
 	if err != nil {
 		return common.Hash{}, err
 	}
-	err = initializeRetryables(statedb, arbosState.RetryableState(), retryableReader, timestamp)
+	err = initializeRetryables(statedb, arbosState.RetryableState(), retryableReader, timestamp, appendConflicts != nil)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -170,6 +247,9 @@ func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig,
 		if err != nil {
 			return common.Hash{}, err
 		}
+		if appendConflicts != nil && *appendConflicts == AppendConflictError && statedb.Exist(account.Addr) {
+			return common.Hash{}, fmt.Errorf("account %v already exists", account.Addr)
+		}
 		err = initializeArbosAccount(statedb, arbosState, *account)
 		if err != nil {
 			return common.Hash{}, err
@@ -177,7 +257,11 @@ func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig,
 		statedb.SetBalance(account.Addr, uint256.MustFromBig(account.EthBalance), tracing.BalanceChangeUnspecified)
 		statedb.SetNonce(account.Addr, account.Nonce, tracing.NonceChangeUnspecified)
 		if account.ContractInfo != nil {
-			statedb.SetCode(account.Addr, account.ContractInfo.Code)
+			code, err := resolveContractCode(initData, account.Addr, account.ContractInfo)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			statedb.SetCode(account.Addr, code)
 			for k, v := range account.ContractInfo.ContractStorage {
 				statedb.SetState(account.Addr, k, v)
 			}
@@ -197,7 +281,57 @@ func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig,
 	return commit()
 }
 
-func initializeRetryables(statedb *state.StateDB, rs *retryables.RetryableState, initData statetransfer.RetryableDataReader, currentTimestamp uint64) error {
+// resolveContractCode returns the code of a contract, which is either inline or referenced by hash
+// in the code blobs of the init data. Code is checked against the hash whenever one is given.
+func resolveContractCode(initData statetransfer.InitDataReader, addr common.Address, contractInfo *statetransfer.AccountInitContractInfo) ([]byte, error) {
+	if contractInfo.CodeHash == nil {
+		return contractInfo.Code, nil
+	}
+	code := contractInfo.Code
+	if len(code) == 0 {
+		var err error
+		code, err = initData.GetCodeByHash(*contractInfo.CodeHash)
+		if err != nil {
+			return nil, fmt.Errorf("resolving code of contract %v: %w", addr, err)
+		}
+	}
+	if hash := crypto.Keccak256Hash(code); hash != *contractInfo.CodeHash {
+		return nil, fmt.Errorf("code of contract %v has hash %v, expected %v", addr, hash, *contractInfo.CodeHash)
+	}
+	return code, nil
+}
+
+// ComputeGenesisStateRoot returns the state root the genesis block of a chain initialized from
+// initData will have, computed against a throwaway in-memory database.
+func ComputeGenesisStateRoot(initData statetransfer.InitDataReader, chainConfig *params.ChainConfig, initMessage *arbostypes.ParsedInitMessage) (common.Hash, error) {
+	cacheConfig := core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	return InitializeArbosInDatabase(rawdb.NewMemoryDatabase(), cacheConfig, initData, chainConfig, nil, initMessage, 0, 0)
+}
+
+// applyPrecompileStorageOverrides writes the storage overrides from the init data over the freshly
+// initialized ArbOS state. Only ArbOS's own state and registered precompiles may be overridden,
+// and all addresses are checked before anything is written.
+func applyPrecompileStorageOverrides(statedb *state.StateDB, overrides map[common.Address]map[common.Hash]common.Hash) error {
+	addresses := make([]common.Address, 0, len(overrides))
+	for addr := range overrides {
+		if _, isPrecompile := PrecompileMinArbOSVersions[addr]; !isPrecompile && addr != types.ArbosStateAddress {
+			return fmt.Errorf("storage override for %v, which isn't a precompile", addr)
+		}
+		addresses = append(addresses, addr)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Cmp(addresses[j]) < 0
+	})
+	for _, addr := range addresses {
+		for slot, value := range overrides[addr] {
+			statedb.SetState(addr, slot, value)
+		}
+		log.Info("applied precompile storage overrides", "address", addr, "slots", len(overrides[addr]))
+	}
+	return nil
+}
+
+func initializeRetryables(statedb *state.StateDB, rs *retryables.RetryableState, initData statetransfer.RetryableDataReader, currentTimestamp uint64, checkExisting bool) error {
 	var retryablesList []*statetransfer.InitializationDataForRetryable
 	for initData.More() {
 		r, err := initData.GetNext()
@@ -219,6 +353,15 @@ func initializeRetryables(statedb *state.StateDB, rs *retryables.RetryableState,
 		return a.Timeout < b.Timeout
 	})
 	for _, r := range retryablesList {
+		if checkExisting {
+			existing, err := rs.OpenRetryable(r.Id, currentTimestamp)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return fmt.Errorf("retryable %v already exists", r.Id)
+			}
+		}
 		var to *common.Address
 		if r.To != (common.Address{}) {
 			addr := r.To
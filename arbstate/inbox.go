@@ -81,11 +81,12 @@ func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 		var err error
 		for _, dapReader := range dapReaders {
 			if dapReader != nil && dapReader.IsValidHeaderByte(ctx, payload[0]) {
+				headerByte := payload[0]
 				payload, _, err = dapReader.RecoverPayloadFromBatch(ctx, batchNum, batchBlockHash, data, nil, keysetValidationMode != daprovider.KeysetDontValidate)
 				if err != nil {
 					// Matches the way keyset validation was done inside DAS readers i.e logging the error
 					//  But other daproviders might just want to return the error
-					if strings.Contains(err.Error(), daprovider.ErrSeqMsgValidation.Error()) && daprovider.IsDASMessageHeaderByte(payload[0]) {
+					if strings.Contains(err.Error(), daprovider.ErrSeqMsgValidation.Error()) && daprovider.IsDASMessageHeaderByte(headerByte) {
 						if keysetValidationMode == daprovider.KeysetPanicIfInvalid {
 							panic(err.Error())
 						} else {
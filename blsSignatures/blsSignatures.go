@@ -173,6 +173,45 @@ func VerifyAggregatedSignatureDifferentMessages(sig Signature, messages [][]byte
 	return leftSide.Equal(rightSide), nil
 }
 
+// batchVerificationCoefficientLimit bounds the random coefficients of VerifySignaturesBatch.
+var batchVerificationCoefficientLimit = new(big.Int).Lsh(big.NewInt(1), 64)
+
+// VerifySignaturesBatch checks many signatures, each over its own message by its own public key,
+// with a single pairing check. It's much faster than checking them one by one, but only reports
+// whether all of them are valid. Each signature is weighted by a random coefficient, so that
+// invalid signatures can't be crafted to cancel each other out.
+func VerifySignaturesBatch(sigs []Signature, messages [][]byte, pubKeys []PublicKey) (bool, error) {
+	if len(sigs) != len(messages) || len(sigs) != len(pubKeys) {
+		return false, errors.New("len(sigs), len(messages) and len(pub keys) differ in batch verification")
+	}
+	g1 := bls12381.NewG1()
+	aggregatedSig := g1.Zero()
+	engine := bls12381.NewPairingEngine()
+	engine.Reset()
+	for i, msg := range messages {
+		coefficient, err := cryptorand.Int(cryptorand.Reader, batchVerificationCoefficientLimit)
+		if err != nil {
+			return false, err
+		}
+		coefficient.Add(coefficient, big.NewInt(1))
+		pointOnCurve, err := hashToG1Curve(msg, false)
+		if err != nil {
+			return false, err
+		}
+		g1.MulScalar(pointOnCurve, pointOnCurve, coefficient)
+		engine.AddPair(pointOnCurve, pubKeys[i].key)
+		weightedSig := g1.New()
+		g1.MulScalar(weightedSig, sigs[i], coefficient)
+		g1.Add(aggregatedSig, aggregatedSig, weightedSig)
+	}
+	leftSide := engine.Result()
+
+	engine.Reset()
+	engine.AddPair(aggregatedSig, engine.G2.One())
+	rightSide := engine.Result()
+	return leftSide.Equal(rightSide), nil
+}
+
 // This hashes a message to a [32]byte, then maps the result to the G1 curve using
 // the Simplified Shallue-van de Woestijne-Ulas Method, described in Section 6.6.2 of
 // https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-06
@@ -249,6 +288,13 @@ func PrivateKeyFromBytes(in []byte) (PrivateKey, error) {
 	return new(big.Int).SetBytes(in), nil
 }
 
+// SignatureLength and CompressedSignatureLength are the lengths of the uncompressed encoding of
+// signatures used by SignatureToBytes, and of the compressed one used by SignatureToCompressedBytes.
+const (
+	SignatureLength           = 96
+	CompressedSignatureLength = 48
+)
+
 func SignatureToBytes(sig Signature) []byte {
 	g1 := bls12381.NewG1()
 	return g1.ToBytes(sig)
@@ -258,3 +304,15 @@ func SignatureFromBytes(in []byte) (Signature, error) {
 	g1 := bls12381.NewG1()
 	return g1.FromBytes(in)
 }
+
+// SignatureToCompressedBytes encodes the signature in the compressed form of other BLS libraries,
+// holding only the x coordinate of the point along with flags.
+func SignatureToCompressedBytes(sig Signature) []byte {
+	g1 := bls12381.NewG1()
+	return g1.ToCompressed(sig)
+}
+
+func SignatureFromCompressedBytes(in []byte) (Signature, error) {
+	g1 := bls12381.NewG1()
+	return g1.FromCompressed(in)
+}
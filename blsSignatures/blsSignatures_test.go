@@ -4,10 +4,13 @@
 package blsSignatures
 
 import (
+	"math/big"
 	"math/rand"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+
 	"github.com/offchainlabs/nitro/util/testhelpers"
 )
 
@@ -140,6 +143,82 @@ func TestSignatureAggregationDifferentMessages(t *testing.T) {
 	}
 }
 
+func TestSignatureEncodings(t *testing.T) {
+	_, priv, err := GenerateKeys()
+	Require(t, err)
+	sig, err := SignMessage(priv, []byte("The quick brown fox jumped over the lazy dog."))
+	Require(t, err)
+	g1 := bls12381.NewG1()
+
+	uncompressed := SignatureToBytes(sig)
+	if len(uncompressed) != SignatureLength {
+		Fail(t, "unexpected uncompressed signature length", len(uncompressed))
+	}
+	decoded, err := SignatureFromBytes(uncompressed)
+	Require(t, err)
+	if !g1.Equal(decoded, sig) {
+		Fail(t, "uncompressed signature doesn't round trip")
+	}
+
+	compressed := SignatureToCompressedBytes(sig)
+	if len(compressed) != CompressedSignatureLength {
+		Fail(t, "unexpected compressed signature length", len(compressed))
+	}
+	decoded, err = SignatureFromCompressedBytes(compressed)
+	Require(t, err)
+	if !g1.Equal(decoded, sig) {
+		Fail(t, "compressed signature doesn't round trip")
+	}
+
+	if _, err := SignatureFromCompressedBytes(uncompressed); err == nil {
+		Fail(t, "expected uncompressed signature to be rejected as compressed")
+	}
+}
+
+func TestVerifySignaturesBatch(t *testing.T) {
+	messages := [][]byte{}
+	pubKeys := []PublicKey{}
+	sigs := []Signature{}
+
+	for i := 0; i < NumSignaturesToAggregate; i++ {
+		msg := []byte{byte(i)}
+		pubKey, privKey, err := GenerateKeys()
+		Require(t, err)
+		sig, err := SignMessage(privKey, msg)
+		Require(t, err)
+		messages = append(messages, msg)
+		pubKeys = append(pubKeys, pubKey)
+		sigs = append(sigs, sig)
+	}
+
+	verified, err := VerifySignaturesBatch(sigs, messages, pubKeys)
+	Require(t, err)
+	if !verified {
+		Fail(t, "batch of valid signatures failed verification")
+	}
+
+	// Shifting one signature by a point and another by its negation keeps their sum, which
+	// plain aggregation would accept, but each of them is now invalid.
+	g1 := bls12381.NewG1()
+	shift := g1.New()
+	g1.MulScalar(shift, g1.One(), big.NewInt(12345))
+	tampered := append([]Signature{}, sigs...)
+	first, second := g1.New(), g1.New()
+	g1.Add(first, sigs[0], shift)
+	g1.Sub(second, sigs[1], shift)
+	tampered[0], tampered[1] = first, second
+	verified, err = VerifyAggregatedSignatureDifferentMessages(AggregateSignatures(tampered), messages, pubKeys)
+	Require(t, err)
+	if !verified {
+		Fail(t, "expected cancelling signatures to pass plain aggregated verification")
+	}
+	verified, err = VerifySignaturesBatch(tampered, messages, pubKeys)
+	Require(t, err)
+	if verified {
+		Fail(t, "batch verification accepted signatures cancelling each other out")
+	}
+}
+
 func Require(t *testing.T, err error, printables ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, printables...)
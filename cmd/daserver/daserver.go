@@ -247,6 +247,14 @@ func startup() error {
 		dasLifecycleManager.Register(&L1ReaderCloser{l1Reader})
 	}
 
+	if serverConfig.DataAvailability.PrometheusMetrics.Enable {
+		metricsServer, err := das.StartPrometheusMetricsServer(serverConfig.DataAvailability.PrometheusMetrics)
+		if err != nil {
+			return err
+		}
+		dasLifecycleManager.Register(metricsServer)
+	}
+
 	vcsRevision, _, vcsTime := confighelpers.GetVersion()
 	var rpcServer *http.Server
 	if serverConfig.EnableRPC {
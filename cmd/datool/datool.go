@@ -9,6 +9,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -35,7 +36,7 @@ import (
 func main() {
 	args := os.Args
 	if len(args) < 2 {
-		panic("Usage: datool [client|keygen|generatehash|dumpkeyset] ...")
+		panic("Usage: datool [client|keygen|generatehash|dumpkeyset|keysethash] ...")
 	}
 
 	var err error
@@ -48,8 +49,10 @@ func main() {
 		err = generateHash(args[2])
 	case "dumpkeyset":
 		err = dumpKeyset(args[2:])
+	case "keysethash":
+		err = keysetHash(args[2:], os.Stdout)
 	default:
-		panic(fmt.Sprintf("Unknown tool '%s' specified, valid tools are 'client', 'keygen', 'generatehash'", args[1]))
+		panic(fmt.Sprintf("Unknown tool '%s' specified, valid tools are 'client', 'keygen', 'generatehash', 'dumpkeyset', 'keysethash'", args[1]))
 	}
 	if err != nil {
 		panic(err)
@@ -181,7 +184,10 @@ func startClientStore(args []string) error {
 		return err
 	}
 
-	serializedCert := dasutil.Serialize(cert)
+	serializedCert, err := dasutil.Serialize(cert)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("Hex Encoded Cert: %s\n", hexutil.Encode(serializedCert))
 	fmt.Printf("Hex Encoded Data Hash: %s\n", hexutil.Encode(cert.DataHash[:]))
 
@@ -223,22 +229,26 @@ func startRESTClientGetByHash(args []string) error {
 		return err
 	}
 
-	var decodedHash []byte
+	var dataHash common.Hash
 	if strings.HasPrefix(config.DataHash, "0x") {
-		decodedHash, err = hexutil.Decode(config.DataHash)
+		dataHash, err = das.ParseHashKey(config.DataHash)
 		if err != nil {
 			return err
 		}
 	} else {
 		hashDecoder := base64.NewDecoder(base64.StdEncoding, bytes.NewReader([]byte(config.DataHash)))
-		decodedHash, err = io.ReadAll(hashDecoder)
+		decodedHash, err := io.ReadAll(hashDecoder)
 		if err != nil {
 			return err
 		}
+		if len(decodedHash) != common.HashLength {
+			return fmt.Errorf("data hash %q is %d bytes, expected %d", config.DataHash, len(decodedHash), common.HashLength)
+		}
+		dataHash = common.BytesToHash(decodedHash)
 	}
 
 	ctx := context.Background()
-	message, err := client.GetByHash(ctx, common.BytesToHash(decodedHash))
+	message, err := client.GetByHash(ctx, dataHash)
 	if err != nil {
 		return err
 	}
@@ -378,3 +388,87 @@ func dumpKeyset(args []string) error {
 
 	return err
 }
+
+// datool keysethash
+
+type KeysetHashConfig struct {
+	KeysetFile string `koanf:"keyset-file"`
+}
+
+// KeysetDefinition is the JSON definition of a keyset read by datool keysethash, with the public keys
+// base64 encoded as generated by datool keygen.
+type KeysetDefinition struct {
+	AssumedHonest uint64   `json:"assumed-honest"`
+	PubKeys       []string `json:"pubkeys"`
+}
+
+func parseKeysetHashConfig(args []string) (*KeysetHashConfig, error) {
+	f := flag.NewFlagSet("datool keysethash", flag.ContinueOnError)
+	f.String("keyset-file", "", "JSON file defining the keyset, e.g. {\"assumed-honest\": 1, \"pubkeys\": [\"<base64 BLS public key>\", ...]}")
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config KeysetHashConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if config.KeysetFile == "" {
+		return nil, errors.New("--keyset-file must be set")
+	}
+	return &config, nil
+}
+
+// keysetFromDefinition builds the keyset defined by the JSON definition.
+func keysetFromDefinition(definition []byte) (*dasutil.DataAvailabilityKeyset, error) {
+	var def KeysetDefinition
+	if err := json.Unmarshal(definition, &def); err != nil {
+		return nil, fmt.Errorf("invalid keyset definition: %w", err)
+	}
+	if def.AssumedHonest == 0 {
+		return nil, errors.New("keyset definition must set assumed-honest")
+	}
+	if len(def.PubKeys) == 0 {
+		return nil, errors.New("keyset definition must have public keys")
+	}
+	keyset := &dasutil.DataAvailabilityKeyset{AssumedHonest: def.AssumedHonest}
+	for i, encoded := range def.PubKeys {
+		pubKey, err := das.DecodeBase64BLSPublicKey([]byte(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %d: %w", i, err)
+		}
+		keyset.PubKeys = append(keyset.PubKeys, *pubKey)
+	}
+	return keyset, nil
+}
+
+func keysetHash(args []string, out io.Writer) error {
+	config, err := parseKeysetHashConfig(args)
+	if err != nil {
+		return err
+	}
+	definition, err := os.ReadFile(config.KeysetFile)
+	if err != nil {
+		return err
+	}
+	keyset, err := keysetFromDefinition(definition)
+	if err != nil {
+		return err
+	}
+
+	var keysetBytes bytes.Buffer
+	if err := keyset.Serialize(&keysetBytes); err != nil {
+		return err
+	}
+	// Fails for keysets larger than dastree.BinSize.
+	hash, err := keyset.Hash()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Keyset: %s\n", hexutil.Encode(keysetBytes.Bytes()))
+	fmt.Fprintf(out, "KeysetHash: %s\n", hexutil.Encode(hash[:]))
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+func TestKeysetHash(t *testing.T) {
+	keyset := &dasutil.DataAvailabilityKeyset{AssumedHonest: 2}
+	definition := KeysetDefinition{AssumedHonest: keyset.AssumedHonest}
+	for i := 0; i < 3; i++ {
+		pubKey, _, err := blsSignatures.GenerateKeys()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyset.PubKeys = append(keyset.PubKeys, pubKey)
+		definition.PubKeys = append(definition.PubKeys, base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(pubKey)))
+	}
+	expectedHash, err := keyset.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expectedBytes bytes.Buffer
+	if err := keyset.Serialize(&expectedBytes); err != nil {
+		t.Fatal(err)
+	}
+
+	definitionJson, err := json.Marshal(&definition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keysetFile := filepath.Join(t.TempDir(), "keyset.json")
+	if err := os.WriteFile(keysetFile, definitionJson, 0600); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := keysetHash([]string{"--keyset-file", keysetFile}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "KeysetHash: "+hexutil.Encode(expectedHash[:])+"\n") {
+		t.Fatal("expected the keyset hash", expectedHash, "to be printed, got", out.String())
+	}
+	if !strings.Contains(out.String(), "Keyset: "+hexutil.Encode(expectedBytes.Bytes())+"\n") {
+		t.Fatal("expected the serialized keyset to be printed, got", out.String())
+	}
+
+	for _, invalid := range []string{
+		`{"assumed-honest": 1, "pubkeys": ["not a key"]}`,
+		`{"assumed-honest": 0, "pubkeys": ["` + definition.PubKeys[0] + `"]}`,
+		`{"assumed-honest": 1, "pubkeys": []}`,
+	} {
+		if _, err := keysetFromDefinition([]byte(invalid)); err == nil {
+			t.Fatal("expected invalid keyset definition to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+)
+
+// existingChainsInfo returns the chains in the chain info file at path, or none if it doesn't exist,
+// failing if one of them is named chainName.
+func existingChainsInfo(path, chainName string) ([]chaininfo.ChainInfo, error) {
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chain info file %s: %w", path, err)
+	}
+	var chainsInfo []chaininfo.ChainInfo
+	if err := json.Unmarshal(existing, &chainsInfo); err != nil {
+		return nil, fmt.Errorf("failed to deserialize chain info file %s: %w", path, err)
+	}
+	for _, info := range chainsInfo {
+		if info.ChainName == chainName {
+			return nil, fmt.Errorf("chain info file %s already has a chain named %q", path, chainName)
+		}
+	}
+	return chainsInfo, nil
+}
+
+// writeChainInfo writes the chain info output file. If appendToExisting is set, the chain is added to
+// the chains already in the file, if any, and must have a name none of them has.
+func writeChainInfo(path string, chainInfo chaininfo.ChainInfo, appendToExisting bool) error {
+	var chainsInfo []chaininfo.ChainInfo
+	if appendToExisting {
+		var err error
+		chainsInfo, err = existingChainsInfo(path, chainInfo.ChainName)
+		if err != nil {
+			return err
+		}
+	}
+	chainsInfo = append(chainsInfo, chainInfo)
+	chainsInfoJson, err := json.Marshal(chainsInfo)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, chainsInfoJson, 0600)
+}
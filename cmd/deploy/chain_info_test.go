@@ -0,0 +1,69 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+)
+
+func readChainNames(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chainsInfo []chaininfo.ChainInfo
+	if err := json.Unmarshal(data, &chainsInfo); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range chainsInfo {
+		names = append(names, info.ChainName)
+	}
+	return names
+}
+
+func TestWriteChainInfoAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "l2_chain_info.json")
+	first := chaininfo.ChainInfo{ChainName: "first", ChainConfig: chaininfo.ArbitrumDevTestChainConfig()}
+	second := chaininfo.ChainInfo{ChainName: "second", ChainConfig: chaininfo.ArbitrumDevTestDASChainConfig()}
+
+	// Appending to a file that doesn't exist yet creates it.
+	if err := writeChainInfo(path, first, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeChainInfo(path, second, true); err != nil {
+		t.Fatal(err)
+	}
+	if names := readChainNames(t, path); len(names) != 2 || names[0] != "first" || names[1] != "second" {
+		t.Fatal("expected both chains in the chain info file, got", names)
+	}
+	for _, name := range []string{"first", "second"} {
+		if _, err := chaininfo.ProcessChainInfo(0, name, []string{path}, ""); err != nil {
+			t.Fatal("appended chain", name, "can't be found:", err)
+		}
+	}
+
+	err := writeChainInfo(path, chaininfo.ChainInfo{ChainName: "first"}, true)
+	if err == nil || !strings.Contains(err.Error(), "already has a chain named") {
+		t.Fatal("expected appending a duplicate chain name to fail, got", err)
+	}
+	if names := readChainNames(t, path); len(names) != 2 {
+		t.Fatal("failed append modified the chain info file", names)
+	}
+
+	// Without appending, the file is overwritten.
+	if err := writeChainInfo(path, first, false); err != nil {
+		t.Fatal(err)
+	}
+	if names := readChainNames(t, path); len(names) != 1 || names[0] != "first" {
+		t.Fatal("expected the chain info file to be overwritten, got", names)
+	}
+}
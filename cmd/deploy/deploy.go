@@ -60,8 +60,18 @@ func main() {
 	authorizevalidators := flag.Uint64("authorizevalidators", 0, "Number of validators to preemptively authorize")
 	txTimeout := flag.Duration("txtimeout", 10*time.Minute, "Timeout when waiting for a transaction to be included in a block")
 	prod := flag.Bool("prod", false, "Whether to configure the rollup for production or testing")
+	appendChainInfo := flag.Bool("append-chain-info", false, "Add the chain to the chains in the existing L2 chain info output json file instead of overwriting it")
+	validateOnly := flag.Bool("validateonly", false, "Only check that the existing chain info output agrees with the deployment output and the L2 chain config, without deploying")
 	flag.Parse()
 	l1ChainId := new(big.Int).SetUint64(*l1ChainIdUint)
+
+	if *validateOnly {
+		if err := validateDeploymentFiles(*outfile, *l2ChainInfo, *l2ChainConfig, *l2ChainName, *l1ChainIdUint); err != nil {
+			panic(err)
+		}
+		log.Info("chain info is consistent with the deployment", "chain", *l2ChainName)
+		return
+	}
 	maxDataSize := new(big.Int).SetUint64(*maxDataSizeUint)
 
 	if *prod {
@@ -72,6 +82,12 @@ func main() {
 	if *l2ChainName == "" {
 		panic("must specify l2 chain name")
 	}
+	if *appendChainInfo {
+		// Fail before deploying if the chain can't be added to the chain info file.
+		if _, err := existingChainsInfo(*l2ChainInfo, *l2ChainName); err != nil {
+			panic(err)
+		}
+	}
 
 	wallet := genericconf.WalletConfig{
 		Pathname:   *l1keystore,
@@ -155,6 +171,11 @@ func main() {
 	if moduleRoot == (common.Hash{}) {
 		panic("wasmModuleRoot not found")
 	}
+	if *wasmrootpath != "" {
+		if err := checkModuleRootMachine(*wasmrootpath, moduleRoot); err != nil {
+			panic(err)
+		}
+	}
 
 	headerReaderConfig := headerreader.DefaultConfig
 	headerReaderConfig.TxTimeout = *txTimeout
@@ -203,20 +224,14 @@ func main() {
 		panic(err)
 	}
 	parentChainIsArbitrum := l1Reader.IsParentChainArbitrum()
-	chainsInfo := []chaininfo.ChainInfo{
-		{
-			ChainName:             *l2ChainName,
-			ParentChainId:         l1ChainId.Uint64(),
-			ParentChainIsArbitrum: &parentChainIsArbitrum,
-			ChainConfig:           &chainConfig,
-			RollupAddresses:       deployedAddresses,
-		},
-	}
-	chainsInfoJson, err := json.Marshal(chainsInfo)
-	if err != nil {
-		panic(err)
-	}
-	if err := os.WriteFile(*l2ChainInfo, chainsInfoJson, 0600); err != nil {
+	chainInfo := chaininfo.ChainInfo{
+		ChainName:             *l2ChainName,
+		ParentChainId:         l1ChainId.Uint64(),
+		ParentChainIsArbitrum: &parentChainIsArbitrum,
+		ChainConfig:           &chainConfig,
+		RollupAddresses:       deployedAddresses,
+	}
+	if err := writeChainInfo(*l2ChainInfo, chainInfo, *appendChainInfo); err != nil {
 		panic(err)
 	}
 }
@@ -0,0 +1,115 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+// validateDeploymentFiles checks that the chain info written for chainName agrees with the deployment
+// output and the chain config the rollup was deployed with, returning all mismatches found at once.
+// The chain info file is written separately from the deployment output, so the two can drift when
+// either is edited or regenerated by hand.
+func validateDeploymentFiles(deployFile, chainInfoFile, chainConfigFile, chainName string, parentChainId uint64) error {
+	var deployedAddresses chaininfo.RollupAddresses
+	if err := readJsonFile(deployFile, &deployedAddresses); err != nil {
+		return err
+	}
+	var chainsInfo []chaininfo.ChainInfo
+	if err := readJsonFile(chainInfoFile, &chainsInfo); err != nil {
+		return err
+	}
+	var chainConfig params.ChainConfig
+	if err := readJsonFile(chainConfigFile, &chainConfig); err != nil {
+		return err
+	}
+
+	var chainInfo *chaininfo.ChainInfo
+	for i := range chainsInfo {
+		if chainsInfo[i].ChainName == chainName {
+			chainInfo = &chainsInfo[i]
+			break
+		}
+	}
+	if chainInfo == nil {
+		return fmt.Errorf("chain %q not found in chain info file %s", chainName, chainInfoFile)
+	}
+
+	var errs []error
+	if chainInfo.RollupAddresses == nil {
+		errs = append(errs, fmt.Errorf("chain info of %q is missing the rollup addresses", chainName))
+	} else if *chainInfo.RollupAddresses != deployedAddresses {
+		errs = append(errs, fmt.Errorf("rollup addresses of %q in chain info %+v don't match deployed addresses %+v", chainName, *chainInfo.RollupAddresses, deployedAddresses))
+	}
+	if chainInfo.ParentChainId != parentChainId {
+		errs = append(errs, fmt.Errorf("parent chain id of %q in chain info is %d, expected %d", chainName, chainInfo.ParentChainId, parentChainId))
+	}
+	if chainInfo.ChainConfig == nil {
+		errs = append(errs, fmt.Errorf("chain info of %q is missing the chain config", chainName))
+	} else {
+		// Compare the encodings, as decoding the same config twice needn't give deeply equal big.Ints.
+		infoConfigJson, err := json.Marshal(chainInfo.ChainConfig)
+		if err != nil {
+			return err
+		}
+		chainConfigJson, err := json.Marshal(&chainConfig)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(infoConfigJson, chainConfigJson) {
+			errs = append(errs, fmt.Errorf("chain config of %q in chain info doesn't match chain config file %s", chainName, chainConfigFile))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// machineFiles are the files validators load the machine of a module root from, as named in the
+// default configs of the arbitrator and JIT machine loaders.
+var machineFiles = []string{"machine.wavm.br", "replay.wasm"}
+
+// checkModuleRootMachine checks that the machine of moduleRoot is present under rootPath, so that a
+// mistyped module root doesn't deploy a rollup whose validators can't serve it.
+func checkModuleRootMachine(rootPath string, moduleRoot common.Hash) error {
+	locator, err := server_common.NewMachineLocator(rootPath)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(locator.ModuleRoots(), moduleRoot) {
+		return fmt.Errorf("%w: module root %v isn't in %s", server_common.ErrMachineNotFound, moduleRoot, rootPath)
+	}
+	machinePath := locator.GetMachinePath(moduleRoot)
+	for _, file := range machineFiles {
+		info, err := os.Stat(filepath.Join(machinePath, file))
+		if err != nil {
+			return fmt.Errorf("%w: machine of module root %v in %s is missing %s: %w", server_common.ErrMachineNotFound, moduleRoot, machinePath, file, err)
+		}
+		if !info.Mode().IsRegular() || info.Size() == 0 {
+			return fmt.Errorf("%w: machine of module root %v in %s has an invalid %s", server_common.ErrMachineNotFound, moduleRoot, machinePath, file)
+		}
+	}
+	return nil
+}
+
+func readJsonFile(path string, value any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to deserialize %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+func writeJsonFile(t *testing.T, path string, value any) {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateDeploymentFiles(t *testing.T) {
+	deployedAddresses := chaininfo.RollupAddresses{
+		Bridge:         common.HexToAddress("0x01"),
+		Inbox:          common.HexToAddress("0x02"),
+		SequencerInbox: common.HexToAddress("0x03"),
+		Rollup:         common.HexToAddress("0x04"),
+		DeployedAt:     100,
+	}
+	chainConfig := chaininfo.ArbitrumDevTestChainConfig()
+	const chainName = "test-chain"
+	const parentChainId = 1337
+
+	testCases := []struct {
+		name     string
+		modify   func(*chaininfo.ChainInfo)
+		expected string
+	}{
+		{name: "matching"},
+		{
+			name:     "rollup addresses",
+			modify:   func(info *chaininfo.ChainInfo) { info.RollupAddresses.Inbox = common.HexToAddress("0x05") },
+			expected: "don't match deployed addresses",
+		},
+		{
+			name:     "missing rollup addresses",
+			modify:   func(info *chaininfo.ChainInfo) { info.RollupAddresses = nil },
+			expected: "missing the rollup addresses",
+		},
+		{
+			name:     "parent chain id",
+			modify:   func(info *chaininfo.ChainInfo) { info.ParentChainId = 1 },
+			expected: "parent chain id",
+		},
+		{
+			name:     "chain config",
+			modify:   func(info *chaininfo.ChainInfo) { info.ChainConfig = chaininfo.ArbitrumOneChainConfig() },
+			expected: "doesn't match chain config file",
+		},
+		{
+			name:     "chain name",
+			modify:   func(info *chaininfo.ChainInfo) { info.ChainName = "other-chain" },
+			expected: "not found in chain info file",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			deployFile := filepath.Join(dir, "deploy.json")
+			chainInfoFile := filepath.Join(dir, "l2_chain_info.json")
+			chainConfigFile := filepath.Join(dir, "l2_chain_config.json")
+			writeJsonFile(t, deployFile, &deployedAddresses)
+			writeJsonFile(t, chainConfigFile, chainConfig)
+
+			rollupAddresses := deployedAddresses
+			info := chaininfo.ChainInfo{
+				ChainName:       chainName,
+				ParentChainId:   parentChainId,
+				ChainConfig:     chainConfig,
+				RollupAddresses: &rollupAddresses,
+			}
+			if tc.modify != nil {
+				tc.modify(&info)
+			}
+			writeJsonFile(t, chainInfoFile, []chaininfo.ChainInfo{info})
+
+			err := validateDeploymentFiles(deployFile, chainInfoFile, chainConfigFile, chainName, parentChainId)
+			if tc.expected == "" {
+				if err != nil {
+					t.Fatal("expected matching files to be valid, got", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("expected error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestCheckModuleRootMachine(t *testing.T) {
+	moduleRoot := common.HexToHash("0xf4389b835497a910d7ba3ebfb77aa93da985634f3c052de1290360635be40c4a")
+	writeMachine := func(t *testing.T, dir string, files ...string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "module-root.txt"), []byte(moduleRoot.Hex()+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte("machine"), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	rootPath := t.TempDir()
+	writeMachine(t, filepath.Join(rootPath, "latest"), machineFiles...)
+	if err := checkModuleRootMachine(rootPath, moduleRoot); err != nil {
+		t.Fatal("expected machine of the latest module root to be found, got", err)
+	}
+	if err := checkModuleRootMachine(rootPath, common.HexToHash("0x1234")); !errors.Is(err, server_common.ErrMachineNotFound) {
+		t.Fatal("expected unknown module root to be rejected, got", err)
+	}
+
+	missingPath := t.TempDir()
+	writeMachine(t, filepath.Join(missingPath, moduleRoot.Hex()), "replay.wasm")
+	if err := checkModuleRootMachine(missingPath, moduleRoot); err == nil || !strings.Contains(err.Error(), "machine.wavm.br") {
+		t.Fatal("expected machine missing its wavm binary to be rejected, got", err)
+	}
+}
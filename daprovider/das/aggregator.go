@@ -26,15 +26,9 @@ import (
 	"github.com/offchainlabs/nitro/util/pretty"
 )
 
-const metricBase string = "arb/das/rpc/aggregator/store"
-
-var (
-	// This metric shows 1 if there was any error posting to the backends, until
-	// there was a Store that had no backend failures.
-	anyErrorGauge = metrics.GetOrRegisterGauge(metricBase+"/error/gauge", nil)
-
-// Other aggregator metrics are generated dynamically in the Store function.
-)
+// aggregatorMetricName is the name, before namespacing, under which the metrics of Aggregator.Store are
+// grouped. The metrics of each backend are named after the backend, and only created once used.
+const aggregatorMetricName = "rpc/aggregator/store"
 
 type AggregatorConfig struct {
 	Enable                bool              `koanf:"enable"`
@@ -42,6 +36,7 @@ type AggregatorConfig struct {
 	Backends              BackendConfigList `koanf:"backends"`
 	MaxStoreChunkBodySize int               `koanf:"max-store-chunk-body-size"`
 	EnableChunkedStore    bool              `koanf:"enable-chunked-store"`
+	MaxKeysetSize         int               `koanf:"max-keyset-size"`
 }
 
 var DefaultAggregatorConfig = AggregatorConfig{
@@ -49,6 +44,7 @@ var DefaultAggregatorConfig = AggregatorConfig{
 	Backends:              nil,
 	MaxStoreChunkBodySize: 512 * 1024,
 	EnableChunkedStore:    true,
+	MaxKeysetSize:         dastree.BinSize,
 }
 
 var parsedBackendsConf BackendConfigList
@@ -59,6 +55,7 @@ func AggregatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Var(&parsedBackendsConf, prefix+".backends", "JSON RPC backend configuration. This can be specified on the command line as a JSON array, eg: [{\"url\": \"...\", \"pubkey\": \"...\"},...], or as a JSON array in the config file.")
 	f.Int(prefix+".max-store-chunk-body-size", DefaultAggregatorConfig.MaxStoreChunkBodySize, "maximum HTTP POST body size to use for individual batch chunks, including JSON RPC overhead and an estimated overhead of 512B of headers")
 	f.Bool(prefix+".enable-chunked-store", DefaultAggregatorConfig.EnableChunkedStore, "enable data to be sent to DAS in chunks instead of all at once")
+	f.Int(prefix+".max-keyset-size", DefaultAggregatorConfig.MaxKeysetSize, "maximum size in bytes of the serialized keyset of the backends")
 }
 
 type Aggregator struct {
@@ -71,6 +68,10 @@ type Aggregator struct {
 	maxAllowedServiceStoreFailures int
 	keysetHash                     [32]byte
 	keysetBytes                    []byte
+	metricBase                     string
+	// anyErrorGauge shows 1 if there was any error posting to the backends, until there was a
+	// Store that had no backend failures.
+	anyErrorGauge *metrics.Gauge
 }
 
 type ServiceDetails struct {
@@ -134,11 +135,12 @@ func NewAggregatorWithSeqInboxCaller(
 ) (*Aggregator, error) {
 
 	// #nosec G115
-	keysetHash, keysetBytes, err := KeysetHashFromServices(services, uint64(config.RPCAggregator.AssumedHonest))
+	keysetHash, keysetBytes, err := KeysetHashFromServicesWithMaxSize(services, uint64(config.RPCAggregator.AssumedHonest), config.RPCAggregator.MaxKeysetSize)
 	if err != nil {
 		return nil, err
 	}
 
+	metricBase := dasutil.MetricName(config.MetricsNamespace, aggregatorMetricName)
 	return &Aggregator{
 		config:                         config.RPCAggregator,
 		services:                       services,
@@ -147,6 +149,8 @@ func NewAggregatorWithSeqInboxCaller(
 		maxAllowedServiceStoreFailures: config.RPCAggregator.AssumedHonest - 1,
 		keysetHash:                     keysetHash,
 		keysetBytes:                    keysetBytes,
+		metricBase:                     metricBase,
+		anyErrorGauge:                  metrics.GetOrRegisterGauge(metricBase+"/error/gauge", nil),
 	}, nil
 }
 
@@ -170,32 +174,54 @@ type storeResponse struct {
 // If Store gets not enough successful responses by the time its context is canceled
 // (eg via TimeoutWrapper) then it also returns an error.
 func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64) (*dasutil.DataAvailabilityCertificate, error) {
+	return a.StoreWithVersion(ctx, message, timeout, dasutil.DefaultCertVersion)
+}
+
+// StoreWithVersion is Store, producing a cert of the given version. All backends must be able to
+// sign certs of that version, since the aggregated signature covers it.
+func (a *Aggregator) StoreWithVersion(ctx context.Context, message []byte, timeout uint64, version uint8) (*dasutil.DataAvailabilityCertificate, error) {
 	// #nosec G115
-	log.Trace("das.Aggregator.Store", "message", pretty.FirstFewBytes(message), "timeout", time.Unix(int64(timeout), 0))
+	log.Trace("das.Aggregator.Store", "message", pretty.FirstFewBytes(message), "timeout", time.Unix(int64(timeout), 0), "version", version)
+	if err := dasutil.ValidateWritableCertVersion(version); err != nil {
+		return nil, err
+	}
+	if version != dasutil.DefaultCertVersion {
+		for _, d := range a.services {
+			if _, ok := d.service.(dasutil.VersionedDASWriter); !ok {
+				return nil, fmt.Errorf("%w: backend %v can only produce version %d certs", dasutil.ErrUnsupportedCertVersion, d.service, dasutil.DefaultCertVersion)
+			}
+		}
+	}
 
 	allBackendsSucceeded := false
 	defer func() {
 		if allBackendsSucceeded {
-			anyErrorGauge.Update(0)
+			a.anyErrorGauge.Update(0)
 		} else {
-			anyErrorGauge.Update(1)
+			a.anyErrorGauge.Update(1)
 		}
 	}()
 
 	responses := make(chan storeResponse, len(a.services))
 
-	expectedHash := dastree.Hash(message)
+	expectedHash := dasutil.CertDataHash(message, version)
 	for _, d := range a.services {
 		go func(ctx context.Context, d ServiceDetails) {
 			storeCtx, cancel := context.WithTimeout(ctx, a.requestTimeout)
-			var metricWithServiceName = metricBase + "/" + d.metricName
+			var metricWithServiceName = a.metricBase + "/" + d.metricName
 			defer cancel()
 			incFailureMetric := func() {
 				metrics.GetOrRegisterCounter(metricWithServiceName+"/error/total", nil).Inc(1)
-				metrics.GetOrRegisterCounter(metricBase+"/error/all/total", nil).Inc(1)
+				metrics.GetOrRegisterCounter(a.metricBase+"/error/all/total", nil).Inc(1)
 			}
 
-			cert, err := d.service.Store(storeCtx, message, timeout)
+			var cert *dasutil.DataAvailabilityCertificate
+			var err error
+			if version == dasutil.DefaultCertVersion {
+				cert, err = d.service.Store(storeCtx, message, timeout)
+			} else {
+				cert, err = d.service.(dasutil.VersionedDASWriter).StoreWithVersion(storeCtx, message, timeout, version)
+			}
 			if err != nil {
 				incFailureMetric()
 				log.Warn("DAS Aggregator failed to store batch to backend", "backend", d.metricName, "err", err)
@@ -203,9 +229,14 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 				return
 			}
 
-			verified, err := blsSignatures.VerifySignature(
-				cert.Sig, cert.SerializeSignableFields(), d.pubKey,
-			)
+			signableFields, err := cert.SerializeSignableFields()
+			if err != nil {
+				incFailureMetric()
+				log.Warn("DAS Aggregator got a malformed cert from backend", "backend", d.metricName, "err", err)
+				responses <- storeResponse{d, nil, err}
+				return
+			}
+			verified, err := blsSignatures.VerifySignature(cert.Sig, signableFields, d.pubKey)
 			if err != nil {
 				incFailureMetric()
 				log.Warn("DAS Aggregator couldn't parse backend's store response signature", "backend", d.metricName, "err", err)
@@ -235,7 +266,7 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 			}
 
 			metrics.GetOrRegisterCounter(metricWithServiceName+"/success/total", nil).Inc(1)
-			metrics.GetOrRegisterCounter(metricBase+"/success/all/total", nil).Inc(1)
+			metrics.GetOrRegisterCounter(a.metricBase+"/success/all/total", nil).Inc(1)
 			responses <- storeResponse{d, cert.Sig, nil}
 		}(ctx, d)
 	}
@@ -315,9 +346,13 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 	aggCert.DataHash = expectedHash
 	aggCert.Timeout = timeout
 	aggCert.KeysetHash = a.keysetHash
-	aggCert.Version = 1
+	aggCert.Version = version
 
-	verified, err := blsSignatures.VerifySignature(aggCert.Sig, aggCert.SerializeSignableFields(), aggPubKey)
+	signableFields, err := aggCert.SerializeSignableFields()
+	if err != nil {
+		return nil, err
+	}
+	verified, err := blsSignatures.VerifySignature(aggCert.Sig, signableFields, aggPubKey)
 	if err != nil {
 		//nolint:errorlint
 		return nil, fmt.Errorf("%s. %w", err.Error(), dasutil.ErrBatchToDasFailed)
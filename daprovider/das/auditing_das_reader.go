@@ -0,0 +1,204 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var ErrAuditLogTampered = errors.New("audit log chain hash mismatch")
+
+// AuditRecord describes a single GetByHash call made through an AuditingDASReader.
+type AuditRecord struct {
+	Hash      common.Hash `json:"hash"`
+	Time      time.Time   `json:"time"`
+	Success   bool        `json:"success"`
+	Length    int         `json:"length"`
+	Error     string      `json:"error,omitempty"`
+	ChainHash common.Hash `json:"chainHash"`
+}
+
+// AuditSink receives the records of an AuditingDASReader, in the order the calls completed.
+type AuditSink interface {
+	RecordFetch(record AuditRecord) error
+}
+
+// AuditingDASReader records every preimage fetched through it to an AuditSink, so that operators
+// have an audit log of the data a node fetched during recovery. Results are returned unaltered,
+// including when the sink fails to record them.
+//
+// Records are chained: each record's ChainHash commits to the record and the ChainHash of the one
+// before it, so a log that was modified, reordered or truncated at the start fails VerifyAuditLog.
+type AuditingDASReader struct {
+	DataAvailabilityServiceReader
+	sink AuditSink
+	now  func() time.Time
+
+	mutex     sync.Mutex
+	chainHash common.Hash
+}
+
+// chainedAuditSink is implemented by sinks that already hold records, which new records must
+// continue the chain of.
+type chainedAuditSink interface {
+	LastChainHash() common.Hash
+}
+
+func NewAuditingDASReader(reader DataAvailabilityServiceReader, sink AuditSink) *AuditingDASReader {
+	r := &AuditingDASReader{
+		DataAvailabilityServiceReader: reader,
+		sink:                          sink,
+		now:                           time.Now,
+	}
+	if chained, ok := sink.(chainedAuditSink); ok {
+		r.chainHash = chained.LastChainHash()
+	}
+	return r
+}
+
+func (r *AuditingDASReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	data, err := r.DataAvailabilityServiceReader.GetByHash(ctx, hash)
+	record := AuditRecord{
+		Hash:    hash,
+		Time:    r.now().UTC(),
+		Success: err == nil,
+		Length:  len(data),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	record.ChainHash = auditChainHash(r.chainHash, record)
+	if sinkErr := r.sink.RecordFetch(record); sinkErr != nil {
+		log.Warn("Failed to record DAS fetch to audit log", "hash", hash, "err", sinkErr)
+	} else {
+		r.chainHash = record.ChainHash
+	}
+	return data, err
+}
+
+func (r *AuditingDASReader) String() string {
+	return fmt.Sprintf("AuditingDASReader{%v}", r.DataAvailabilityServiceReader)
+}
+
+// auditChainHash returns the chain hash of record, following the record with the given chain hash.
+func auditChainHash(previous common.Hash, record AuditRecord) common.Hash {
+	record.ChainHash = common.Hash{}
+	// Marshaling a struct of these field types can't fail.
+	encoded, _ := json.Marshal(record)
+	return crypto.Keccak256Hash(previous.Bytes(), encoded)
+}
+
+// VerifyAuditLog checks that the records, in order, form an unbroken chain from the start of a log.
+func VerifyAuditLog(records []AuditRecord) error {
+	var chainHash common.Hash
+	for i, record := range records {
+		chainHash = auditChainHash(chainHash, record)
+		if record.ChainHash != chainHash {
+			return fmt.Errorf("%w at record %d", ErrAuditLogTampered, i)
+		}
+	}
+	return nil
+}
+
+// MemoryAuditSink keeps audit records in memory.
+type MemoryAuditSink struct {
+	mutex   sync.Mutex
+	records []AuditRecord
+}
+
+func (s *MemoryAuditSink) RecordFetch(record AuditRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+// Records returns a copy of the records received so far.
+func (s *MemoryAuditSink) Records() []AuditRecord {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]AuditRecord{}, s.records...)
+}
+
+// FileAuditSink appends audit records to a file as JSON lines, syncing each one to disk before the
+// fetch it records is returned.
+type FileAuditSink struct {
+	mutex         sync.Mutex
+	file          *os.File
+	lastChainHash common.Hash
+}
+
+// NewFileAuditSink opens the audit log at path, creating it if needed. The records already in the
+// log are verified, and new records continue their chain.
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	records, err := ReadAuditLog(file)
+	if err == nil {
+		err = VerifyAuditLog(records)
+	}
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("existing audit log %s is invalid: %w", path, err), file.Close())
+	}
+	sink := &FileAuditSink{file: file}
+	if len(records) > 0 {
+		sink.lastChainHash = records[len(records)-1].ChainHash
+	}
+	return sink, nil
+}
+
+// LastChainHash returns the chain hash of the last record in the log when it was opened.
+func (s *FileAuditSink) LastChainHash() common.Hash {
+	return s.lastChainHash
+}
+
+func (s *FileAuditSink) RecordFetch(record AuditRecord) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, err := s.file.Write(append(encoded, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileAuditSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.file.Close()
+}
+
+// ReadAuditLog reads the records written by a FileAuditSink.
+func ReadAuditLog(rd io.Reader) ([]AuditRecord, error) {
+	var records []AuditRecord
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid audit record %d: %w", len(records), err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,106 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+type stubDASReader struct {
+	contents map[common.Hash][]byte
+}
+
+func (r *stubDASReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	data, ok := r.contents[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func (r *stubDASReader) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
+	return dasutil.KeepForever, nil
+}
+
+func (r *stubDASReader) String() string {
+	return "stubDASReader"
+}
+
+func TestAuditingDASReaderRecordsFetches(t *testing.T) {
+	ctx := context.Background()
+	value := []byte("audited preimage")
+	present := dastree.Hash(value)
+	missing := dastree.Hash([]byte("missing preimage"))
+	sink := &MemoryAuditSink{}
+	reader := NewAuditingDASReader(&stubDASReader{contents: map[common.Hash][]byte{present: value}}, sink)
+	now := time.Unix(1000, 0)
+	reader.now = func() time.Time { return now }
+
+	data, err := reader.GetByHash(ctx, present)
+	Require(t, err)
+	if !bytes.Equal(data, value) {
+		Fail(t, "auditing altered the fetched data", data)
+	}
+	now = now.Add(time.Second)
+	if _, err := reader.GetByHash(ctx, missing); !errors.Is(err, ErrNotFound) {
+		Fail(t, "auditing altered the fetch error", err)
+	}
+
+	records := sink.Records()
+	if len(records) != 2 {
+		Fail(t, "expected a record per fetch, got", len(records))
+	}
+	if records[0].Hash != present || !records[0].Success || records[0].Length != len(value) || !records[0].Time.Equal(time.Unix(1000, 0)) {
+		Fail(t, "unexpected record of successful fetch", records[0])
+	}
+	if records[1].Hash != missing || records[1].Success || records[1].Length != 0 || records[1].Error == "" || !records[1].Time.Equal(time.Unix(1001, 0)) {
+		Fail(t, "unexpected record of failed fetch", records[1])
+	}
+	Require(t, VerifyAuditLog(records))
+
+	records[0].Length++
+	if err := VerifyAuditLog(records); !errors.Is(err, ErrAuditLogTampered) {
+		Fail(t, "expected modified record to be detected, got", err)
+	}
+	if err := VerifyAuditLog(sink.Records()[1:]); !errors.Is(err, ErrAuditLogTampered) {
+		Fail(t, "expected truncated log to be detected, got", err)
+	}
+}
+
+func TestFileAuditSinkContinuesChain(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	value := []byte("audited preimage")
+	stub := &stubDASReader{contents: map[common.Hash][]byte{dastree.Hash(value): value}}
+
+	for i := 0; i < 2; i++ {
+		sink, err := NewFileAuditSink(path)
+		Require(t, err)
+		reader := NewAuditingDASReader(stub, sink)
+		_, err = reader.GetByHash(ctx, dastree.Hash(value))
+		Require(t, err)
+		Require(t, sink.Close())
+	}
+
+	file, err := os.Open(path)
+	Require(t, err)
+	defer file.Close()
+	records, err := ReadAuditLog(file)
+	Require(t, err)
+	if len(records) != 2 {
+		Fail(t, "expected records from both sinks, got", len(records))
+	}
+	Require(t, VerifyAuditLog(records))
+}
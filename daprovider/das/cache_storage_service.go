@@ -37,8 +37,9 @@ func CacheConfigAddOptions(prefix string, f *flag.FlagSet) {
 }
 
 type CacheStorageService struct {
-	baseStorageService StorageService
-	cache              *lru.Cache[common.Hash, []byte]
+	baseStorageService          StorageService
+	cache                       *lru.Cache[common.Hash, []byte]
+	expirationPolicyAggregation ExpirationPolicyAggregation
 }
 
 func NewCacheStorageService(cacheConfig CacheConfig, baseStorageService StorageService) *CacheStorageService {
@@ -75,6 +76,14 @@ func (c *CacheStorageService) Put(ctx context.Context, value []byte, timeout uin
 	return nil
 }
 
+func (c *CacheStorageService) ValidatePut(ctx context.Context, value []byte) error {
+	return c.baseStorageService.ValidatePut(ctx, value)
+}
+
+func (c *CacheStorageService) Refresh(ctx context.Context, key common.Hash, timeout uint64) error {
+	return c.baseStorageService.Refresh(ctx, key, timeout)
+}
+
 func (c *CacheStorageService) Sync(ctx context.Context) error {
 	return c.baseStorageService.Sync(ctx)
 }
@@ -83,8 +92,14 @@ func (c *CacheStorageService) Close(ctx context.Context) error {
 	return c.baseStorageService.Close(ctx)
 }
 
+// SetExpirationPolicyAggregation sets how the policy of the cache is aggregated with the policy of
+// its base, AggregateMostDurable by default. It must be called before the service is used.
+func (c *CacheStorageService) SetExpirationPolicyAggregation(aggregation ExpirationPolicyAggregation) {
+	c.expirationPolicyAggregation = aggregation
+}
+
 func (c *CacheStorageService) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
-	return c.baseStorageService.ExpirationPolicy(ctx)
+	return chainedExpirationPolicy(ctx, c.expirationPolicyAggregation, cacheTierExpirationPolicy, c.baseStorageService)
 }
 
 func (c *CacheStorageService) String() string {
@@ -6,62 +6,44 @@ package das
 import (
 	"context"
 	"errors"
-	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 
-	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/util/pretty"
 )
 
-type syncedKeysetCache struct {
-	cache map[[32]byte][]byte
-	sync.RWMutex
-}
-
-func (c *syncedKeysetCache) get(key [32]byte) ([]byte, bool) {
-	c.RLock()
-	defer c.RUnlock()
-	res, ok := c.cache[key]
-	return res, ok
-}
-
-func (c *syncedKeysetCache) put(key [32]byte, value []byte) {
-	c.Lock()
-	defer c.Unlock()
-	c.cache[key] = value
-}
-
 type KeysetFetcher struct {
 	seqInboxCaller   *bridgegen.SequencerInboxCaller
 	seqInboxFilterer *bridgegen.SequencerInboxFilterer
-	keysetCache      syncedKeysetCache
+	keysetCache      *keysetCache
 }
 
-func NewKeysetFetcher(l1client *ethclient.Client, seqInboxAddr common.Address) (*KeysetFetcher, error) {
+// NewKeysetFetcher returns a KeysetFetcher reading keysets from the SetValidKeyset logs of the
+// sequencer inbox at seqInboxAddr, through any contract backend such as an ethclient.Client.
+func NewKeysetFetcher(l1client bind.ContractBackend, seqInboxAddr common.Address, cacheConfig KeysetCacheConfig) (*KeysetFetcher, error) {
 	seqInbox, err := bridgegen.NewSequencerInbox(seqInboxAddr, l1client)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewKeysetFetcherWithSeqInbox(seqInbox)
+	return NewKeysetFetcherWithSeqInbox(seqInbox, cacheConfig)
 }
 
-func NewKeysetFetcherWithSeqInbox(seqInbox *bridgegen.SequencerInbox) (*KeysetFetcher, error) {
+func NewKeysetFetcherWithSeqInbox(seqInbox *bridgegen.SequencerInbox, cacheConfig KeysetCacheConfig) (*KeysetFetcher, error) {
 	return &KeysetFetcher{
 		seqInboxCaller:   &seqInbox.SequencerInboxCaller,
 		seqInboxFilterer: &seqInbox.SequencerInboxFilterer,
-		keysetCache:      syncedKeysetCache{cache: make(map[[32]byte][]byte)},
+		keysetCache:      newKeysetCache(cacheConfig),
 	}, nil
 }
 
 func (c *KeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
 	log.Trace("das.KeysetFetcher.GetKeysetByHash", "hash", pretty.PrettyHash(hash))
-	cache := &c.keysetCache
+	cache := c.keysetCache
 	seqInboxCaller := c.seqInboxCaller
 	seqInboxFilterer := c.seqInboxFilterer
 
@@ -92,7 +74,7 @@ func (c *KeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) (
 		return nil, err
 	}
 	for iter.Next() {
-		if dastree.ValidHash(hash, iter.Event.KeysetBytes) {
+		if dasutil.ValidKeysetHash(0, hash, iter.Event.KeysetBytes) {
 			cache.put(hash, iter.Event.KeysetBytes)
 			return iter.Event.KeysetBytes, nil
 		}
@@ -103,3 +85,17 @@ func (c *KeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) (
 
 	return nil, ErrNotFound
 }
+
+// IsKeysetValid reports whether the sequencer inbox currently considers the keyset valid, for use as
+// dasutil.RecoveryOptions.CheckKeysetValidity. The sequencer inbox doesn't keep the history of
+// keyset validity, so batchNum is ignored and keysets invalidated after the batch are rejected.
+func (c *KeysetFetcher) IsKeysetValid(ctx context.Context, keysetHash common.Hash, batchNum uint64) (bool, error) {
+	return c.seqInboxCaller.IsValidKeysetHash(&bind.CallOpts{Context: ctx}, keysetHash)
+}
+
+// AvailableKeysets returns the keysets that have been fetched from the parent chain and are still cached.
+func (c *KeysetFetcher) AvailableKeysets(ctx context.Context) ([]common.Hash, error) {
+	hashes := c.keysetCache.keys()
+	sortHashes(hashes)
+	return hashes, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+)
+
+// mockSequencerInboxBackend serves the keyset getters and SetValidKeyset logs of a sequencer inbox.
+type mockSequencerInboxBackend struct {
+	bind.ContractBackend
+	abi            *abi.ABI
+	keysets        map[common.Hash][]byte
+	creationBlocks map[common.Hash]uint64
+	filterCalls    int
+}
+
+func (b *mockSequencerInboxBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := b.abi.MethodById(call.Data)
+	if err != nil {
+		return nil, err
+	}
+	if method.Name != "getKeysetCreationBlock" {
+		return nil, fmt.Errorf("unexpected call of %s", method.Name)
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	block, ok := b.creationBlocks[common.Hash(args[0].([32]byte))]
+	if !ok {
+		return nil, errors.New("execution reverted: NoSuchKeyset")
+	}
+	return method.Outputs.Pack(new(big.Int).SetUint64(block))
+}
+
+func (b *mockSequencerInboxBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	b.filterCalls++
+	event := b.abi.Events["SetValidKeyset"]
+	var logs []types.Log
+	for _, hash := range query.Topics[1] {
+		keyset, ok := b.keysets[hash]
+		if !ok || query.FromBlock.Uint64() != b.creationBlocks[hash] {
+			continue
+		}
+		data, err := event.Inputs.NonIndexed().Pack(keyset)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, types.Log{
+			Address:     query.Addresses[0],
+			Topics:      []common.Hash{event.ID, hash},
+			Data:        data,
+			BlockNumber: b.creationBlocks[hash],
+		})
+	}
+	return logs, nil
+}
+
+func TestKeysetFetcherWithContractBackend(t *testing.T) {
+	ctx := context.Background()
+	seqInboxAbi, err := bridgegen.SequencerInboxMetaData.GetAbi()
+	Require(t, err)
+	keyset := []byte("keyset set on chain")
+	keysetHash := dastree.Hash(keyset)
+	// A log claiming a hash its keyset doesn't match is ignored.
+	forgedHash := dastree.Hash([]byte("another keyset"))
+	backend := &mockSequencerInboxBackend{
+		abi:            seqInboxAbi,
+		keysets:        map[common.Hash][]byte{keysetHash: keyset, forgedHash: keyset},
+		creationBlocks: map[common.Hash]uint64{keysetHash: 100, forgedHash: 200},
+	}
+
+	fetcher, err := NewKeysetFetcher(backend, common.HexToAddress("0x1234"), DefaultKeysetCacheConfig)
+	Require(t, err)
+	for i := 0; i < 2; i++ {
+		fetched, err := fetcher.GetKeysetByHash(ctx, keysetHash)
+		Require(t, err)
+		if !bytes.Equal(fetched, keyset) {
+			Fail(t, "fetched wrong keyset", string(fetched))
+		}
+	}
+	if backend.filterCalls != 1 {
+		Fail(t, "expected the keyset to be fetched from the chain once, got", backend.filterCalls, "log queries")
+	}
+
+	if _, err := fetcher.GetKeysetByHash(ctx, forgedHash); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected a keyset not matching its hash to be rejected, got", err)
+	}
+	if _, err := fetcher.GetKeysetByHash(ctx, common.Hash{1}); err == nil {
+		Fail(t, "expected fetching an unknown keyset to fail")
+	}
+}
@@ -39,15 +39,23 @@ type DataAvailabilityConfig struct {
 
 	RequestTimeout time.Duration `koanf:"request-timeout"`
 
-	LocalCache CacheConfig `koanf:"local-cache"`
-	RedisCache RedisConfig `koanf:"redis-cache"`
+	LocalCache  CacheConfig       `koanf:"local-cache"`
+	RedisCache  RedisConfig       `koanf:"redis-cache"`
+	KeysetCache KeysetCacheConfig `koanf:"keyset-cache"`
 
 	LocalDBStorage     LocalDBStorageConfig            `koanf:"local-db-storage"`
 	LocalFileStorage   LocalFileStorageConfig          `koanf:"local-file-storage"`
 	S3Storage          S3StorageServiceConfig          `koanf:"s3-storage"`
 	GoogleCloudStorage GoogleCloudStorageServiceConfig `koanf:"google-cloud-storage"`
 
-	MigrateLocalDBToFileStorage bool `koanf:"migrate-local-db-to-file-storage"`
+	Encryption  EncryptionConfig           `koanf:"encryption"`
+	HealthCheck HealthCheckSchedulerConfig `koanf:"health-check"`
+
+	PrometheusMetrics PrometheusMetricsConfig `koanf:"prometheus-metrics"`
+
+	MigrateLocalDBToFileStorage bool   `koanf:"migrate-local-db-to-file-storage"`
+	ExpirationPolicyAggregation string `koanf:"expiration-policy-aggregation"`
+	AllowEphemeralStorage       bool   `koanf:"allow-ephemeral-storage"`
 
 	Key KeyConfig `koanf:"key"`
 
@@ -61,6 +69,8 @@ type DataAvailabilityConfig struct {
 
 	PanicOnError             bool `koanf:"panic-on-error"`
 	DisableSignatureChecking bool `koanf:"disable-signature-checking"`
+
+	MetricsNamespace string `koanf:"metrics-namespace"`
 }
 
 var DefaultDataAvailabilityConfig = DataAvailabilityConfig{
@@ -68,10 +78,21 @@ var DefaultDataAvailabilityConfig = DataAvailabilityConfig{
 	Enable:                        false,
 	RestAggregator:                DefaultRestfulClientAggregatorConfig,
 	RPCAggregator:                 DefaultAggregatorConfig,
+	KeysetCache:                   DefaultKeysetCacheConfig,
+	ExpirationPolicyAggregation:   "most-durable",
+	HealthCheck:                   DefaultHealthCheckSchedulerConfig,
+	PrometheusMetrics:             DefaultPrometheusMetricsConfig,
 	ParentChainConnectionAttempts: 15,
 	PanicOnError:                  false,
 }
 
+// keysetCacheConfig returns the KeysetCache config, with its metrics namespaced by MetricsNamespace.
+func (c *DataAvailabilityConfig) keysetCacheConfig() KeysetCacheConfig {
+	cacheConfig := c.KeysetCache
+	cacheConfig.MetricsNamespace = c.MetricsNamespace
+	return cacheConfig
+}
+
 func OptionalAddressFromString(s string) (*common.Address, error) {
 	if s == "none" {
 		return nil, nil
@@ -104,6 +125,7 @@ const (
 func dataAvailabilityConfigAddOptions(prefix string, f *flag.FlagSet, r role) {
 	f.Bool(prefix+".enable", DefaultDataAvailabilityConfig.Enable, "enable Anytrust Data Availability mode")
 	f.Bool(prefix+".panic-on-error", DefaultDataAvailabilityConfig.PanicOnError, "whether the Data Availability Service should fail immediately on errors (not recommended)")
+	f.String(prefix+".metrics-namespace", DefaultDataAvailabilityConfig.MetricsNamespace, "namespace inserted into the names of the DAS metrics, e.g. the chain name, to tell apart the metrics of several chains served by the same process")
 
 	if r == roleDaserver {
 		f.Bool(prefix+".disable-signature-checking", DefaultDataAvailabilityConfig.DisableSignatureChecking, "disables signature checking on Data Availability Store requests (DANGEROUS, FOR TESTING ONLY)")
@@ -117,7 +139,12 @@ func dataAvailabilityConfigAddOptions(prefix string, f *flag.FlagSet, r role) {
 		LocalFileStorageConfigAddOptions(prefix+".local-file-storage", f)
 		S3ConfigAddOptions(prefix+".s3-storage", f)
 		GoogleCloudConfigAddOptions(prefix+".google-cloud-storage", f)
+		EncryptionConfigAddOptions(prefix+".encryption", f)
+		HealthCheckSchedulerConfigAddOptions(prefix+".health-check", f)
+		PrometheusMetricsConfigAddOptions(prefix+".prometheus-metrics", f)
 		f.Bool(prefix+".migrate-local-db-to-file-storage", DefaultDataAvailabilityConfig.MigrateLocalDBToFileStorage, "daserver will migrate all data on startup from local-db-storage to local-file-storage, then mark local-db-storage as unusable")
+		f.String(prefix+".expiration-policy-aggregation", DefaultDataAvailabilityConfig.ExpirationPolicyAggregation, "how the expiration policy of multiple storage backends, and of the caches and fallbacks chained in front of them, is reported; \"most-durable\" reports the backend data survives longest in, \"least-durable\" the backend data expires first in, counting caches as keeping no data")
+		f.Bool(prefix+".allow-ephemeral-storage", DefaultDataAvailabilityConfig.AllowEphemeralStorage, "allow starting with no storage backend keeping data forever, in which case stored data is eventually lost")
 
 		// Key config for storage
 		KeyConfigAddOptions(prefix+".key", f)
@@ -132,6 +159,7 @@ func dataAvailabilityConfigAddOptions(prefix string, f *flag.FlagSet, r role) {
 
 	// Both the Nitro node and daserver can use these options.
 	RestfulClientAggregatorConfigAddOptions(prefix+".rest-aggregator", f)
+	KeysetCacheConfigAddOptions(prefix+".keyset-cache", f)
 
 	f.String(prefix+".parent-chain-node-url", DefaultDataAvailabilityConfig.ParentChainNodeURL, "URL for parent chain node, only used in standalone daserver and daprovider; when running as part of a node that node's L1 configuration is used")
 	f.Int(prefix+".parent-chain-connection-attempts", DefaultDataAvailabilityConfig.ParentChainConnectionAttempts, "parent chain RPC connection attempts (spaced out at least 1 second per attempt, 0 to retry infinitely), only used in standalone daserver; when running as part of a node that node's parent chain configuration is used")
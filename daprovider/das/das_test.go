@@ -6,10 +6,18 @@ package das
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
 	"github.com/offchainlabs/nitro/util/testhelpers"
 )
 
@@ -177,6 +185,183 @@ func TestDASMissingMessageDB(t *testing.T) {
 	testDASMissingMessage(t, "db")
 }
 
+func TestBuildDASReaderWriterRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dbPath := t.TempDir()
+	_, _, err := GenerateAndStoreKeys(dbPath)
+	Require(t, err)
+
+	config := DefaultDataAvailabilityConfig
+	config.Enable = true
+	config.Key = KeyConfig{KeyDir: dbPath}
+	config.LocalFileStorage = DefaultLocalFileStorageConfig
+	config.LocalFileStorage.Enable = true
+	config.LocalFileStorage.DataDir = dbPath
+	config.LocalCache = DefaultCacheConfig
+	config.LocalCache.Enable = true
+
+	stack, err := BuildDASReaderWriter(ctx, &config, nil)
+	Require(t, err)
+	defer stack.LifecycleManager.StopAndWaitUntil(time.Second)
+	if stack.Writer == nil {
+		Fail(t, "expected a writer to be built from the signing key")
+	}
+
+	message := []byte("round trip through the configured stack")
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour * 24).Unix())
+	certBytes, err := stack.Writer.Store(ctx, message, timeout, true)
+	Require(t, err)
+
+	sequencerMsg := append(make([]byte, 40), certBytes...)
+	payload, _, err := stack.Reader.RecoverPayloadFromBatch(ctx, 0, common.Hash{}, sequencerMsg, nil, true)
+	Require(t, err)
+	if !bytes.Equal(payload, message) {
+		Fail(t, "recovered payload doesn't match the stored message", payload)
+	}
+
+	// Without a signing key or a parent chain there is no way to resolve keysets.
+	config.Key = KeyConfig{}
+	if _, err := BuildDASReaderWriter(ctx, &config, nil); err == nil {
+		Fail(t, "expected building a stack that can't resolve keysets to fail")
+	}
+}
+
+func TestCreatePersistentStorageServiceRequiresDurableStorage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultDataAvailabilityConfig
+	config.Enable = true
+	config.LocalFileStorage = DefaultLocalFileStorageConfig
+	config.LocalFileStorage.Enable = true
+	config.LocalFileStorage.DataDir = t.TempDir()
+	config.LocalFileStorage.EnableExpiry = true
+	config.LocalDBStorage = DefaultLocalDBStorageConfig
+	config.LocalDBStorage.Enable = true
+	config.LocalDBStorage.DataDir = t.TempDir()
+	config.LocalDBStorage.DiscardAfterTimeout = true
+
+	// Every backend discards data after its timeout.
+	if _, _, err := CreatePersistentStorageService(ctx, &config); err == nil || !strings.Contains(err.Error(), "allow-ephemeral-storage") {
+		Fail(t, "expected a stack without durable storage to be refused, got", err)
+	}
+
+	// The refused stack isn't closed, so the override is tried on new directories.
+	config.AllowEphemeralStorage = true
+	config.LocalFileStorage.DataDir = t.TempDir()
+	config.LocalDBStorage.DataDir = t.TempDir()
+	storageService, lifecycleManager, err := CreatePersistentStorageService(ctx, &config)
+	Require(t, err)
+	defer lifecycleManager.StopAndWaitUntil(time.Second)
+	policy, err := storageService.ExpirationPolicy(ctx)
+	Require(t, err)
+	if policy != dasutil.DiscardAfterDataTimeout {
+		Fail(t, "expected ephemeral storage, got policy", policy)
+	}
+}
+
+func TestStorageEncryptionCoversEveryTier(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, err := miniredis.Run()
+	Require(t, err)
+	config := DefaultDataAvailabilityConfig
+	config.Enable = true
+	config.LocalFileStorage = DefaultLocalFileStorageConfig
+	config.LocalFileStorage.Enable = true
+	config.LocalFileStorage.DataDir = t.TempDir()
+	config.RedisCache = DefaultRedisConfig
+	config.RedisCache.Enable = true
+	config.RedisCache.Url = "redis://" + server.Addr()
+	config.RedisCache.KeyConfig = "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9"
+	config.Encryption = EncryptionConfig{Enable: true, KeyConfig: testEncryptionKey}
+
+	storageService, lifecycleManager, err := CreatePersistentStorageService(ctx, &config)
+	Require(t, err)
+	defer lifecycleManager.StopAndWaitUntil(time.Second)
+	storageService, err = WrapStorageWithCache(ctx, &config, storageService, lifecycleManager)
+	Require(t, err)
+
+	value := []byte("a value that must only be stored encrypted")
+	key := dastree.Hash(value)
+	// #nosec G115
+	Require(t, storageService.Put(ctx, value, uint64(time.Now().Add(time.Hour).Unix())))
+	stored, err := storageService.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(stored, value) {
+		Fail(t, "encrypted value doesn't round trip", stored)
+	}
+
+	cached, err := server.Get(string(key.Bytes()))
+	Require(t, err)
+	if strings.Contains(cached, string(value)) {
+		Fail(t, "value cached in Redis in plaintext")
+	}
+	plainFiles, err := NewLocalFileStorageService(config.LocalFileStorage)
+	Require(t, err)
+	onDisk, err := plainFiles.GetByHash(ctx, key)
+	Require(t, err)
+	if bytes.Contains(onDisk, value) {
+		Fail(t, "value stored on disk in plaintext")
+	}
+
+	// Encryption that can't be applied to every backend is refused rather than skipped.
+	config.LocalFileStorage.DataDir = t.TempDir()
+	config.LocalDBStorage = DefaultLocalDBStorageConfig
+	config.LocalDBStorage.Enable = true
+	config.LocalDBStorage.DataDir = t.TempDir()
+	if _, _, err := CreatePersistentStorageService(ctx, &config); err == nil || !strings.Contains(err.Error(), "encryption") {
+		Fail(t, "expected encryption of a backend not supporting it to be refused, got", err)
+	}
+	if _, _, _, err := CreateDAReader(ctx, &config, nil, nil); err == nil || !strings.Contains(err.Error(), "encryption") {
+		Fail(t, "expected encryption without local storage to be refused, got", err)
+	}
+}
+
+func TestMigrateLocalDBToEncryptedFileStorage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultDataAvailabilityConfig
+	config.Enable = true
+	config.LocalDBStorage = DefaultLocalDBStorageConfig
+	config.LocalDBStorage.Enable = true
+	config.LocalDBStorage.DataDir = t.TempDir()
+	config.LocalFileStorage = DefaultLocalFileStorageConfig
+	config.LocalFileStorage.Enable = true
+	config.LocalFileStorage.DataDir = t.TempDir()
+	config.MigrateLocalDBToFileStorage = true
+	config.Encryption = EncryptionConfig{Enable: true, KeyConfig: testEncryptionKey}
+
+	dbService, err := NewDBStorageService(ctx, &config.LocalDBStorage, nil)
+	Require(t, err)
+	value := []byte("a value migrated from the database")
+	key := dastree.Hash(value)
+	Require(t, dbService.Put(ctx, value, 0))
+	Require(t, dbService.Close(ctx))
+
+	storageService, lifecycleManager, err := CreatePersistentStorageService(ctx, &config)
+	Require(t, err)
+	defer lifecycleManager.StopAndWaitUntil(time.Second)
+	stored, err := storageService.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(stored, value) {
+		Fail(t, "migrated value doesn't round trip", stored)
+	}
+
+	plainFiles, err := NewLocalFileStorageService(config.LocalFileStorage)
+	Require(t, err)
+	onDisk, err := plainFiles.GetByHash(ctx, key)
+	Require(t, err)
+	if bytes.Contains(onDisk, value) {
+		Fail(t, "migrated value stored on disk in plaintext")
+	}
+}
+
 func Require(t *testing.T, err error, printables ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, printables...)
@@ -186,3 +371,62 @@ func Fail(t *testing.T, printables ...interface{}) {
 	t.Helper()
 	testhelpers.FailImpl(t, printables...)
 }
+
+func TestWriterForDASPinnedCertVersion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dbPath := t.TempDir()
+	_, _, err := GenerateAndStoreKeys(dbPath)
+	Require(t, err)
+	config := DefaultDataAvailabilityConfig
+	config.Key = KeyConfig{KeyDir: dbPath}
+	storageService := NewMemoryBackedStorageService(ctx)
+	signer, err := NewSignAfterStoreDASWriter(ctx, config, storageService)
+	Require(t, err)
+	reader := dasutil.NewReaderForDAS(storageService, signer)
+
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour * 24).Unix())
+	for _, version := range dasutil.WritableCertVersions() {
+		writer := dasutil.NewWriterForDAS(signer)
+		Require(t, writer.PinCertVersion(version))
+		message := []byte(fmt.Sprintf("message stored with a version %d cert", version))
+		certBytes, dataHash, err := writer.StoreWithDataHash(ctx, message, timeout, true)
+		Require(t, err)
+		if dataHash != dastree.Hash(message) {
+			Fail(t, "unexpected data hash for version", version, dataHash)
+		}
+
+		cert, err := dasutil.DeserializeDASCertFrom(bytes.NewReader(certBytes))
+		Require(t, err)
+		if cert.Version != version {
+			Fail(t, "expected a version", version, "cert, got version", cert.Version)
+		}
+		if cert.DataHash != dasutil.CertDataHash(message, version) {
+			Fail(t, "version", version, "cert commits to the wrong hash", cert.DataHash)
+		}
+		reserialized, err := dasutil.Serialize(cert)
+		Require(t, err)
+		if !bytes.Equal(reserialized, certBytes) {
+			Fail(t, "version", version, "cert doesn't round trip through serialization")
+		}
+
+		sequencerMsg := append(make([]byte, 40), certBytes...)
+		payload, _, err := reader.RecoverPayloadFromBatch(ctx, 0, common.Hash{}, sequencerMsg, nil, true)
+		Require(t, err)
+		if !bytes.Equal(payload, message) {
+			Fail(t, "recovered payload of version", version, "cert doesn't match the stored message", payload)
+		}
+	}
+
+	writer := dasutil.NewWriterForDAS(signer)
+	if err := writer.PinCertVersion(dasutil.MaxWritableCertVersion + 1); !errors.Is(err, dasutil.ErrUnsupportedCertVersion) {
+		Fail(t, "expected pinning an unwritable version to fail, got", err)
+	}
+	unversioned := dasutil.NewWriterForDAS(NewWriterPanicWrapper(signer))
+	if err := unversioned.PinCertVersion(0); !errors.Is(err, dasutil.ErrUnsupportedCertVersion) {
+		Fail(t, "expected pinning version 0 on a writer that can't produce it to fail, got", err)
+	}
+	Require(t, unversioned.PinCertVersion(dasutil.DefaultCertVersion))
+}
@@ -39,6 +39,7 @@ type ServerConfig struct {
 	Port               uint64                              `koanf:"port"`
 	JWTSecret          string                              `koanf:"jwtsecret"`
 	EnableDAWriter     bool                                `koanf:"enable-da-writer"`
+	Provider           string                              `koanf:"provider"`
 	DataAvailability   das.DataAvailabilityConfig          `koanf:"data-availability"`
 	ServerTimeouts     genericconf.HTTPServerTimeoutConfig `koanf:"server-timeouts"`
 	RPCServerBodyLimit int                                 `koanf:"rpc-server-body-limit"`
@@ -49,6 +50,7 @@ var DefaultServerConfig = ServerConfig{
 	Port:               9880,
 	JWTSecret:          "",
 	EnableDAWriter:     false,
+	Provider:           dasutil.DASProviderName,
 	DataAvailability:   das.DefaultDataAvailabilityConfig,
 	ServerTimeouts:     genericconf.HTTPServerTimeoutConfigDefault,
 	RPCServerBodyLimit: genericconf.HTTPServerBodyLimitDefault,
@@ -59,6 +61,7 @@ func ServerConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Uint64(prefix+".port", DefaultServerConfig.Port, "JSON rpc server listening port")
 	f.String(prefix+".jwtsecret", DefaultServerConfig.JWTSecret, "path to file with jwtsecret for validation")
 	f.Bool(prefix+".enable-da-writer", DefaultServerConfig.EnableDAWriter, "implies if the das server supports daprovider's writer interface")
+	f.String(prefix+".provider", DefaultServerConfig.Provider, "name of the DA provider to serve, among those registered at startup")
 	f.Int("rpc-server-body-limit", DefaultServerConfig.RPCServerBodyLimit, "HTTP-RPC server maximum request body size in bytes; the default (0) uses geth's 5MB limit")
 	das.DataAvailabilityConfigAddNodeOptions(prefix+".data-availability", f)
 	genericconf.HTTPServerTimeoutConfigAddOptions(prefix+".server-timeouts", f)
@@ -103,6 +106,15 @@ func NewServer(ctx context.Context, config *ServerConfig, dataSigner signature.D
 		daReader = das.NewReaderPanicWrapper(daReader)
 	}
 
+	registry := daprovider.NewRegistry()
+	if err = registry.Register(dasutil.DASProviderName, dasutil.NewDASProviderFactory(daReader, dasKeysetFetcher, daWriter)); err != nil {
+		return nil, nil, err
+	}
+	reader, writer, err := registry.Resolve(ctx, config.Provider)
+	if err != nil {
+		return nil, nil, fmt.Errorf("available DA providers are %v: %w", registry.Names(), err)
+	}
+
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Addr, config.Port))
 	if err != nil {
 		return nil, nil, err
@@ -112,12 +124,8 @@ func NewServer(ctx context.Context, config *ServerConfig, dataSigner signature.D
 	if config.RPCServerBodyLimit > 0 {
 		rpcServer.SetHTTPBodyLimit(config.RPCServerBodyLimit)
 	}
-	var writer daprovider.Writer
-	if daWriter != nil {
-		writer = dasutil.NewWriterForDAS(daWriter)
-	}
 	server := &Server{
-		reader: dasutil.NewReaderForDAS(daReader, dasKeysetFetcher),
+		reader: reader,
 		writer: writer,
 	}
 	if err = rpcServer.RegisterName("daprovider", server); err != nil {
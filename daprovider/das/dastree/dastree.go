@@ -6,6 +6,7 @@ package dastree
 import (
 	"encoding/binary"
 	"fmt"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -71,6 +72,12 @@ func RecordHash(record func(bytes32, []byte, arbutil.PreimageType), preimage ...
 		leaves = append(leaves, node{hash, uint32(end - bin)})
 	}
 
+	return treeRoot(leaves, keccord)
+}
+
+// treeRoot hashes the non-empty leaves of a tree up to its root, as described by RecordHash,
+// calling keccord to hash each node.
+func treeRoot(leaves []node, keccord func([]byte) bytes32) bytes32 {
 	layer := leaves
 	for len(layer) > 1 {
 		prior := len(layer)
@@ -82,7 +89,7 @@ func RecordHash(record func(bytes32, []byte, arbutil.PreimageType), preimage ...
 			sizeUnder := layer[i].size + layer[i+1].size
 			dataUnder := arbmath.ConcatByteSlices(firstHash, otherHash, arbmath.Uint32ToBytes(sizeUnder))
 			parent := node{
-				keccord(prepend(NodeByte, dataUnder)),
+				keccord(append([]byte{NodeByte}, dataUnder...)),
 				sizeUnder,
 			}
 			paired[i/2] = parent
@@ -95,11 +102,69 @@ func RecordHash(record func(bytes32, []byte, arbutil.PreimageType), preimage ...
 	return arbmath.FlipBit(layer[0].hash, 0)
 }
 
+// Hasher computes the dastree hash of the data written to it, as Hash does, while only holding one
+// bin of the data and the leaves of the tree in memory, so that large data can be hashed as it's
+// streamed. Its writes never fail.
+type Hasher struct {
+	bin    []byte
+	leaves []node
+}
+
+func NewHasher() *Hasher {
+	return &Hasher{bin: make([]byte, 0, BinSize)}
+}
+
+func (h *Hasher) Write(data []byte) (int, error) {
+	written := len(data)
+	for len(data) > 0 {
+		// A full bin is only hashed once more data follows, as the last bin is hashed by Sum.
+		if len(h.bin) == BinSize {
+			h.leaves = append(h.leaves, binLeaf(h.bin))
+			h.bin = h.bin[:0]
+		}
+		n := min(BinSize-len(h.bin), len(data))
+		h.bin = append(h.bin, data[:n]...)
+		data = data[n:]
+	}
+	return written, nil
+}
+
+// Sum returns the dastree hash of the data written so far. Further data may be written afterwards.
+func (h *Hasher) Sum() bytes32 {
+	if len(h.leaves) == 0 {
+		return Hash(h.bin)
+	}
+	leaves := append(slices.Clip(h.leaves), binLeaf(h.bin))
+	return treeRoot(leaves, func(data []byte) bytes32 { return crypto.Keccak256Hash(data) })
+}
+
+func binLeaf(bin []byte) node {
+	inner := crypto.Keccak256Hash(bin)
+	// #nosec G115
+	return node{crypto.Keccak256Hash([]byte{LeafByte}, inner.Bytes()), uint32(len(bin))}
+}
+
 func Hash(preimage ...[]byte) bytes32 {
 	// Merkelizes without recording anything. All but the validator's DAS will call this
+	if hash, ok := hashSingleBin(preimage...); ok {
+		return hash
+	}
 	return RecordHash(func(bytes32, []byte, arbutil.PreimageType) {}, preimage...)
 }
 
+// hashSingleBin computes the root of a preimage fitting in a single bin, which is the flipped
+// hash of its only leaf, without concatenating the preimage or building the tree.
+func hashSingleBin(preimage ...[]byte) (bytes32, bool) {
+	length := 0
+	for _, slice := range preimage {
+		length += len(slice)
+		if length > BinSize {
+			return bytes32{}, false
+		}
+	}
+	return FlatHashToTreeHash(crypto.Keccak256Hash(preimage...)), true
+}
+
 func HashBytes(preimage ...[]byte) []byte {
 	return Hash(preimage...).Bytes()
 }
@@ -136,6 +201,37 @@ func ValidHash(hash bytes32, preimage []byte) bool {
 //  4. Only the committee can produce trees unwrapped by this function
 //  5. When the replay binary calls this, the oracle function must be infallible.
 func Content(root bytes32, oracle func(bytes32) ([]byte, error)) ([]byte, error) {
+	leaves, sized, err := treeLeaves(root, oracle, func(bytes32, []byte) {})
+	if err != nil {
+		return nil, err
+	}
+	if !sized {
+		return oracle(leaves[0].hash)
+	}
+
+	preimage := []byte{}
+	for i, leaf := range leaves { // TODO We can parallelize leaf fetching in future.
+		bin, err := oracle(leaf.hash)
+		if err != nil {
+			return nil, err
+		}
+		if len(bin) != int(leaf.size) {
+			return nil, fmt.Errorf("leaf %v has an incorrectly sized bin: %v vs %v", i, len(bin), leaf.size)
+		}
+		preimage = append(preimage, bin...)
+	}
+
+	// Check the hash matches. Given the size data this should never fail but we'll check anyway
+	if Hash(preimage) != root {
+		return nil, fmt.Errorf("preimage not canonically hashed")
+	}
+	return preimage, nil
+}
+
+// treeLeaves resolves the leaves under the root using the preimage oracle, checking the size-data
+// of each node and calling visit with the hash and preimage of each node and leaf it reveals. The
+// leaf of a degenerate single-leaf tree has no size-data, which is reported by returning unsized.
+func treeLeaves(root bytes32, oracle func(bytes32) ([]byte, error), visit func(bytes32, []byte)) ([]node, bool, error) {
 
 	unpeal := func(hash bytes32) (byte, []byte, error) {
 		data, err := oracle(hash)
@@ -150,6 +246,7 @@ func Content(root bytes32, oracle func(bytes32) ([]byte, error)) ([]byte, error)
 		if (kind == LeafByte && size != 33) || (kind == NodeByte && size != 69) {
 			return 0, nil, fmt.Errorf("invalid node for hash %v: %v", hash, data)
 		}
+		visit(hash, data)
 		return kind, data[1:], nil
 	}
 
@@ -157,15 +254,15 @@ func Content(root bytes32, oracle func(bytes32) ([]byte, error)) ([]byte, error)
 	total := uint32(0)
 	kind, upper, err := unpeal(start)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	switch kind {
 	case LeafByte:
-		return oracle(common.BytesToHash(upper))
+		return []node{{hash: common.BytesToHash(upper)}}, false, nil
 	case NodeByte:
 		total = binary.BigEndian.Uint32(upper[64:])
 	default:
-		return nil, fmt.Errorf("unexpected root preimage of kind %v: %v", kind, upper)
+		return nil, false, fmt.Errorf("unexpected root preimage of kind %v: %v", kind, upper)
 	}
 
 	leaves := []node{}
@@ -176,7 +273,7 @@ func Content(root bytes32, oracle func(bytes32) ([]byte, error)) ([]byte, error)
 		stack = stack[:len(stack)-1]
 		kind, data, err := unpeal(place.hash)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		switch kind {
@@ -193,7 +290,7 @@ func Content(root bytes32, oracle func(bytes32) ([]byte, error)) ([]byte, error)
 			halfPower := uint32(power / 2)
 
 			if place.size != count {
-				return nil, fmt.Errorf("invalid size data: %v vs %v for %v", count, place.size, data)
+				return nil, false, fmt.Errorf("invalid size data: %v vs %v for %v", count, place.size, data)
 			}
 
 			prior := node{
@@ -208,25 +305,52 @@ func Content(root bytes32, oracle func(bytes32) ([]byte, error)) ([]byte, error)
 			// we want to expand leftward so we reverse their order
 			stack = append(stack, after, prior)
 		default:
-			return nil, fmt.Errorf("failed to resolve preimage %v %v", place.hash, data)
+			return nil, false, fmt.Errorf("failed to resolve preimage %v %v", place.hash, data)
 		}
 	}
+	return leaves, true, nil
+}
 
-	preimage := []byte{}
-	for i, leaf := range leaves { // TODO We can parallelize leaf fetching in future.
-		bin, err := oracle(leaf.hash)
-		if err != nil {
-			return nil, err
+// Proof collects the preimages of the nodes and leaves of the dastree under the root, without the
+// bins of data they commit to. Together they prove the hashes of the bins, so that a verifier can
+// check parts of the data against the root with VerifyProof without having all of it.
+func Proof(root bytes32, oracle func(bytes32) ([]byte, error)) ([][]byte, error) {
+	var proof [][]byte
+	seen := make(map[bytes32]struct{})
+	_, _, err := treeLeaves(root, oracle, func(hash bytes32, preimage []byte) {
+		if _, ok := seen[hash]; !ok {
+			seen[hash] = struct{}{}
+			proof = append(proof, preimage)
 		}
-		if len(bin) != int(leaf.size) {
-			return nil, fmt.Errorf("leaf %v has an incorrectly sized bin: %v vs %v", i, len(bin), leaf.size)
-		}
-		preimage = append(preimage, bin...)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return proof, nil
+}
 
-	// Check the hash matches. Given the size data this should never fail but we'll check anyway
-	if Hash(preimage) != root {
-		return nil, fmt.Errorf("preimage not canonically hashed")
+// VerifyProof checks that the proof produced by Proof resolves the root, returning the hashes of the
+// bins of data under it in order. Like Content, it accepts degenerate single-leaf trees, whose only
+// bin may be larger than BinSize.
+func VerifyProof(root bytes32, proof [][]byte) ([]bytes32, error) {
+	nodes := make(map[bytes32][]byte, len(proof))
+	for _, preimage := range proof {
+		nodes[crypto.Keccak256Hash(preimage)] = preimage
 	}
-	return preimage, nil
+	oracle := func(hash bytes32) ([]byte, error) {
+		preimage, ok := nodes[hash]
+		if !ok {
+			return nil, fmt.Errorf("proof is missing the preimage of %v", hash)
+		}
+		return preimage, nil
+	}
+	leaves, _, err := treeLeaves(root, oracle, func(bytes32, []byte) {})
+	if err != nil {
+		return nil, err
+	}
+	bins := make([]bytes32, len(leaves))
+	for i, leaf := range leaves {
+		bins[i] = leaf.hash
+	}
+	return bins, nil
 }
@@ -63,6 +63,49 @@ func TestDASTree(t *testing.T) {
 	}
 }
 
+func TestHashSingleBin(t *testing.T) {
+	noRecord := func(bytes32, []byte, arbutil.PreimageType) {}
+	sizes := []int{0, 1, 2, 31, 32, 33, 1000}
+	for size := BinSize - 2; size <= BinSize+2; size++ {
+		sizes = append(sizes, size)
+	}
+	for i := 0; i < 16; i++ {
+		sizes = append(sizes, rand.Intn(BinSize+1))
+	}
+	for _, size := range sizes {
+		preimage := make([]byte, size)
+		_, _ = rand.Read(preimage)
+		expected := RecordHash(noRecord, preimage)
+		if hash := Hash(preimage); hash != expected {
+			Fail(t, "fast path hash of size", size, "is", hash, "expected", expected)
+		}
+		split := rand.Intn(size + 1)
+		if hash := Hash(preimage[:split], preimage[split:]); hash != expected {
+			Fail(t, "fast path hash of size", size, "split at", split, "is", hash, "expected", expected)
+		}
+		_, ok := hashSingleBin(preimage)
+		if ok != (size <= BinSize) {
+			Fail(t, "fast path taken", ok, "for size", size)
+		}
+	}
+}
+
+func BenchmarkHashSmallPayload(b *testing.B) {
+	preimage := make([]byte, 4096)
+	_, _ = rand.Read(preimage)
+	b.Run("fast", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Hash(preimage)
+		}
+	})
+	b.Run("tree", func(b *testing.B) {
+		noRecord := func(bytes32, []byte, arbutil.PreimageType) {}
+		for i := 0; i < b.N; i++ {
+			RecordHash(noRecord, preimage)
+		}
+	})
+}
+
 func Require(t *testing.T, err error, printables ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, printables...)
@@ -72,3 +115,24 @@ func Fail(t *testing.T, printables ...interface{}) {
 	t.Helper()
 	testhelpers.FailImpl(t, printables...)
 }
+
+func TestHasher(t *testing.T) {
+	sizes := []int{0, 1, BinSize - 1, BinSize, BinSize + 1, 2 * BinSize, 3*BinSize + 7, 5*BinSize - 3}
+	for _, size := range sizes {
+		data := make([]byte, size)
+		// #nosec G404
+		_, _ = rand.Read(data)
+		hasher := NewHasher()
+		// Write in uneven pieces so that writes straddle bins.
+		for rest := data; len(rest) > 0; {
+			n := min(len(rest), 1+rand.Intn(BinSize/3))
+			if _, err := hasher.Write(rest[:n]); err != nil {
+				Fail(t, err)
+			}
+			rest = rest[n:]
+		}
+		if hasher.Sum() != Hash(data) {
+			Fail(t, "hasher disagrees with Hash for size", size)
+		}
+	}
+}
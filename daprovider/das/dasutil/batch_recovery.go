@@ -0,0 +1,150 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/daprovider"
+)
+
+// signatureBatchSize bounds the number of cert signatures checked together by BatchRecover. A
+// batch containing an invalid signature is checked again one signature at a time, so larger
+// batches are faster when all signatures are valid but slower when one isn't.
+const signatureBatchSize = 64
+
+// batchRecoveryConcurrency bounds the number of payloads BatchRecover fetches at once.
+const batchRecoveryConcurrency = 16
+
+// BatchRecoveryRequest is a sequencer message to recover the DAS payload of with BatchRecover.
+type BatchRecoveryRequest struct {
+	BatchNum     uint64
+	SequencerMsg []byte
+}
+
+// BatchRecoveryResult holds what RecoverPayloadFromDasBatchWithOptions returns for a request.
+type BatchRecoveryResult struct {
+	Payload   []byte
+	Preimages daprovider.PreimagesMap
+	Err       error
+}
+
+// BatchRecover recovers the payloads of many sequencer messages, as when syncing, returning a result
+// per request in the same order. Each result is the same as recovering the message on its own with
+// RecoverPayloadFromDasBatchWithOptions, with each recovery recording into its own preimages map,
+// but keysets are fetched once for all the certs referencing them, the signatures of certs sharing a
+// keyset are verified together, and payloads are fetched concurrently.
+func BatchRecover(
+	ctx context.Context,
+	requests []BatchRecoveryRequest,
+	dasReader DASReader,
+	keysetFetcher DASKeysetFetcher,
+	validateSeqMsg bool,
+	opts RecoveryOptions,
+) []BatchRecoveryResult {
+	results := make([]BatchRecoveryResult, len(requests))
+	recoveries := make([]*dasRecovery, len(requests))
+	var keysetHashes []common.Hash
+	byKeyset := make(map[common.Hash][]int)
+	for i, request := range requests {
+		r, err := startRecovery(request.BatchNum, request.SequencerMsg, dasReader, nil, opts)
+		if r == nil {
+			results[i].Err = err
+			continue
+		}
+		recoveries[i] = r
+		keysetHash := common.Hash(r.cert.KeysetHash)
+		if _, ok := byKeyset[keysetHash]; !ok {
+			keysetHashes = append(keysetHashes, keysetHash)
+		}
+		byKeyset[keysetHash] = append(byKeyset[keysetHash], i)
+	}
+
+	var verified []int
+	for _, keysetHash := range keysetHashes {
+		indices := byKeyset[keysetHash]
+		keysetPreimage, err := recoveries[indices[0]].fetchKeyset(ctx, keysetFetcher, opts)
+		var withKeyset []int
+		for _, i := range indices {
+			if err != nil {
+				results[i].Err = err
+			} else if checkErr := recoveries[i].checkKeysetValidity(ctx, opts); checkErr != nil {
+				results[i].Err = checkErr
+			} else if setErr := recoveries[i].setKeyset(keysetPreimage, validateSeqMsg); setErr != nil {
+				results[i].Err = setErr
+			} else {
+				withKeyset = append(withKeyset, i)
+			}
+		}
+		for start := 0; start < len(withKeyset); start += signatureBatchSize {
+			batch := withKeyset[start:min(start+signatureBatchSize, len(withKeyset))]
+			verified = append(verified, verifyRecoverySignatures(recoveries, batch)...)
+		}
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, batchRecoveryConcurrency)
+	for _, i := range verified {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			payload, preimages, err := recoveries[i].finish(ctx, opts)
+			results[i] = BatchRecoveryResult{Payload: payload, Preimages: preimages, Err: err}
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// verifyRecoverySignatures verifies the cert signatures of the recoveries with the given indices,
+// which share a keyset, returning the indices of those with a valid signature.
+func verifyRecoverySignatures(recoveries []*dasRecovery, indices []int) []int {
+	var sigs []blsSignatures.Signature
+	var messages [][]byte
+	var pubKeys []blsSignatures.PublicKey
+	var candidates []int
+	for _, i := range indices {
+		r := recoveries[i]
+		pubKey, err := r.keyset.signersPublicKey(r.cert.SignersMask, r.rejectsExtraSigners())
+		if err != nil {
+			r.logBadSignature(err)
+			continue
+		}
+		signableFields, err := r.cert.SerializeSignableFields()
+		if err != nil {
+			r.logBadSignature(err)
+			continue
+		}
+		sigs = append(sigs, r.cert.Sig)
+		messages = append(messages, signableFields)
+		pubKeys = append(pubKeys, pubKey)
+		candidates = append(candidates, i)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	valid, err := blsSignatures.VerifySignaturesBatch(sigs, messages, pubKeys)
+	if err == nil && valid {
+		return candidates
+	}
+	// Find the invalid signatures, and the errors to report for them.
+	var verified []int
+	for _, i := range candidates {
+		r := recoveries[i]
+		if err := r.verifyCertSignature(); err != nil {
+			r.logBadSignature(err)
+			continue
+		}
+		verified = append(verified, i)
+	}
+	return verified
+}
@@ -0,0 +1,110 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+)
+
+// addSignedBatch stores the payload in the reader of r and returns a sequencer message with a cert
+// for it, signed by the keyset of r.
+func (r *testRecovery) addSignedBatch(t testing.TB, payload []byte, version uint8, maxTimestamp uint64) []byte {
+	t.Helper()
+	cert := r.cert.Clone()
+	cert.Version = version
+	cert.DataHash = CertDataHash(payload, version)
+	r.reader.data[cert.DataHash] = payload
+	cert.Sig = signTestCert(t, r.privKeys[0], cert)
+	return makeSequencerMessage(t, maxTimestamp, cert)
+}
+
+func TestBatchRecoverMatchesSingleRecovery(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("first batch"), 1)
+	other := newTestRecovery(t, []byte("batch of another keyset"), 1)
+	// The second keyset is known too, but the third one isn't.
+	r.fetcher.keysets[other.cert.KeysetHash] = other.keysetBytes
+	for hash, data := range other.reader.data {
+		r.reader.data[hash] = data
+	}
+	unknownKeyset := newTestRecovery(t, []byte("batch of an unknown keyset"), 1)
+
+	foreignSig := r.cert.Clone()
+	_, foreignKey, err := blsSignatures.GenerateKeys()
+	Require(t, err)
+	foreignSig.Sig = signTestCert(t, foreignKey, foreignSig)
+	noSigners := r.cert.Clone()
+	noSigners.SignersMask = 0
+	missingData := r.cert.Clone()
+	missingData.DataHash = CertDataHash([]byte("never stored"), 1)
+	missingData.Sig = signTestCert(t, r.privKeys[0], missingData)
+
+	requests := []BatchRecoveryRequest{
+		{BatchNum: 1, SequencerMsg: r.msg},
+		{BatchNum: 2, SequencerMsg: r.addSignedBatch(t, []byte("version 0 batch"), 0, 0)},
+		{BatchNum: 3, SequencerMsg: other.msg},
+		{BatchNum: 4, SequencerMsg: makeSequencerMessage(t, 0, foreignSig)},
+		{BatchNum: 5, SequencerMsg: unknownKeyset.msg},
+		{BatchNum: 6, SequencerMsg: makeSequencerMessage(t, 0, noSigners)},
+		{BatchNum: 7, SequencerMsg: r.addSignedBatch(t, []byte("expiring batch"), 1, r.cert.Timeout)},
+		{BatchNum: 8, SequencerMsg: makeSequencerMessage(t, 0, missingData)},
+		{BatchNum: 9, SequencerMsg: r.msg[:sequencerMsgHeaderLen]},
+		{BatchNum: 10, SequencerMsg: r.addSignedBatch(t, []byte("last batch"), 1, 0)},
+	}
+	results := BatchRecover(ctx, requests, r.reader, r.fetcher, true, RecoveryOptions{})
+	if len(results) != len(requests) {
+		Fail(t, "expected a result per request, got", len(results))
+	}
+	recovered := 0
+	for i, request := range requests {
+		payload, preimages, err := RecoverPayloadFromDasBatch(ctx, request.BatchNum, request.SequencerMsg, r.reader, r.fetcher, nil, true)
+		result := results[i]
+		if !bytes.Equal(result.Payload, payload) || !reflect.DeepEqual(result.Preimages, preimages) || fmt.Sprint(result.Err) != fmt.Sprint(err) {
+			Fail(t, "batch", request.BatchNum, "recovered differently in a batch:", result, "alone:", payload, preimages, err)
+		}
+		if payload != nil {
+			recovered++
+		}
+	}
+	if recovered != 4 {
+		Fail(t, "expected four batches to be recovered, got", recovered)
+	}
+}
+
+func BenchmarkBatchRecover(b *testing.B) {
+	ctx := context.Background()
+	r := newTestRecovery(b, []byte("batch 0"), 1)
+	requests := []BatchRecoveryRequest{{BatchNum: 0, SequencerMsg: r.msg}}
+	for i := 1; i < 256; i++ {
+		msg := r.addSignedBatch(b, []byte(fmt.Sprintf("batch %d", i)), 1, 0)
+		// #nosec G115
+		requests = append(requests, BatchRecoveryRequest{BatchNum: uint64(i), SequencerMsg: msg})
+	}
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, result := range BatchRecover(ctx, requests, r.reader, r.fetcher, true, RecoveryOptions{}) {
+				if result.Payload == nil {
+					b.Fatal("failed to recover batch", result.Err)
+				}
+			}
+		}
+	})
+	b.Run("individual", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, request := range requests {
+				payload, _, err := RecoverPayloadFromDasBatch(ctx, request.BatchNum, request.SequencerMsg, r.reader, r.fetcher, nil, true)
+				if payload == nil {
+					b.Fatal("failed to recover batch", err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BundleFormatVersion is the version byte leading serialized bundles.
+const BundleFormatVersion uint8 = 1
+
+var ErrInvalidBundle = errors.New("invalid DAS bundle")
+
+// Bundle holds a DAS certificate together with the preimages of its keyset and data, so that the
+// cert can be verified end to end without access to a DAS or the chain.
+type Bundle struct {
+	Cert   *DataAvailabilityCertificate
+	Keyset []byte
+	Data   []byte
+}
+
+// SerializeBundle encodes the bundle as its format version followed by the serialized cert, the
+// keyset and the data, each prefixed by its big-endian uint64 length.
+func SerializeBundle(bundle *Bundle) ([]byte, error) {
+	cert, err := Serialize(bundle.Cert)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, 1+3*8+len(cert)+len(bundle.Keyset)+len(bundle.Data))
+	buf = append(buf, BundleFormatVersion)
+	for _, component := range [][]byte{cert, bundle.Keyset, bundle.Data} {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(component)))
+		buf = append(buf, component...)
+	}
+	return buf, nil
+}
+
+// DeserializeBundle decodes a bundle produced by SerializeBundle. The bundle isn't verified.
+func DeserializeBundle(data []byte) (*Bundle, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidBundle)
+	}
+	if data[0] != BundleFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidBundle, data[0])
+	}
+	rest := data[1:]
+	var components [3][]byte
+	for i := range components {
+		if len(rest) < 8 {
+			return nil, fmt.Errorf("%w: truncated length of component %d", ErrInvalidBundle, i)
+		}
+		length := binary.BigEndian.Uint64(rest)
+		rest = rest[8:]
+		if length > uint64(len(rest)) {
+			return nil, fmt.Errorf("%w: component %d has length %d but only %d bytes remain", ErrInvalidBundle, i, length, len(rest))
+		}
+		components[i] = rest[:length]
+		rest = rest[length:]
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidBundle, len(rest))
+	}
+	cert, err := DeserializeDASCertFrom(bytes.NewReader(components[0]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	if reserialized, err := Serialize(cert); err != nil || !bytes.Equal(reserialized, components[0]) {
+		return nil, fmt.Errorf("%w: trailing bytes after cert", ErrInvalidBundle)
+	}
+	return &Bundle{
+		Cert:   cert,
+		Keyset: bytes.Clone(components[1]),
+		Data:   bytes.Clone(components[2]),
+	}, nil
+}
+
+// VerifyBundle checks that the keyset matches the cert's keyset hash, that the cert was signed by
+// enough members of that keyset, and that the data matches the cert's data hash. Certs expiring too
+// soon aren't rejected, as there's no sequencer message to compare the timeout against. Bundles aren't
+// tied to an ArbOS version, so certs of every version recovered at some ArbOS version are accepted.
+func VerifyBundle(bundle *Bundle) error {
+	cert := bundle.Cert
+	if err := ValidateCertVersion(cert.Version, math.MaxUint64); err != nil {
+		return err
+	}
+	if !ValidKeysetHash(cert.Version, cert.KeysetHash, bundle.Keyset) {
+		return fmt.Errorf("%w: keyset does not match cert keyset hash %v", ErrHashMismatch, common.Hash(cert.KeysetHash))
+	}
+	keyset, err := DeserializeKeysetBytes(bundle.Keyset, false)
+	if err != nil {
+		return fmt.Errorf("couldn't deserialize bundled keyset: %w", err)
+	}
+	if err := keyset.verifyCertSignature(cert, true); err != nil {
+		return fmt.Errorf("bad signature on bundled cert: %w", err)
+	}
+	if CertDataHash(bundle.Data, cert.Version) != cert.DataHash {
+		return fmt.Errorf("%w: data does not match cert data hash %v", ErrHashMismatch, common.Hash(cert.DataHash))
+	}
+	return nil
+}
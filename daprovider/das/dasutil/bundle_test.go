@@ -0,0 +1,93 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+)
+
+func TestBundleFromRecovery(t *testing.T) {
+	ctx := context.Background()
+	for _, version := range []uint8{0, 1} {
+		r := newTestRecovery(t, []byte("some batch data"), version)
+		payload, _, err := RecoverPayloadFromDasBatch(ctx, 1, r.msg, r.reader, r.fetcher, nil, true)
+		Require(t, err)
+		keysetBytes, err := r.fetcher.GetKeysetByHash(ctx, r.cert.KeysetHash)
+		Require(t, err)
+
+		serialized, err := SerializeBundle(&Bundle{Cert: r.cert, Keyset: keysetBytes, Data: payload})
+		Require(t, err)
+		bundle, err := DeserializeBundle(serialized)
+		Require(t, err)
+		reserialized, err := SerializeBundle(bundle)
+		Require(t, err)
+		if !bytes.Equal(reserialized, serialized) {
+			Fail(t, "version", version, "bundle doesn't round trip")
+		}
+		Require(t, VerifyBundle(bundle))
+
+		tampered := *bundle
+		tampered.Data = append([]byte{}, bundle.Data...)
+		tampered.Data[0] ^= 1
+		if err := VerifyBundle(&tampered); !errors.Is(err, ErrHashMismatch) {
+			Fail(t, "version", version, "expected tampered data to be rejected, got", err)
+		}
+
+		tampered = *bundle
+		tampered.Keyset = append([]byte{}, bundle.Keyset...)
+		tampered.Keyset[len(tampered.Keyset)-1] ^= 1
+		if err := VerifyBundle(&tampered); !errors.Is(err, ErrHashMismatch) {
+			Fail(t, "version", version, "expected tampered keyset to be rejected, got", err)
+		}
+
+		tampered = *bundle
+		tampered.Cert = bundle.Cert.Clone()
+		tampered.Cert.Timeout++
+		if err := VerifyBundle(&tampered); err == nil {
+			Fail(t, "version", version, "expected cert with tampered fields to be rejected")
+		}
+
+		// A signature by a key outside the keyset is rejected even over the correct fields.
+		_, otherKey, err := blsSignatures.GenerateKeys()
+		Require(t, err)
+		tampered = *bundle
+		tampered.Cert = bundle.Cert.Clone()
+		tampered.Cert.Sig = signTestCert(t, otherKey, tampered.Cert)
+		if err := VerifyBundle(&tampered); err == nil {
+			Fail(t, "version", version, "expected cert with foreign signature to be rejected")
+		}
+
+		// Tampering with the cert's data hash alone breaks its signature.
+		tampered = *bundle
+		tampered.Cert = bundle.Cert.Clone()
+		tampered.Data = append(bytes.Clone(payload), 0)
+		tampered.Cert.DataHash = CertDataHash(tampered.Data, version)
+		if err := VerifyBundle(&tampered); err == nil {
+			Fail(t, "version", version, "expected cert with swapped data hash to be rejected")
+		}
+	}
+}
+
+func TestDeserializeBundleMalformed(t *testing.T) {
+	r := newTestRecovery(t, []byte("some batch data"), 1)
+	serialized, err := SerializeBundle(&Bundle{Cert: r.cert, Keyset: r.keysetBytes, Data: r.payload})
+	Require(t, err)
+
+	cases := map[string][]byte{
+		"empty":           {},
+		"unknown version": append([]byte{BundleFormatVersion + 1}, serialized[1:]...),
+		"truncated":       serialized[:len(serialized)-1],
+		"trailing bytes":  append(append([]byte{}, serialized...), 0),
+	}
+	for name, data := range cases {
+		if _, err := DeserializeBundle(data); !errors.Is(err, ErrInvalidBundle) {
+			Fail(t, name, "expected ErrInvalidBundle, got", err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CertDiff reports which fields differ between two DAS certificates.
+type CertDiff struct {
+	KeysetHash  bool
+	DataHash    bool
+	Timeout     bool
+	SignersMask bool
+	Version     bool
+	DataHashes  bool
+}
+
+// Fields returns the names of the differing fields.
+func (d CertDiff) Fields() []string {
+	var fields []string
+	if d.KeysetHash {
+		fields = append(fields, "KeysetHash")
+	}
+	if d.DataHash {
+		fields = append(fields, "DataHash")
+	}
+	if d.Timeout {
+		fields = append(fields, "Timeout")
+	}
+	if d.SignersMask {
+		fields = append(fields, "SignersMask")
+	}
+	if d.Version {
+		fields = append(fields, "Version")
+	}
+	if d.DataHashes {
+		fields = append(fields, "DataHashes")
+	}
+	return fields
+}
+
+func (d CertDiff) Empty() bool {
+	return len(d.Fields()) == 0
+}
+
+// DiffCerts deserializes the DAS certificates of two sequencer messages and reports which of their fields differ.
+func DiffCerts(msgA, msgB []byte) (CertDiff, error) {
+	certA, _, err := DeserializeDASCertFromMessage(msgA)
+	if err != nil {
+		return CertDiff{}, fmt.Errorf("failed to deserialize first cert: %w", err)
+	}
+	certB, _, err := DeserializeDASCertFromMessage(msgB)
+	if err != nil {
+		return CertDiff{}, fmt.Errorf("failed to deserialize second cert: %w", err)
+	}
+	return CertDiff{
+		KeysetHash:  certA.KeysetHash != certB.KeysetHash,
+		DataHash:    certA.DataHash != certB.DataHash,
+		Timeout:     certA.Timeout != certB.Timeout,
+		SignersMask: certA.SignersMask != certB.SignersMask,
+		Version:     certA.Version != certB.Version,
+		DataHashes:  !slices.Equal(certA.DataHashes, certB.DataHashes),
+	}, nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"testing"
+)
+
+func TestDiffCerts(t *testing.T) {
+	certA := makeTestCert(t, []byte("payload"), 12345)
+	certB := *certA
+	certB.SignersMask = 3
+
+	diff, err := DiffCerts(makeSequencerMessage(t, 1, certA), makeSequencerMessage(t, 1, certA))
+	Require(t, err)
+	if !diff.Empty() {
+		Fail(t, "identical certs reported as different", diff.Fields())
+	}
+
+	diff, err = DiffCerts(makeSequencerMessage(t, 1, certA), makeSequencerMessage(t, 1, &certB))
+	Require(t, err)
+	fields := diff.Fields()
+	if len(fields) != 1 || fields[0] != "SignersMask" || !diff.SignersMask {
+		Fail(t, "expected only SignersMask to differ", fields)
+	}
+
+	if _, err := DiffCerts(makeSequencerMessage(t, 1, certA), []byte{}); err == nil {
+		Fail(t, "expected error for malformed message")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+)
+
+// TestCertSerializationVectors pins the wire format of certs to fixed byte vectors, which other
+// implementations can check their encoding against. The keyset hash is the bytes 0x01 to 0x20 and
+// the data hash the bytes 0x21 to 0x40. Signatures are the uncompressed G1 generator, which is a
+// valid point but not a signature of anything, and the point at infinity, encoded as all zeros.
+func TestCertSerializationVectors(t *testing.T) {
+	var keysetHash, dataHash [32]byte
+	for i := range keysetHash {
+		keysetHash[i] = byte(i + 0x01)
+		dataHash[i] = byte(i + 0x21)
+	}
+	generatorSigBytes, err := hex.DecodeString("17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1")
+	Require(t, err)
+	generatorSig, err := blsSignatures.SignatureFromBytes(generatorSigBytes)
+	Require(t, err)
+	zeroSig, err := blsSignatures.SignatureFromBytes(make([]byte, blsSignatures.SignatureLength))
+	Require(t, err)
+
+	vectors := []struct {
+		name        string
+		version     uint8
+		timeout     uint64
+		signersMask uint64
+		sig         blsSignatures.Signature
+		expected    string
+	}{
+		{
+			name:        "version 0 min timeout",
+			version:     0,
+			timeout:     0,
+			signersMask: 1,
+			sig:         generatorSig,
+			expected:    "800102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f400000000000000000000000000000000117f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1",
+		},
+		{
+			name:        "version 0 max timeout",
+			version:     0,
+			timeout:     math.MaxUint64,
+			signersMask: 5,
+			sig:         generatorSig,
+			expected:    "800102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f40ffffffffffffffff000000000000000517f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1",
+		},
+		{
+			name:        "version 0 all signers zero signature",
+			version:     0,
+			timeout:     1700000000,
+			signersMask: math.MaxUint64,
+			sig:         zeroSig,
+			expected:    "800102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f40000000006553f100ffffffffffffffff000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:        "version 1 min timeout",
+			version:     1,
+			timeout:     0,
+			signersMask: 1,
+			sig:         generatorSig,
+			expected:    "880102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f40000000000000000001000000000000000117f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1",
+		},
+		{
+			name:        "version 1 max timeout all signers",
+			version:     1,
+			timeout:     math.MaxUint64,
+			signersMask: math.MaxUint64,
+			sig:         generatorSig,
+			expected:    "880102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f40ffffffffffffffff01ffffffffffffffff17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1",
+		},
+		{
+			name:        "version 1 zero signature",
+			version:     1,
+			timeout:     1700000000,
+			signersMask: 3,
+			sig:         zeroSig,
+			expected:    "880102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f40000000006553f100010000000000000003000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		},
+	}
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			cert := &DataAvailabilityCertificate{
+				KeysetHash:  keysetHash,
+				DataHash:    dataHash,
+				Timeout:     v.timeout,
+				SignersMask: v.signersMask,
+				Sig:         v.sig,
+				Version:     v.version,
+			}
+			serialized, err := Serialize(cert)
+			Require(t, err)
+			if hex.EncodeToString(serialized) != v.expected {
+				Fail(t, "serialized cert", hex.EncodeToString(serialized), "doesn't match vector", v.expected)
+			}
+
+			expected, err := hex.DecodeString(v.expected)
+			Require(t, err)
+			deserialized, err := DeserializeDASCertFrom(bytes.NewReader(expected))
+			Require(t, err)
+			if deserialized.KeysetHash != keysetHash || deserialized.DataHash != dataHash || deserialized.Timeout != v.timeout ||
+				deserialized.SignersMask != v.signersMask || deserialized.Version != v.version {
+				Fail(t, "vector deserialized to wrong cert", deserialized)
+			}
+			if !bytes.Equal(blsSignatures.SignatureToBytes(deserialized.Sig), blsSignatures.SignatureToBytes(v.sig)) {
+				Fail(t, "vector deserialized to wrong signature")
+			}
+		})
+	}
+}
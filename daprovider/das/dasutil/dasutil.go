@@ -10,16 +10,24 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/bits"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 
 	"github.com/offchainlabs/nitro/arbos/util"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/blsSignatures"
 	"github.com/offchainlabs/nitro/daprovider"
 	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/util/arbmath"
 )
 
 type DASReader interface {
@@ -33,6 +41,20 @@ type DASWriter interface {
 	fmt.Stringer
 }
 
+// VersionedDASWriter is implemented by DASWriters that can produce certs of a version other than
+// DefaultCertVersion.
+type VersionedDASWriter interface {
+	// StoreWithVersion is Store, producing a cert of the given version.
+	StoreWithVersion(ctx context.Context, message []byte, timeout uint64, version uint8) (*DataAvailabilityCertificate, error)
+}
+
+// SizeLimitedDASReader is implemented by DASReaders that can stop reading a value as soon as it
+// exceeds a size limit, rather than buffering all of it first.
+type SizeLimitedDASReader interface {
+	// GetByHashWithSizeLimit is GetByHash, failing with ErrPayloadTooLarge for values larger than maxSize.
+	GetByHashWithSizeLimit(ctx context.Context, hash common.Hash, maxSize uint64) ([]byte, error)
+}
+
 type DASKeysetFetcher interface {
 	GetKeysetByHash(context.Context, common.Hash) ([]byte, error)
 }
@@ -40,15 +62,33 @@ type DASKeysetFetcher interface {
 // NewReaderForDAS is generally meant to be only used by nitro.
 // DA Providers should implement methods in the Reader interface independently
 func NewReaderForDAS(dasReader DASReader, keysetFetcher DASKeysetFetcher) *readerForDAS {
+	return NewReaderForDASWithOptions(dasReader, keysetFetcher, RecoveryOptions{})
+}
+
+// NewReaderForDASWithOptions is NewReaderForDAS, recovering payloads with the given options.
+func NewReaderForDASWithOptions(dasReader DASReader, keysetFetcher DASKeysetFetcher, opts RecoveryOptions) *readerForDAS {
 	return &readerForDAS{
 		dasReader:     dasReader,
 		keysetFetcher: keysetFetcher,
+		opts:          opts,
+		now:           time.Now,
 	}
 }
 
+// expirationPolicyCacheDuration is how long readerForDAS reuses the expiration policy of its DAS reader.
+const expirationPolicyCacheDuration = time.Minute
+
 type readerForDAS struct {
 	dasReader     DASReader
 	keysetFetcher DASKeysetFetcher
+	opts          RecoveryOptions
+
+	validatedPayloads *lru.SizeConstrainedCache[common.Hash, []byte]
+
+	policyMutex     sync.Mutex
+	policy          ExpirationPolicy
+	policyFetchedAt time.Time
+	now             func() time.Time
 }
 
 func (d *readerForDAS) IsValidHeaderByte(ctx context.Context, headerByte byte) bool {
@@ -63,7 +103,37 @@ func (d *readerForDAS) RecoverPayloadFromBatch(
 	preimages daprovider.PreimagesMap,
 	validateSeqMsg bool,
 ) ([]byte, daprovider.PreimagesMap, error) {
-	return RecoverPayloadFromDasBatch(ctx, batchNum, sequencerMsg, d.dasReader, d.keysetFetcher, preimages, validateSeqMsg)
+	opts := d.opts
+	opts.validatedPayloads = d.validatedPayloads
+	return RecoverPayloadFromDasBatchWithOptions(ctx, batchNum, sequencerMsg, d.dasReader, d.keysetFetcher, preimages, validateSeqMsg, opts)
+}
+
+// EnableValidatedPayloadCache makes the reader keep up to maxBytes of the payloads it recovered,
+// keyed by the data hash of their certs, so that recovering a batch again, as when handling a
+// reorg, returns its payload without fetching it from the DAS reader. The cert is still checked
+// and the preimages still recorded as usual. Payloads are only cached once they've been checked
+// against their data hash. Payloads of multi-chunk certs aren't cached, as their chunks, which
+// are recorded as preimages too, aren't determined by the data hash.
+func (d *readerForDAS) EnableValidatedPayloadCache(maxBytes uint64) {
+	d.validatedPayloads = lru.NewSizeConstrainedCache[common.Hash, []byte](maxBytes)
+}
+
+// ExpirationPolicy returns the expiration policy of the DAS reader, so the node can decide how
+// aggressively to pin data. The policy is reused for expirationPolicyCacheDuration after it's read,
+// but failures to read it aren't.
+func (d *readerForDAS) ExpirationPolicy(ctx context.Context) (ExpirationPolicy, error) {
+	d.policyMutex.Lock()
+	defer d.policyMutex.Unlock()
+	if !d.policyFetchedAt.IsZero() && d.now().Before(d.policyFetchedAt.Add(expirationPolicyCacheDuration)) {
+		return d.policy, nil
+	}
+	policy, err := d.dasReader.ExpirationPolicy(ctx)
+	if err != nil {
+		return -1, err
+	}
+	d.policy = policy
+	d.policyFetchedAt = d.now()
+	return policy, nil
 }
 
 // NewWriterForDAS is generally meant to be only used by nitro.
@@ -72,32 +142,401 @@ func NewWriterForDAS(dasWriter DASWriter) *writerForDAS {
 	return &writerForDAS{dasWriter: dasWriter}
 }
 
+// NewWriterForDASWithFallbackCoalescing returns a writer that buffers messages falling back to
+// on-chain storage, returning ErrFallbackBuffered instead of the message, so that consecutive
+// fallbacks can be posted together. Callers must post the groups returned by FlushFallbacks
+// before anything stored afterwards to preserve batch ordering.
+func NewWriterForDASWithFallbackCoalescing(dasWriter DASWriter, config FallbackCoalescingConfig) *writerForDAS {
+	return &writerForDAS{
+		dasWriter: dasWriter,
+		coalescer: newFallbackCoalescer(config),
+	}
+}
+
 type writerForDAS struct {
-	dasWriter DASWriter
+	dasWriter    DASWriter
+	coalescer    *fallbackCoalescer
+	certVersion  *uint8
+	verifyReader DASReader
+}
+
+// PinCertVersion makes the writer produce certs of the given version instead of the DAS writer's
+// default, so that operators can control the output format during cert format migrations.
+func (d *writerForDAS) PinCertVersion(version uint8) error {
+	if err := ValidateWritableCertVersion(version); err != nil {
+		return err
+	}
+	if _, ok := d.dasWriter.(VersionedDASWriter); !ok && version != DefaultCertVersion {
+		return fmt.Errorf("%w: %v can only produce version %d certs", ErrUnsupportedCertVersion, d.dasWriter, DefaultCertVersion)
+	}
+	d.certVersion = &version
+	return nil
+}
+
+// VerifyStoresWith makes the writer read back the data of each cert it produces from reader, failing
+// the store with ErrStoreNotRetrievable unless it's the stored message, so that write-path bugs
+// dropping data behind a valid cert are caught before the cert is posted.
+func (d *writerForDAS) VerifyStoresWith(reader DASReader) {
+	d.verifyReader = reader
+}
+
+// verifyStored reads the data of cert back from the verification reader, as recovery would, and
+// checks it's the message.
+func (d *writerForDAS) verifyStored(ctx context.Context, cert *DataAvailabilityCertificate, message []byte) error {
+	reader := ReaderForCert(d.verifyReader, cert)
+	var stored []byte
+	switch cert.Version {
+	case 0:
+		hash := common.Hash(cert.DataHash)
+		data, err := reader.GetByHash(ctx, dastree.FlatHashToTreeHash(hash))
+		if err != nil {
+			data, err = reader.GetByHash(ctx, hash)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: data %v: %w", ErrStoreNotRetrievable, hash, err)
+		}
+		stored = data
+	case MultiChunkCertVersion:
+		for i, chunkHash := range cert.DataHashes {
+			chunk, err := reader.GetByHash(ctx, chunkHash)
+			if err != nil {
+				return fmt.Errorf("%w: chunk %d of %d, %v: %w", ErrStoreNotRetrievable, i, len(cert.DataHashes), common.Hash(chunkHash), err)
+			}
+			stored = append(stored, chunk...)
+		}
+	default:
+		data, err := reader.GetByHash(ctx, cert.DataHash)
+		if err != nil {
+			return fmt.Errorf("%w: data %v: %w", ErrStoreNotRetrievable, common.Hash(cert.DataHash), err)
+		}
+		stored = data
+	}
+	if !bytes.Equal(stored, message) {
+		return fmt.Errorf("%w: data %v read back differs from the stored message", ErrStoreNotRetrievable, common.Hash(cert.DataHash))
+	}
+	return nil
+}
+
+func (d *writerForDAS) storeCert(ctx context.Context, message []byte, timeout uint64) (*DataAvailabilityCertificate, error) {
+	if d.certVersion == nil {
+		return d.dasWriter.Store(ctx, message, timeout)
+	}
+	var cert *DataAvailabilityCertificate
+	var err error
+	if versionedWriter, ok := d.dasWriter.(VersionedDASWriter); ok {
+		cert, err = versionedWriter.StoreWithVersion(ctx, message, timeout, *d.certVersion)
+	} else {
+		cert, err = d.dasWriter.Store(ctx, message, timeout)
+	}
+	if err == nil && cert.Version != *d.certVersion {
+		return nil, fmt.Errorf("%v produced a version %d cert, expected version %d", d.dasWriter, cert.Version, *d.certVersion)
+	}
+	return cert, err
 }
 
 func (d *writerForDAS) Store(ctx context.Context, message []byte, timeout uint64, disableFallbackStoreDataOnChain bool) ([]byte, error) {
-	cert, err := d.dasWriter.Store(ctx, message, timeout)
+	serialized, _, err := d.StoreWithDataHash(ctx, message, timeout, disableFallbackStoreDataOnChain)
+	return serialized, err
+}
+
+// StoreWithDataHash is like Store, but also returns the dastree hash of the message, whether it
+// was stored in the DAS or is to be posted on chain, so callers don't need to re-hash it.
+func (d *writerForDAS) StoreWithDataHash(ctx context.Context, message []byte, timeout uint64, disableFallbackStoreDataOnChain bool) ([]byte, common.Hash, error) {
+	cert, err := d.storeCert(ctx, message, timeout)
 	if errors.Is(err, ErrBatchToDasFailed) {
 		if disableFallbackStoreDataOnChain {
-			return nil, errors.New("unable to batch to DAS and fallback storing data on chain is disabled")
+			return nil, common.Hash{}, errors.New("unable to batch to DAS and fallback storing data on chain is disabled")
+		}
+		if d.coalescer != nil {
+			log.Warn("Buffering message to store data on chain", "err", err)
+			d.coalescer.add(message)
+			return nil, dastree.Hash(message), ErrFallbackBuffered
 		}
 		log.Warn("Falling back to storing data on chain", "err", err)
-		return message, nil
+		return message, dastree.Hash(message), nil
 	} else if err != nil {
-		return nil, err
-	} else {
-		return Serialize(cert), nil
+		return nil, common.Hash{}, err
+	}
+	if d.verifyReader != nil {
+		if err := d.verifyStored(ctx, cert, message); err != nil {
+			log.Error("DAS store succeeded but its data couldn't be read back", "err", err)
+			return nil, common.Hash{}, err
+		}
+	}
+	dataHash := common.Hash(cert.DataHash)
+	if cert.Version == 0 {
+		// Version 0 certs commit to the flat keccak hash of the message rather than its dastree
+		// hash, so the latter is computed here.
+		dataHash = dastree.Hash(message)
+	}
+	serialized, err := Serialize(cert)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return serialized, dataHash, nil
+}
+
+// FlushFallbacks returns the buffered on-chain fallback messages, in the order they were stored,
+// once their group is complete or unconditionally if force is set. It returns nil if fallback
+// coalescing isn't enabled or no group is ready.
+func (d *writerForDAS) FlushFallbacks(force bool) [][]byte {
+	if d.coalescer == nil {
+		return nil
+	}
+	return d.coalescer.flush(force)
+}
+
+// PendingFallbacks returns the number of buffered on-chain fallback messages.
+func (d *writerForDAS) PendingFallbacks() int {
+	if d.coalescer == nil {
+		return 0
+	}
+	return d.coalescer.pendingCount()
+}
+
+// DASProviderName is the name the DAS provider is registered under in a daprovider.Registry.
+const DASProviderName = "das"
+
+// NewDASProviderFactory returns a daprovider.ProviderFactory constructing the DAS reader and writer.
+// The writer is omitted if dasWriter is nil.
+func NewDASProviderFactory(dasReader DASReader, keysetFetcher DASKeysetFetcher, dasWriter DASWriter) daprovider.ProviderFactory {
+	return func(ctx context.Context) (daprovider.Reader, daprovider.Writer, error) {
+		if dasReader == nil || keysetFetcher == nil {
+			return nil, nil, errors.New("DAS provider requires a DAS reader and a keyset fetcher")
+		}
+		var writer daprovider.Writer
+		if dasWriter != nil {
+			writer = NewWriterForDAS(dasWriter)
+		}
+		return NewReaderForDAS(dasReader, keysetFetcher), writer, nil
 	}
 }
 
 var (
 	ErrHashMismatch     = errors.New("result does not match expected hash")
 	ErrBatchToDasFailed = errors.New("unable to batch to DAS")
+	ErrPayloadTooLarge  = errors.New("DAS payload exceeds the maximum size")
+
+	ErrUnsupportedCertVersion = errors.New("unsupported DAS certificate version")
+	ErrMalformedSignersMask   = errors.New("signers mask claims signers outside the keyset")
+	ErrKeysetNotValid         = errors.New("keyset isn't registered as valid on chain")
+	ErrStoreNotRetrievable    = errors.New("stored DAS data can't be read back")
 )
 
+// MetricsPrefix is the prefix of the names of all metrics of the DAS subsystem.
+const MetricsPrefix = "arb/das/"
+
+// MetricName returns the full name of a DAS metric. A non-empty namespace, such as the name of the
+// chain, is inserted after MetricsPrefix, so that the metrics of several chains served by the same
+// process are kept apart.
+func MetricName(namespace string, name string) string {
+	if namespace == "" {
+		return MetricsPrefix + name
+	}
+	return MetricsPrefix + namespace + "/" + name
+}
+
+// recoveredPayloadSizeHistograms maps each metrics namespace to the histograms tracking the sizes of
+// the payloads recovered in it, indexed by cert version.
+var recoveredPayloadSizeHistograms sync.Map
+
+// RecordRecoveredPayloadSize records the size of a payload recovered from a cert of the given
+// version, for recoveries of payloads done outside of this package. Sizes for versions that can't be
+// recovered are dropped, as there's no histogram for them.
+func RecordRecoveredPayloadSize(namespace string, version uint8, size uint64) {
+	if version > MaxSupportedCertVersion {
+		return
+	}
+	// #nosec G115
+	recoveredPayloadSizeHistogram(namespace, version).Update(int64(size))
+}
+
+func recoveredPayloadSizeHistogram(namespace string, version uint8) metrics.Histogram {
+	if histograms, ok := recoveredPayloadSizeHistograms.Load(namespace); ok {
+		return histograms.([]metrics.Histogram)[version]
+	}
+	histograms := make([]metrics.Histogram, 0, MaxSupportedCertVersion+1)
+	for version := uint8(0); version <= MaxSupportedCertVersion; version++ {
+		name := MetricName(namespace, fmt.Sprintf("recover/payload/size/v%d", version))
+		histograms = append(histograms, metrics.GetOrRegisterHistogram(name, nil, metrics.NewBoundedHistogramSample()))
+	}
+	actual, _ := recoveredPayloadSizeHistograms.LoadOrStore(namespace, histograms)
+	return actual.([]metrics.Histogram)[version]
+}
+
 const MinLifetimeSecondsForDataAvailabilityCert = 7 * 24 * 60 * 60 // one week
 
+// MinLifetimeSecondsFloor is the shortest minimum cert lifetime RecoveryOptions can configure, so
+// that the check can be relaxed but never disabled.
+const MinLifetimeSecondsFloor = 24 * 60 * 60 // one day
+
+// MaxSupportedCertVersion is the highest DAS certificate version this software can recover.
+// Version 0 certs commit to the flat keccak hash of the data, later versions to its dastree hash.
+const MaxSupportedCertVersion uint8 = MultiChunkCertVersion
+
+// MaxWritableCertVersion is the highest DAS certificate version this software can produce.
+const MaxWritableCertVersion uint8 = 1
+
+// MultiChunkCertVersion certs commit to the dastree hash of the data like version 1 certs, and also
+// to the dastree hashes of the chunks the data is stored as, so that data can exceed the size of a
+// single stored value. They can be recovered from MultiChunkCertArbOSVersion on, but aren't
+// produced yet, so writers can't pin them.
+const MultiChunkCertVersion uint8 = 2
+
+// MultiChunkCertArbOSVersion is the first ArbOS version recovering MultiChunkCertVersion certs. Before
+// it, they're ignored like certs of unknown versions, as they are by older nodes. Their activation
+// isn't scheduled yet.
+const MultiChunkCertArbOSVersion uint64 = math.MaxUint64
+
+// TreeHeaderVersionCheckArbOSVersion is the first ArbOS version ignoring certs with the tree header
+// flag but version 0, which don't round-trip through serialization. Before it, they're recovered as
+// version 0 certs, as they are by older nodes. Its activation isn't scheduled yet.
+const TreeHeaderVersionCheckArbOSVersion uint64 = math.MaxUint64
+
+// SignersMaskCheckArbOSVersion is the first ArbOS version ignoring certs whose signers mask claims
+// signers beyond the keyset. Before it, the extra bits of their masks are ignored, as they are by
+// older nodes. Its activation isn't scheduled yet.
+const SignersMaskCheckArbOSVersion uint64 = math.MaxUint64
+
+// WeightedKeysetArbOSVersion is the first ArbOS version recovering certs of keysets serialized with
+// per-key weights. Before it, keysets are always parsed in the original format, as they are by older
+// nodes, which reads the versionedKeysetMarker as AssumedHonest. Its activation isn't scheduled yet.
+const WeightedKeysetArbOSVersion uint64 = math.MaxUint64
+
+// isRecoverableCertVersion returns whether certs of the given version are recovered at the given
+// ArbOS version.
+func isRecoverableCertVersion(version uint8, arbosVersion uint64) bool {
+	if version == MultiChunkCertVersion {
+		return arbosVersion >= MultiChunkCertArbOSVersion
+	}
+	return version <= MaxSupportedCertVersion
+}
+
+// DefaultCertVersion is the version of the certs produced by DASWriter.Store.
+const DefaultCertVersion uint8 = 1
+
+// SupportedCertVersions returns the DAS certificate versions recovered at the given ArbOS version, in
+// ascending order.
+func SupportedCertVersions(arbosVersion uint64) []uint8 {
+	var versions []uint8
+	for _, version := range certVersionsUpTo(MaxSupportedCertVersion) {
+		if isRecoverableCertVersion(version, arbosVersion) {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// WritableCertVersions returns the DAS certificate versions this software can produce, in
+// ascending order.
+func WritableCertVersions() []uint8 {
+	return certVersionsUpTo(MaxWritableCertVersion)
+}
+
+func certVersionsUpTo(maxVersion uint8) []uint8 {
+	versions := make([]uint8, 0, maxVersion+1)
+	for version := uint8(0); version <= maxVersion; version++ {
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// ValidateCertVersion returns ErrUnsupportedCertVersion if certs of the given version aren't recovered
+// at the given ArbOS version.
+func ValidateCertVersion(version uint8, arbosVersion uint64) error {
+	if !isRecoverableCertVersion(version, arbosVersion) {
+		return fmt.Errorf("%w: %d, supported versions are %v", ErrUnsupportedCertVersion, version, SupportedCertVersions(arbosVersion))
+	}
+	return nil
+}
+
+// ValidateWritableCertVersion returns ErrUnsupportedCertVersion if certs of the given version can't
+// be produced.
+func ValidateWritableCertVersion(version uint8) error {
+	if version > MaxWritableCertVersion {
+		return fmt.Errorf("%w: %d, writable versions are %v", ErrUnsupportedCertVersion, version, WritableCertVersions())
+	}
+	return nil
+}
+
+// CertDataHash returns the hash a cert of the given version commits to for the message.
+func CertDataHash(message []byte, version uint8) common.Hash {
+	if version == 0 {
+		return crypto.Keccak256Hash(message)
+	}
+	return dastree.Hash(message)
+}
+
+// RecoveryOptions tunes RecoverPayloadFromDasBatchWithOptions. The zero value matches the
+// behavior required for proving before MultiChunkCertArbOSVersion.
+type RecoveryOptions struct {
+	// ArbOSVersion is the ArbOS version the batch is read at, which gates the cert versions that
+	// are recovered rather than ignored.
+	ArbOSVersion uint64
+	// SkipTreeLeafRecording skips recording the synthetic dastree leaf of version 0 certs.
+	// The leaf is only needed by the prover, so non-proving read paths can save its allocations.
+	SkipTreeLeafRecording bool
+	// PostProcess, if set, transforms the recovered payload (e.g. decompressing it) before it's
+	// returned. Preimages are always recorded for the raw payload, as that's what the cert commits to.
+	PostProcess func(payload []byte) ([]byte, error)
+	// MaxPayloadSize, if non-zero, rejects payloads larger than this many bytes before they're
+	// hashed. Readers implementing SizeLimitedDASReader stop reading as soon as it's exceeded.
+	MaxPayloadSize uint64
+	// KeysetFetchRetries is how many times a failed keyset fetch is retried, if IsTransientError
+	// classifies its error as transient. Errors classified as permanent, such as the fetcher not
+	// knowing the keyset, are returned immediately.
+	KeysetFetchRetries int
+	// KeysetFetchRetryDelay is the wait before each retry of a keyset fetch.
+	KeysetFetchRetryDelay time.Duration
+	// IsTransientError classifies the errors of keyset fetches, e.g. das.IsTransient. Fetches are
+	// only retried if it's set.
+	IsTransientError func(error) bool
+	// MetricsNamespace namespaces the recovery metrics, as described by MetricName.
+	MetricsNamespace string
+	// MinCertLifetimeSeconds, if non-zero, replaces MinLifetimeSecondsForDataAvailabilityCert as
+	// how long certs must outlive the max timestamp of their batch. Values below
+	// MinLifetimeSecondsFloor are raised to it.
+	MinCertLifetimeSeconds uint64
+	// CheckKeysetValidity, if set, confirms the keyset of the cert is registered on chain for the
+	// batch, e.g. with das.KeysetFetcher.IsKeysetValid. Recovery fails with ErrKeysetNotValid for
+	// keysets it rejects, and with its error if it fails.
+	CheckKeysetValidity func(ctx context.Context, keysetHash common.Hash, batchNum uint64) (bool, error)
+	// RecordPhaseTimings, if set, is called once each recovery by RecoverPayloadFromDasBatchWithOptions
+	// ends with the time it spent in each phase, for tracing slow recoveries.
+	RecordPhaseTimings func(RecoveryPhaseTimings)
+	// RejectMalformedCerts makes recovery fail with an error wrapping daprovider.ErrSeqMsgValidation
+	// for sequencer messages whose cert can't be parsed, rather than ignoring them. It's meant for
+	// checking messages before they're posted, and must never be set when reading the inbox or
+	// replaying, as every node and the prover must agree to ignore a malformed cert someone posted.
+	RejectMalformedCerts bool
+
+	// recoverAllCertVersions lets certs of every supported version through startRecovery whatever
+	// ArbOSVersion is, for recoveries rejecting the versions they can't handle with an error.
+	recoverAllCertVersions bool
+	// validatedPayloads, if set, holds the raw payloads that passed the check against their cert's
+	// data hash, keyed by it, so that recovering the same batch again needn't fetch its payload.
+	validatedPayloads *lru.SizeConstrainedCache[common.Hash, []byte]
+}
+
+// RecoveryPhaseTimings is the time a recovery spent in each of its phases. Phases the recovery
+// didn't reach, as it failed or the message is ignored, are zero.
+type RecoveryPhaseTimings struct {
+	// KeysetFetch covers fetching the keyset of the cert, including retries, and deserializing it.
+	KeysetFetch time.Duration
+	// SignatureVerification covers verifying the signature of the cert.
+	SignatureVerification time.Duration
+	// DataFetch covers fetching the payload and checking it against the cert's data hash.
+	DataFetch time.Duration
+}
+
+// minCertLifetimeSeconds returns the minimum cert lifetime configured by opts.
+func (opts RecoveryOptions) minCertLifetimeSeconds() uint64 {
+	if opts.MinCertLifetimeSeconds == 0 {
+		return MinLifetimeSecondsForDataAvailabilityCert
+	}
+	return max(opts.MinCertLifetimeSeconds, MinLifetimeSecondsFloor)
+}
+
 func RecoverPayloadFromDasBatch(
 	ctx context.Context,
 	batchNum uint64,
@@ -107,32 +546,341 @@ func RecoverPayloadFromDasBatch(
 	preimages daprovider.PreimagesMap,
 	validateSeqMsg bool,
 ) ([]byte, daprovider.PreimagesMap, error) {
-	var preimageRecorder daprovider.PreimageRecorder
-	if preimages != nil {
-		preimageRecorder = daprovider.RecordPreimagesTo(preimages)
+	return RecoverPayloadFromDasBatchWithOptions(ctx, batchNum, sequencerMsg, dasReader, keysetFetcher, preimages, validateSeqMsg, RecoveryOptions{})
+}
+
+func RecoverPayloadFromDasBatchWithOptions(
+	ctx context.Context,
+	batchNum uint64,
+	sequencerMsg []byte,
+	dasReader DASReader,
+	keysetFetcher DASKeysetFetcher,
+	preimages daprovider.PreimagesMap,
+	validateSeqMsg bool,
+	opts RecoveryOptions,
+) ([]byte, daprovider.PreimagesMap, error) {
+	payload, preimages, _, err := RecoverPayloadAndCertFromDasBatch(ctx, batchNum, sequencerMsg, dasReader, keysetFetcher, preimages, validateSeqMsg, opts)
+	return payload, preimages, err
+}
+
+// RecoverPayloadTo is RecoverPayloadFromDasBatchWithOptions, writing the recovered payload to w
+// instead of returning it, and returning the number of bytes written. Nothing is written if no
+// payload is recovered. The payload must be read in full to check it against the cert's data hash
+// before any of it is written, so it's still buffered once in memory, but it's written from that
+// buffer without being copied again for the caller.
+func RecoverPayloadTo(
+	ctx context.Context,
+	batchNum uint64,
+	sequencerMsg []byte,
+	dasReader DASReader,
+	keysetFetcher DASKeysetFetcher,
+	preimages daprovider.PreimagesMap,
+	validateSeqMsg bool,
+	opts RecoveryOptions,
+	w io.Writer,
+) (int64, daprovider.PreimagesMap, error) {
+	payload, preimages, err := RecoverPayloadFromDasBatchWithOptions(ctx, batchNum, sequencerMsg, dasReader, keysetFetcher, preimages, validateSeqMsg, opts)
+	if err != nil || payload == nil {
+		return 0, preimages, err
+	}
+	n, err := w.Write(payload)
+	if err != nil {
+		return int64(n), preimages, fmt.Errorf("failed to write recovered DAS payload: %w", err)
+	}
+	return int64(n), preimages, nil
+}
+
+// RecoverPayloadWithProof is RecoverPayloadFromDasBatchWithOptions, also returning the dastree proof
+// of the payload's data hash, as built by dastree.Proof, so that light clients can check the bins of
+// the payload against the cert with dastree.VerifyProof. The proof is nil if no payload is recovered,
+// and for version 0 certs, which commit to the flat hash of the payload instead of its dastree.
+func RecoverPayloadWithProof(
+	ctx context.Context,
+	batchNum uint64,
+	sequencerMsg []byte,
+	dasReader DASReader,
+	keysetFetcher DASKeysetFetcher,
+	preimages daprovider.PreimagesMap,
+	validateSeqMsg bool,
+	opts RecoveryOptions,
+) ([]byte, daprovider.PreimagesMap, [][]byte, error) {
+	payload, preimages, cert, err := RecoverPayloadAndCertFromDasBatch(ctx, batchNum, sequencerMsg, dasReader, keysetFetcher, preimages, validateSeqMsg, opts)
+	if err != nil || payload == nil || cert.Version == 0 {
+		return payload, preimages, nil, err
 	}
-	cert, err := DeserializeDASCertFrom(bytes.NewReader(sequencerMsg[40:]))
+	// The preimages of the payload's dastree were recorded while recovering it.
+	recorded := preimages[arbutil.Keccak256PreimageType]
+	proof, err := dastree.Proof(cert.DataHash, func(hash common.Hash) ([]byte, error) {
+		preimage, ok := recorded[hash]
+		if !ok {
+			return nil, fmt.Errorf("preimage %v of the payload wasn't recorded", hash)
+		}
+		return preimage, nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return payload, preimages, proof, nil
+}
+
+// RecoverPayloadAndCertFromDasBatch is RecoverPayloadFromDasBatchWithOptions, also returning the cert
+// parsed from the sequencer message so callers needn't parse it again. The cert is returned even if
+// the payload isn't recovered, unless the message couldn't be parsed or has an unsupported version.
+// A nil payload means the message is to be ignored, while an empty payload the cert commits to is
+// returned as an empty, non-nil slice.
+func RecoverPayloadAndCertFromDasBatch(
+	ctx context.Context,
+	batchNum uint64,
+	sequencerMsg []byte,
+	dasReader DASReader,
+	keysetFetcher DASKeysetFetcher,
+	preimages daprovider.PreimagesMap,
+	validateSeqMsg bool,
+	opts RecoveryOptions,
+) ([]byte, daprovider.PreimagesMap, *DataAvailabilityCertificate, error) {
+	r, err := startRecovery(batchNum, sequencerMsg, dasReader, preimages, opts)
+	if r == nil {
+		return nil, nil, nil, err
+	}
+	if opts.RecordPhaseTimings != nil {
+		defer func() { opts.RecordPhaseTimings(r.timings) }()
+	}
+	if err := r.checkKeysetValidity(ctx, opts); err != nil {
+		return nil, nil, r.cert, err
+	}
+	start := time.Now()
+	keysetPreimage, err := r.fetchKeyset(ctx, keysetFetcher, opts)
+	if err == nil {
+		err = r.setKeyset(keysetPreimage, validateSeqMsg)
+	}
+	r.timings.KeysetFetch = time.Since(start)
+	if err != nil {
+		return nil, nil, r.cert, err
+	}
+	start = time.Now()
+	err = r.verifyCertSignature()
+	r.timings.SignatureVerification = time.Since(start)
+	if err != nil {
+		r.logBadSignature(err)
+		return nil, nil, r.cert, nil
+	}
+	payload, preimages, err := r.finish(ctx, opts)
+	return payload, preimages, r.cert, err
+}
+
+// ReaderForCert returns the reader to fetch the payload of cert from, which is the reader dedicated
+// to the cert's keyset if dasReader is a KeysetAwareDASReader.
+func ReaderForCert(dasReader DASReader, cert *DataAvailabilityCertificate) DASReader {
+	if keysetAwareReader, ok := dasReader.(KeysetAwareDASReader); ok {
+		return keysetAwareReader.ReaderForKeyset(cert.KeysetHash)
+	}
+	return dasReader
+}
+
+// RecoverCertFromDasBatch runs the checks RecoverPayloadFromDasBatchWithOptions makes before
+// fetching the payload: it parses the cert of the sequencer message, fetches and checks its keyset,
+// verifies its signature and checks its timeout. It returns the cert whose payload is to be
+// fetched, or nil if the message is to be ignored, for recoveries fetching payloads themselves.
+func RecoverCertFromDasBatch(
+	ctx context.Context,
+	batchNum uint64,
+	sequencerMsg []byte,
+	keysetFetcher DASKeysetFetcher,
+	validateSeqMsg bool,
+	opts RecoveryOptions,
+) (*DataAvailabilityCertificate, error) {
+	r, err := startRecovery(batchNum, sequencerMsg, nil, nil, opts)
+	if r == nil {
+		return nil, err
+	}
+	if err := r.checkKeysetValidity(ctx, opts); err != nil {
+		return nil, err
+	}
+	keysetPreimage, err := r.fetchKeyset(ctx, keysetFetcher, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.setKeyset(keysetPreimage, validateSeqMsg); err != nil {
+		return nil, err
+	}
+	if err := r.verifyCertSignature(); err != nil {
+		r.logBadSignature(err)
+		return nil, nil
+	}
+	if r.expiresTooSoon(opts) {
+		return nil, nil
+	}
+	return r.cert, nil
+}
+
+// dasRecovery holds the state of the recovery of a single batch between its phases, which
+// BatchRecover runs for many batches at once.
+type dasRecovery struct {
+	batchNum     uint64
+	cert         *DataAvailabilityCertificate
+	maxTimestamp uint64
+	arbosVersion uint64
+	// metricsNamespace is the namespace of the metrics updated while recovering.
+	metricsNamespace string
+	dasReader        DASReader
+	keyset           *DataAvailabilityKeyset
+	preimages        daprovider.PreimagesMap
+	timings          RecoveryPhaseTimings
+}
+
+// startRecovery deserializes the cert of the sequencer message, returning nil if the message is to
+// be ignored as its cert is malformed or of a version not recovered at opts.ArbOSVersion. If
+// opts.RejectMalformedCerts is set, a malformed message is reported with an error wrapping
+// daprovider.ErrSeqMsgValidation instead.
+func startRecovery(batchNum uint64, sequencerMsg []byte, dasReader DASReader, preimages daprovider.PreimagesMap, opts RecoveryOptions) (*dasRecovery, error) {
+	acceptTreeVersion0 := !opts.RejectMalformedCerts && opts.ArbOSVersion < TreeHeaderVersionCheckArbOSVersion
+	cert, maxTimestamp, err := deserializeDASCertFromMessage(sequencerMsg, acceptTreeVersion0)
 	if err != nil {
 		log.Error("Failed to deserialize DAS message", "err", err)
-		return nil, nil, nil
+		if opts.RejectMalformedCerts {
+			return nil, fmt.Errorf("%w. Couldn't deserialize DAS message, err: %w, batch num: %d", daprovider.ErrSeqMsgValidation, err, batchNum)
+		}
+		return nil, nil
 	}
-	version := cert.Version
+	if !opts.recoverAllCertVersions && !isRecoverableCertVersion(cert.Version, opts.ArbOSVersion) {
+		log.Error("Your node software is probably out of date", "certificateVersion", cert.Version, "supported", SupportedCertVersions(opts.ArbOSVersion), "arbosVersion", opts.ArbOSVersion)
+		return nil, nil
+	}
+	// Each recovery records into its own map unless the caller provides one, so that concurrent
+	// recoveries never interleave their preimages. Callers passing a map must not share it between
+	// concurrent recoveries.
+	if preimages == nil {
+		preimages = make(daprovider.PreimagesMap)
+	}
+	return &dasRecovery{
+		batchNum:         batchNum,
+		cert:             cert,
+		maxTimestamp:     maxTimestamp,
+		arbosVersion:     opts.ArbOSVersion,
+		metricsNamespace: opts.MetricsNamespace,
+		dasReader:        ReaderForCert(dasReader, cert),
+		preimages:        preimages,
+	}, nil
+}
+
+// checkKeysetValidity checks the keyset of the cert with opts.CheckKeysetValidity, if it's set.
+func (r *dasRecovery) checkKeysetValidity(ctx context.Context, opts RecoveryOptions) error {
+	if opts.CheckKeysetValidity == nil {
+		return nil
+	}
+	keysetHash := common.Hash(r.cert.KeysetHash)
+	valid, err := opts.CheckKeysetValidity(ctx, keysetHash, r.batchNum)
+	if err != nil {
+		return fmt.Errorf("failed to check validity of keyset %v: %w", keysetHash, err)
+	}
+	if !valid {
+		log.Error("DAS cert references a keyset that isn't valid on chain", "keysetHash", keysetHash, "batchNum", r.batchNum)
+		return fmt.Errorf("%w: keyset %v, batch num %d", ErrKeysetNotValid, keysetHash, r.batchNum)
+	}
+	return nil
+}
+
+// fetchKeyset fetches the keyset of the cert, retrying transient failures as configured by opts.
+func (r *dasRecovery) fetchKeyset(ctx context.Context, keysetFetcher DASKeysetFetcher, opts RecoveryOptions) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		keysetPreimage, err := keysetFetcher.GetKeysetByHash(ctx, r.cert.KeysetHash)
+		if err == nil {
+			return keysetPreimage, nil
+		}
+		if attempt >= opts.KeysetFetchRetries || opts.IsTransientError == nil || !opts.IsTransientError(err) {
+			log.Error("Couldn't get keyset", "err", err, "keysetHash", common.Bytes2Hex(r.cert.KeysetHash[:]), "attempts", attempt+1)
+			return nil, err
+		}
+		log.Warn("Transient failure getting keyset, retrying", "err", err, "keysetHash", common.Bytes2Hex(r.cert.KeysetHash[:]), "attempt", attempt+1)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w while retrying keyset fetch which failed with: %w", ctx.Err(), err)
+		case <-time.After(opts.KeysetFetchRetryDelay):
+		}
+	}
+}
+
+// setKeyset checks the preimage of the cert's keyset against its hash, records it and deserializes it.
+func (r *dasRecovery) setKeyset(keysetPreimage []byte, validateSeqMsg bool) error {
+	keysetHash := common.Hash(r.cert.KeysetHash)
+	if !ValidKeysetHash(r.cert.Version, keysetHash, keysetPreimage) {
+		return fmt.Errorf("%w: keyset preimage doesn't match keyset hash %v, batch num: %d", ErrHashMismatch, keysetHash, r.batchNum)
+	}
+	recorder := daprovider.RecordPreimagesTo(r.preimages)
+	if r.cert.Version == 0 && dastree.Hash(keysetPreimage) != keysetHash {
+		recordVersion0Preimages(recorder, keysetHash, keysetPreimage, true)
+	} else {
+		dastree.RecordHash(recorder, keysetPreimage)
+	}
+	keyset, err := deserializeKeysetBytes(keysetPreimage, !validateSeqMsg, r.arbosVersion >= WeightedKeysetArbOSVersion, r.metricsNamespace)
+	if err != nil {
+		return fmt.Errorf("%w. Couldn't deserialize keyset, err: %w, keyset hash: %x batch num: %d", daprovider.ErrSeqMsgValidation, err, r.cert.KeysetHash, r.batchNum)
+	}
+	r.keyset = keyset
+	return nil
+}
+
+// verifyCertSignature checks the signature of the cert against its keyset, which must be set.
+func (r *dasRecovery) verifyCertSignature() error {
+	return r.keyset.verifyCertSignature(r.cert, r.rejectsExtraSigners())
+}
+
+// rejectsExtraSigners returns whether signers masks claiming signers beyond the keyset are rejected,
+// rather than their extra bits ignored.
+func (r *dasRecovery) rejectsExtraSigners() bool {
+	return r.arbosVersion >= SignersMaskCheckArbOSVersion
+}
 
-	if version >= 2 {
-		log.Error("Your node software is probably out of date", "certificateVersion", version)
+func (r *dasRecovery) logBadSignature(err error) {
+	log.Error("Bad signature on DAS batch", "err", err, "claimedSigners", r.cert.NumClaimedSigners())
+}
+
+// expiresTooSoon reports whether the cert doesn't outlive the max timestamp of its batch by the
+// minimum cert lifetime, in which case its message is ignored.
+func (r *dasRecovery) expiresTooSoon(opts RecoveryOptions) bool {
+	if r.cert.Timeout < r.maxTimestamp+opts.minCertLifetimeSeconds() {
+		log.Error("Data availability cert expires too soon", "err", "")
+		return true
+	}
+	return false
+}
+
+// finish checks the timeout of the cert, whose signature must already be verified, then fetches,
+// records and post-processes the payload.
+func (r *dasRecovery) finish(ctx context.Context, opts RecoveryOptions) ([]byte, daprovider.PreimagesMap, error) {
+	cert := r.cert
+	version := cert.Version
+	if r.expiresTooSoon(opts) {
 		return nil, nil, nil
 	}
 
+	fetch := func(ctx context.Context, hash common.Hash) ([]byte, error) {
+		if opts.MaxPayloadSize == 0 {
+			return r.dasReader.GetByHash(ctx, hash)
+		}
+		var preimage []byte
+		var err error
+		if limitedReader, ok := r.dasReader.(SizeLimitedDASReader); ok {
+			preimage, err = limitedReader.GetByHashWithSizeLimit(ctx, hash, opts.MaxPayloadSize)
+		} else {
+			preimage, err = r.dasReader.GetByHash(ctx, hash)
+		}
+		if err == nil && uint64(len(preimage)) > opts.MaxPayloadSize {
+			err = fmt.Errorf("%w: got %d bytes, limit is %d", ErrPayloadTooLarge, len(preimage), opts.MaxPayloadSize)
+		}
+		return preimage, err
+	}
+
 	getByHash := func(ctx context.Context, hash common.Hash) ([]byte, error) {
 		newHash := hash
 		if version == 0 {
 			newHash = dastree.FlatHashToTreeHash(hash)
 		}
 
-		preimage, err := dasReader.GetByHash(ctx, newHash)
-		if err != nil && hash != newHash {
+		preimage, err := fetch(ctx, newHash)
+		if err != nil && hash != newHash && !errors.Is(err, ErrPayloadTooLarge) {
 			log.Debug("error fetching new style hash, trying old", "new", newHash, "old", hash, "err", err)
-			preimage, err = dasReader.GetByHash(ctx, hash)
+			preimage, err = fetch(ctx, hash)
 		}
 		if err != nil {
 			return nil, err
@@ -141,7 +889,7 @@ func RecoverPayloadFromDasBatch(
 		switch {
 		case version == 0 && crypto.Keccak256Hash(preimage) != hash:
 			fallthrough
-		case version == 1 && dastree.Hash(preimage) != hash:
+		case version != 0 && dastree.Hash(preimage) != hash:
 			log.Error(
 				"preimage mismatch for hash",
 				"hash", hash, "err", ErrHashMismatch, "version", version,
@@ -151,49 +899,111 @@ func RecoverPayloadFromDasBatch(
 		return preimage, nil
 	}
 
-	keysetPreimage, err := keysetFetcher.GetKeysetByHash(ctx, cert.KeysetHash)
+	dataHash := cert.DataHash
+	cacheable := opts.validatedPayloads != nil && version != MultiChunkCertVersion
+	var payload []byte
+	var chunks [][]byte
+	var cached bool
+	var err error
+	fetchStart := time.Now()
+	if cacheable {
+		payload, cached = opts.validatedPayloads.Get(dataHash)
+	}
+	switch {
+	case cached:
+		// The cached payload is shared, while the returned one may be post-processed in place.
+		payload = common.CopyBytes(payload)
+	case version == MultiChunkCertVersion:
+		payload, chunks, err = r.fetchChunks(ctx, getByHash, opts)
+	default:
+		payload, err = getByHash(ctx, dataHash)
+	}
+	r.timings.DataFetch = time.Since(fetchStart)
 	if err != nil {
-		log.Error("Couldn't get keyset", "err", err, "keysetHash", common.Bytes2Hex(cert.KeysetHash[:]))
+		log.Error("Couldn't fetch DAS batch contents", "err", err, "claimedSigners", cert.NumClaimedSigners())
 		return nil, nil, err
 	}
-	if preimageRecorder != nil {
-		dastree.RecordHash(preimageRecorder, keysetPreimage)
+	if payload == nil {
+		// The data is a validly stored empty payload, which mustn't be mistaken for an ignored message.
+		payload = []byte{}
+	}
+	if cacheable && !cached {
+		opts.validatedPayloads.Add(dataHash, common.CopyBytes(payload))
 	}
 
-	keyset, err := DeserializeKeyset(bytes.NewReader(keysetPreimage), !validateSeqMsg)
-	if err != nil {
-		return nil, nil, fmt.Errorf("%w. Couldn't deserialize keyset, err: %w, keyset hash: %x batch num: %d", daprovider.ErrSeqMsgValidation, err, cert.KeysetHash, batchNum)
+	preimageRecorder := daprovider.RecordPreimagesTo(r.preimages)
+	if version == 0 {
+		recordVersion0Preimages(preimageRecorder, dataHash, payload, !opts.SkipTreeLeafRecording)
+	} else {
+		dastree.RecordHash(preimageRecorder, payload)
 	}
-	err = keyset.VerifySignature(cert.SignersMask, cert.SerializeSignableFields(), cert.Sig)
-	if err != nil {
-		log.Error("Bad signature on DAS batch", "err", err)
-		return nil, nil, nil
+	for _, chunk := range chunks {
+		dastree.RecordHash(preimageRecorder, chunk)
 	}
 
-	maxTimestamp := binary.BigEndian.Uint64(sequencerMsg[8:16])
-	if cert.Timeout < maxTimestamp+MinLifetimeSecondsForDataAvailabilityCert {
-		log.Error("Data availability cert expires too soon", "err", "")
-		return nil, nil, nil
-	}
+	recoveredPayloadSizeHistogram(opts.MetricsNamespace, version).Update(int64(len(payload)))
 
-	dataHash := cert.DataHash
-	payload, err := getByHash(ctx, dataHash)
-	if err != nil {
-		log.Error("Couldn't fetch DAS batch contents", "err", err)
-		return nil, nil, err
+	if opts.PostProcess != nil {
+		payload, err = opts.PostProcess(payload)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to post-process DAS batch payload: %w", err)
+		}
+		if payload == nil {
+			payload = []byte{}
+		}
 	}
 
-	if preimageRecorder != nil {
-		if version == 0 {
-			treeLeaf := dastree.FlatHashToTreeLeaf(dataHash)
-			preimageRecorder(dataHash, payload, arbutil.Keccak256PreimageType)
-			preimageRecorder(crypto.Keccak256Hash(treeLeaf), treeLeaf, arbutil.Keccak256PreimageType)
-		} else {
-			dastree.RecordHash(preimageRecorder, payload)
+	return payload, r.preimages, nil
+}
+
+// fetchChunks fetches the chunks of a MultiChunkCertVersion cert in order with getByHash, which checks
+// each against its hash, and returns them along with their concatenation, which is checked against
+// the cert's hash of the whole payload.
+func (r *dasRecovery) fetchChunks(ctx context.Context, getByHash func(context.Context, common.Hash) ([]byte, error), opts RecoveryOptions) ([]byte, [][]byte, error) {
+	chunks := make([][]byte, 0, len(r.cert.DataHashes))
+	var length uint64
+	for i, chunkHash := range r.cert.DataHashes {
+		chunk, err := getByHash(ctx, chunkHash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("chunk %d of %d: %w", i, len(r.cert.DataHashes), err)
 		}
+		length += uint64(len(chunk))
+		if opts.MaxPayloadSize != 0 && length > opts.MaxPayloadSize {
+			return nil, nil, fmt.Errorf("%w: chunks add up to more than %d bytes", ErrPayloadTooLarge, opts.MaxPayloadSize)
+		}
+		chunks = append(chunks, chunk)
+	}
+	payload := make([]byte, 0, length)
+	for _, chunk := range chunks {
+		payload = append(payload, chunk...)
 	}
+	if dastree.Hash(payload) != r.cert.DataHash {
+		log.Error("preimage mismatch for hash of concatenated chunks", "hash", common.Hash(r.cert.DataHash), "err", ErrHashMismatch, "chunks", len(chunks), "length", length)
+		return nil, nil, ErrHashMismatch
+	}
+	return payload, chunks, nil
+}
 
-	return payload, preimages, nil
+// recordVersion0Preimages records the preimages of version 0 data with the given flat keccak hash:
+// the payload itself and, if recordTreeLeaf is set, the synthetic dastree leaf wrapping the flat hash.
+func recordVersion0Preimages(record func(common.Hash, []byte, arbutil.PreimageType), flatHash common.Hash, payload []byte, recordTreeLeaf bool) {
+	record(flatHash, payload, arbutil.Keccak256PreimageType)
+	if recordTreeLeaf {
+		treeLeaf := dastree.FlatHashToTreeLeaf(flatHash)
+		record(crypto.Keccak256Hash(treeLeaf), treeLeaf, arbutil.Keccak256PreimageType)
+	}
+}
+
+// Version0DataHashes returns both hashes of version 0 data: the flat keccak hash its cert commits
+// to, and the dastree hash of the degenerate single-leaf tree it's stored under. The preimages are
+// those recovering the data records.
+func Version0DataHashes(flatHash common.Hash, payload []byte) (common.Hash, common.Hash, daprovider.PreimagesMap, error) {
+	if crypto.Keccak256Hash(payload) != flatHash {
+		return common.Hash{}, common.Hash{}, nil, fmt.Errorf("%w: payload doesn't match flat hash %v", ErrHashMismatch, flatHash)
+	}
+	preimages := make(daprovider.PreimagesMap)
+	recordVersion0Preimages(daprovider.RecordPreimagesTo(preimages), flatHash, payload, true)
+	return flatHash, dastree.FlatHashToTreeHash(flatHash), preimages, nil
 }
 
 type DataAvailabilityCertificate struct {
@@ -203,9 +1013,117 @@ type DataAvailabilityCertificate struct {
 	SignersMask uint64
 	Sig         blsSignatures.Signature
 	Version     uint8
+	// DataHashes are the dastree hashes of the chunks the data of a MultiChunkCertVersion cert is
+	// stored as, in order. They're serialized after the version, preceded by their count, so at most
+	// 255 can be referenced.
+	DataHashes [][32]byte
+}
+
+// maxCertDataChunks is the most chunks a MultiChunkCertVersion cert can reference, as their count
+// is serialized as a single byte.
+const maxCertDataChunks = math.MaxUint8
+
+// sequencerMsgHeaderLen is the length of the L1 header (min/max timestamp, min/max L1 block
+// and after delayed messages count) preceding the payload of a sequencer message.
+const sequencerMsgHeaderLen = 40
+
+// DeserializeDASCertFromMessage parses the DAS certificate following the L1 header of a
+// sequencer message, returning the certificate along with the header's max timestamp.
+func DeserializeDASCertFromMessage(sequencerMsg []byte) (*DataAvailabilityCertificate, uint64, error) {
+	return deserializeDASCertFromMessage(sequencerMsg, false)
+}
+
+// deserializeDASCertFromMessage is DeserializeDASCertFromMessage, parsing certs with the tree header
+// flag but version 0 as version 0 certs if acceptTreeVersion0 is set.
+func deserializeDASCertFromMessage(sequencerMsg []byte, acceptTreeVersion0 bool) (*DataAvailabilityCertificate, uint64, error) {
+	if len(sequencerMsg) <= sequencerMsgHeaderLen {
+		return nil, 0, fmt.Errorf("sequencer message of length %d is too short to contain a DAS certificate", len(sequencerMsg))
+	}
+	maxTimestamp := binary.BigEndian.Uint64(sequencerMsg[8:16])
+	cert, err := deserializeDASCertFrom(bytes.NewReader(sequencerMsg[sequencerMsgHeaderLen:]), UncompressedSignatures, acceptTreeVersion0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return cert, maxTimestamp, nil
+}
+
+// ParsedCert is a cert parsed by DeserializeDASCertsFromMessages, along with the index of its
+// sequencer message and the message's max timestamp.
+type ParsedCert struct {
+	Index        int
+	Cert         *DataAvailabilityCertificate
+	MaxTimestamp uint64
+}
+
+// CertParseError records a sequencer message whose cert failed to parse.
+type CertParseError struct {
+	Index int
+	Err   error
+}
+
+func (e CertParseError) Error() string {
+	return fmt.Sprintf("sequencer message %d: %v", e.Index, e.Err)
+}
+
+func (e CertParseError) Unwrap() error {
+	return e.Err
+}
+
+// DeserializeDASCertsFromMessages parses the certs of many sequencer messages with
+// DeserializeDASCertFromMessage, as when analyzing a range of batches. Rather than stopping at the
+// first malformed message, it returns the certs that parsed and the failures of the others, each
+// in the order of their messages.
+func DeserializeDASCertsFromMessages(sequencerMsgs [][]byte) ([]ParsedCert, []CertParseError) {
+	parsed := make([]ParsedCert, 0, len(sequencerMsgs))
+	var parseErrors []CertParseError
+	for i, sequencerMsg := range sequencerMsgs {
+		cert, maxTimestamp, err := DeserializeDASCertFromMessage(sequencerMsg)
+		if err != nil {
+			parseErrors = append(parseErrors, CertParseError{Index: i, Err: err})
+			continue
+		}
+		parsed = append(parsed, ParsedCert{Index: i, Cert: cert, MaxTimestamp: maxTimestamp})
+	}
+	return parsed, parseErrors
 }
 
 func DeserializeDASCertFrom(rd io.Reader) (c *DataAvailabilityCertificate, err error) {
+	return deserializeDASCertFrom(rd, UncompressedSignatures, false)
+}
+
+// DeserializeDASCertWithSignatureEncoding deserializes a cert serialized by
+// SerializeWithSignatureEncoding with the same encoding, which must be all of data.
+func DeserializeDASCertWithSignatureEncoding(data []byte, encoding SignatureEncoding) (*DataAvailabilityCertificate, error) {
+	sigLen, err := encoding.length()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty DAS certificate")
+	}
+	expectedLen := 1 + 32 + 32 + 8 + 8 + sigLen
+	if daprovider.IsTreeDASMessageHeaderByte(data[0]) {
+		expectedLen++
+		// The chunk hashes of multi-chunk certs follow the version and their count.
+		const versionOffset = 1 + 32 + 32 + 8
+		if len(data) > versionOffset+1 && data[versionOffset] == MultiChunkCertVersion {
+			expectedLen += 1 + common.HashLength*int(data[versionOffset+1])
+		}
+	}
+	if len(data) != expectedLen {
+		return nil, fmt.Errorf("DAS certificate is %d bytes, expected %d for a %d byte signature", len(data), expectedLen, sigLen)
+	}
+	return deserializeDASCertFrom(bytes.NewReader(data), encoding, false)
+}
+
+// deserializeDASCertFrom deserializes a cert whose signature has the given encoding. Certs with the
+// tree header flag but version 0 are rejected unless acceptTreeVersion0 is set, for recovering them
+// as version 0 certs before TreeHeaderVersionCheckArbOSVersion.
+func deserializeDASCertFrom(rd io.Reader, encoding SignatureEncoding, acceptTreeVersion0 bool) (c *DataAvailabilityCertificate, err error) {
+	sigLen, err := encoding.length()
+	if err != nil {
+		return nil, err
+	}
 	r := bufio.NewReader(rd)
 	c = &DataAvailabilityCertificate{}
 
@@ -240,9 +1158,30 @@ func DeserializeDASCertFrom(rd io.Reader) (c *DataAvailabilityCertificate, err e
 		if err != nil {
 			return nil, err
 		}
+		// Version 0 certs are serialized without the tree flag and version byte, so a tree cert
+		// claiming version 0 wouldn't round-trip and its signature would cover different fields.
+		if versionBuf[0] == 0 && !acceptTreeVersion0 {
+			return nil, errors.New("DAS certificate has the tree header flag but version 0")
+		}
 		c.Version = versionBuf[0]
 	}
 
+	if c.Version == MultiChunkCertVersion {
+		count, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, errors.New("multi-chunk DAS certificate has no chunks")
+		}
+		c.DataHashes = make([][32]byte, count)
+		for i := range c.DataHashes {
+			if _, err := io.ReadFull(r, c.DataHashes[i][:]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	var signersMaskBuf [8]byte
 	_, err = io.ReadFull(r, signersMaskBuf[:])
 	if err != nil {
@@ -250,12 +1189,12 @@ func DeserializeDASCertFrom(rd io.Reader) (c *DataAvailabilityCertificate, err e
 	}
 	c.SignersMask = binary.BigEndian.Uint64(signersMaskBuf[:])
 
-	var blsSignaturesBuf [96]byte
-	_, err = io.ReadFull(r, blsSignaturesBuf[:])
+	blsSignaturesBuf := make([]byte, sigLen)
+	_, err = io.ReadFull(r, blsSignaturesBuf)
 	if err != nil {
 		return nil, err
 	}
-	c.Sig, err = blsSignatures.SignatureFromBytes(blsSignaturesBuf[:])
+	c.Sig, err = encoding.signatureFromBytes(blsSignaturesBuf)
 	if err != nil {
 		return nil, err
 	}
@@ -263,7 +1202,26 @@ func DeserializeDASCertFrom(rd io.Reader) (c *DataAvailabilityCertificate, err e
 	return c, nil
 }
 
-func (c *DataAvailabilityCertificate) SerializeSignableFields() []byte {
+// NumClaimedSigners returns the number of keyset members the cert claims signed it, without
+// checking the claim against the keyset.
+func (c *DataAvailabilityCertificate) NumClaimedSigners() int {
+	return bits.OnesCount64(c.SignersMask)
+}
+
+// Clone returns a deep copy of the cert, which doesn't share its signature.
+func (c *DataAvailabilityCertificate) Clone() *DataAvailabilityCertificate {
+	clone := *c
+	clone.DataHashes = slices.Clone(c.DataHashes)
+	if c.Sig != nil {
+		sig := *c.Sig
+		clone.Sig = &sig
+	}
+	return &clone
+}
+
+// SerializeSignableFields serializes the fields covered by the signature of the cert. It fails for
+// MultiChunkCertVersion certs referencing more than 255 chunks, as their count can't be serialized.
+func (c *DataAvailabilityCertificate) SerializeSignableFields() ([]byte, error) {
 	buf := make([]byte, 0, 32+9)
 	buf = append(buf, c.DataHash[:]...)
 
@@ -275,7 +1233,18 @@ func (c *DataAvailabilityCertificate) SerializeSignableFields() []byte {
 		buf = append(buf, c.Version)
 	}
 
-	return buf
+	if c.Version == MultiChunkCertVersion {
+		if len(c.DataHashes) > maxCertDataChunks {
+			return nil, fmt.Errorf("multi-chunk DAS certificate references %d chunks, at most %d are supported", len(c.DataHashes), maxCertDataChunks)
+		}
+		// #nosec G115
+		buf = append(buf, uint8(len(c.DataHashes)))
+		for _, chunkHash := range c.DataHashes {
+			buf = append(buf, chunkHash[:]...)
+		}
+	}
+
+	return buf, nil
 }
 
 func (c *DataAvailabilityCertificate) RecoverKeyset(
@@ -285,20 +1254,64 @@ func (c *DataAvailabilityCertificate) RecoverKeyset(
 ) (*DataAvailabilityKeyset, error) {
 	keysetBytes, err := da.GetByHash(ctx, c.KeysetHash)
 	if err != nil {
-		return nil, err
-	}
-	if !dastree.ValidHash(c.KeysetHash, keysetBytes) {
+		// Very old chains reference keysets by their flat keccak hash, which storage may hold
+		// under the equivalent dastree hash, so try that as the data path does for version 0.
+		legacyHash := dastree.FlatHashToTreeHash(c.KeysetHash)
+		log.Debug("error fetching keyset, trying legacy flat hash", "hash", c.KeysetHash, "legacy", legacyHash, "err", err)
+		var legacyErr error
+		keysetBytes, legacyErr = da.GetByHash(ctx, legacyHash)
+		if legacyErr != nil {
+			return nil, err
+		}
+		if crypto.Keccak256Hash(keysetBytes) != c.KeysetHash {
+			return nil, errors.New("keyset flat hash does not match cert")
+		}
+	} else if !ValidKeysetHash(c.Version, c.KeysetHash, keysetBytes) {
 		return nil, errors.New("keyset hash does not match cert")
 	}
 	return DeserializeKeyset(bytes.NewReader(keysetBytes), assumeKeysetValid)
 }
 
+// ValidKeysetHash reports whether keyset is the preimage of keysetHash, as referenced by a cert of
+// the given version. Keysets of version 0 certs, which predate tree hashing on the oldest chains,
+// may be referenced by their flat keccak hash whatever their first byte, which dastree.ValidHash
+// only accepts for preimages that can't be mistaken for tree nodes.
+func ValidKeysetHash(certVersion uint8, keysetHash common.Hash, keyset []byte) bool {
+	if certVersion == 0 && crypto.Keccak256Hash(keyset) == keysetHash {
+		return true
+	}
+	return dastree.ValidHash(keysetHash, keyset)
+}
+
 type DataAvailabilityKeyset struct {
 	AssumedHonest uint64
 	PubKeys       []blsSignatures.PublicKey
+	// Weights optionally assigns a voting weight to each of PubKeys, in which case AssumedHonest is
+	// the total weight of honest members assumed. Unweighted keysets give every member weight 1.
+	Weights []uint64
 }
 
+// versionedKeysetMarker starts the serialization of keysets using a format newer than the original
+// one, followed by a version byte. The original format starts with AssumedHonest instead, which
+// can't take this value in a meaningful keyset.
+const versionedKeysetMarker = math.MaxUint64
+
+// WeightedKeysetVersion is the serialization version of keysets with per-key weights, which are
+// appended after the public keys. Unweighted keysets keep the original, unversioned format.
+const WeightedKeysetVersion uint8 = 1
+
 func (keyset *DataAvailabilityKeyset) Serialize(wr io.Writer) error {
+	if keyset.Weights != nil {
+		if len(keyset.Weights) != len(keyset.PubKeys) {
+			return fmt.Errorf("keyset has %d weights for %d public keys", len(keyset.Weights), len(keyset.PubKeys))
+		}
+		if err := util.Uint64ToWriter(versionedKeysetMarker, wr); err != nil {
+			return err
+		}
+		if _, err := wr.Write([]byte{WeightedKeysetVersion}); err != nil {
+			return err
+		}
+	}
 	if err := util.Uint64ToWriter(keyset.AssumedHonest, wr); err != nil {
 		return err
 	}
@@ -313,76 +1326,313 @@ func (keyset *DataAvailabilityKeyset) Serialize(wr io.Writer) error {
 			return err
 		}
 	}
+	for _, weight := range keyset.Weights {
+		if err := util.Uint64ToWriter(weight, wr); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (keyset *DataAvailabilityKeyset) Hash() (common.Hash, error) {
+	return keyset.HashWithMaxSize(dastree.BinSize)
+}
+
+// HashWithMaxSize is Hash, failing if the serialized keyset is larger than maxSize bytes instead of
+// dastree.BinSize, e.g. for test chains with large committees. A maxSize of 0 means dastree.BinSize.
+func (keyset *DataAvailabilityKeyset) HashWithMaxSize(maxSize int) (common.Hash, error) {
+	if maxSize == 0 {
+		maxSize = dastree.BinSize
+	}
 	wr := bytes.NewBuffer([]byte{})
 	if err := keyset.Serialize(wr); err != nil {
 		return common.Hash{}, err
 	}
-	if wr.Len() > dastree.BinSize {
-		return common.Hash{}, errors.New("keyset too large")
+	if wr.Len() > maxSize {
+		return common.Hash{}, fmt.Errorf("keyset too large: %d bytes exceeds the limit of %d bytes", wr.Len(), maxSize)
 	}
 	return dastree.Hash(wr.Bytes()), nil
 }
 
 func DeserializeKeyset(rd io.Reader, assumeKeysetValid bool) (*DataAvailabilityKeyset, error) {
-	assumedHonest, err := util.Uint64FromReader(rd)
+	keyset, _, err := deserializeKeyset(rd, assumeKeysetValid, true, true)
+	return keyset, err
+}
+
+// publicKeyFromBytes parses the public keys of keysets, and is replaced by tests counting validations.
+var publicKeyFromBytes = blsSignatures.PublicKeyFromBytes
+
+// validatedKeyset identifies a serialized keyset along with the format it was parsed in, as the
+// same bytes may hold different public keys depending on whether weighted keysets are accepted.
+type validatedKeyset struct {
+	hash           common.Hash
+	acceptWeighted bool
+}
+
+// strictlyValidatedKeysets holds the serialized keysets whose public keys all passed strict
+// validation. Deserializing without validation says nothing about validity, so it's never recorded.
+// The cache is shared by the whole process, as validity doesn't depend on the chain, so a keyset
+// validated while recovering for one metrics namespace is a hit when recovering for another.
+var strictlyValidatedKeysets = lru.NewCache[validatedKeyset, struct{}](256)
+
+// keysetValidationCacheCounter returns the counter of the given keyset validation cache event in the
+// given metrics namespace.
+func keysetValidationCacheCounter(namespace string, event string) *metrics.Counter {
+	return metrics.GetOrRegisterCounter(MetricName(namespace, "keyset_validation_cache/"+event), nil)
+}
+
+// DeserializeKeysetBytes is DeserializeKeyset for a keyset already in memory. Strict validation of
+// the public keys of a keyset only runs the first time its bytes are deserialized with
+// assumeKeysetValid unset, as the result is remembered for later deserializations of the same bytes.
+func DeserializeKeysetBytes(data []byte, assumeKeysetValid bool) (*DataAvailabilityKeyset, error) {
+	return deserializeKeysetBytes(data, assumeKeysetValid, true, "")
+}
+
+// deserializeKeysetBytes is DeserializeKeysetBytes, parsing keysets in the original format even if
+// they start with the versionedKeysetMarker unless acceptWeighted is set, and counting validation
+// cache events in the given metrics namespace.
+func deserializeKeysetBytes(data []byte, assumeKeysetValid bool, acceptWeighted bool, metricsNamespace string) (*DataAvailabilityKeyset, error) {
+	deserialize := func(assumeKeysetValid bool) (*DataAvailabilityKeyset, error) {
+		keyset, _, err := deserializeKeyset(bytes.NewReader(data), assumeKeysetValid, true, acceptWeighted)
+		return keyset, err
+	}
+	if assumeKeysetValid {
+		return deserialize(true)
+	}
+	key := validatedKeyset{hash: crypto.Keccak256Hash(data), acceptWeighted: acceptWeighted}
+	if strictlyValidatedKeysets.Contains(key) {
+		keysetValidationCacheCounter(metricsNamespace, "hits").Inc(1)
+		return deserialize(true)
+	}
+	keysetValidationCacheCounter(metricsNamespace, "misses").Inc(1)
+	keyset, err := deserialize(false)
 	if err != nil {
 		return nil, err
 	}
+	if evicted := strictlyValidatedKeysets.Add(key, struct{}{}); evicted {
+		keysetValidationCacheCounter(metricsNamespace, "evictions").Inc(1)
+	}
+	return keyset, nil
+}
+
+// KeysetKeyError records a public key of a serialized keyset that failed to parse.
+type KeysetKeyError struct {
+	Index uint64
+	Err   error
+}
+
+func (e KeysetKeyError) Error() string {
+	return fmt.Sprintf("public key %d: %v", e.Index, e.Err)
+}
+
+func (e KeysetKeyError) Unwrap() error {
+	return e.Err
+}
+
+// DeserializeKeysetCollectingErrors is a diagnostic variant of DeserializeKeyset which, rather than
+// aborting on the first public key that fails to parse, records the failure and continues with the
+// remaining keys. Keys that failed to parse are left as zero values so that indices still match the
+// signers mask. Structural errors (e.g. truncated input) still abort.
+func DeserializeKeysetCollectingErrors(rd io.Reader, assumeKeysetValid bool) (*DataAvailabilityKeyset, []KeysetKeyError, error) {
+	return deserializeKeyset(rd, assumeKeysetValid, false, true)
+}
+
+// deserializeKeyset deserializes a keyset, recording the public keys that fail to parse rather than
+// failing unless failFast is set. Keysets starting with the versionedKeysetMarker are parsed in the
+// original format, with the marker as AssumedHonest, unless acceptWeighted is set.
+func deserializeKeyset(rd io.Reader, assumeKeysetValid bool, failFast bool, acceptWeighted bool) (*DataAvailabilityKeyset, []KeysetKeyError, error) {
+	assumedHonest, err := util.Uint64FromReader(rd)
+	if err != nil {
+		return nil, nil, err
+	}
+	weighted := false
+	if acceptWeighted && assumedHonest == versionedKeysetMarker {
+		var versionBuf [1]byte
+		if _, err := io.ReadFull(rd, versionBuf[:]); err != nil {
+			return nil, nil, err
+		}
+		if versionBuf[0] != WeightedKeysetVersion {
+			return nil, nil, fmt.Errorf("unsupported keyset version %d", versionBuf[0])
+		}
+		weighted = true
+		assumedHonest, err = util.Uint64FromReader(rd)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 	numKeys, err := util.Uint64FromReader(rd)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if numKeys > 64 {
-		return nil, errors.New("too many keys in serialized DataAvailabilityKeyset")
+		return nil, nil, errors.New("too many keys in serialized DataAvailabilityKeyset")
 	}
 	pubkeys := make([]blsSignatures.PublicKey, numKeys)
+	var keyErrors []KeysetKeyError
 	buf2 := []byte{0, 0}
 	for i := uint64(0); i < numKeys; i++ {
 		if _, err := io.ReadFull(rd, buf2); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		buf := make([]byte, int(buf2[0])*256+int(buf2[1]))
 		if _, err := io.ReadFull(rd, buf); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		pubkeys[i], err = blsSignatures.PublicKeyFromBytes(buf, assumeKeysetValid)
+		pubkeys[i], err = publicKeyFromBytes(buf, assumeKeysetValid)
 		if err != nil {
-			return nil, err
+			if failFast {
+				return nil, nil, err
+			}
+			keyErrors = append(keyErrors, KeysetKeyError{Index: i, Err: err})
+		}
+	}
+	var weights []uint64
+	if weighted {
+		weights = make([]uint64, numKeys)
+		for i := range weights {
+			weights[i], err = util.Uint64FromReader(rd)
+			if err != nil {
+				return nil, nil, err
+			}
+			if weights[i] == 0 {
+				return nil, nil, fmt.Errorf("public key %d of weighted keyset has zero weight", i)
+			}
 		}
 	}
 	return &DataAvailabilityKeyset{
 		AssumedHonest: assumedHonest,
 		PubKeys:       pubkeys,
-	}, nil
+		Weights:       weights,
+	}, keyErrors, nil
+}
+
+func (keyset *DataAvailabilityKeyset) weight(i int) uint64 {
+	if keyset.Weights == nil {
+		return 1
+	}
+	return keyset.Weights[i]
 }
 
+func (keyset *DataAvailabilityKeyset) totalWeight() uint64 {
+	total := uint64(0)
+	for i := range keyset.PubKeys {
+		total = arbmath.SaturatingUAdd(total, keyset.weight(i))
+	}
+	return total
+}
+
+// MaxTolerableFailures returns how many members can fail to sign while certs still verify, or for
+// weighted keysets the total weight of members that can. VerifySignature rejects certs once the
+// non-signers reach AssumedHonest, as the signers might then all be dishonest, so this is
+// AssumedHonest-1 capped at the size of the keyset.
+func (keyset *DataAvailabilityKeyset) MaxTolerableFailures() uint64 {
+	if keyset.AssumedHonest == 0 {
+		return 0
+	}
+	return arbmath.MinInt(keyset.AssumedHonest-1, keyset.totalWeight())
+}
+
+// QuorumSize returns the minimum number of members, or for weighted keysets the minimum total
+// weight of members, that must sign for a cert to verify. A keyset with AssumedHonest 0 can't
+// verify any cert, so its quorum is larger than the keyset.
+func (keyset *DataAvailabilityKeyset) QuorumSize() uint64 {
+	if keyset.AssumedHonest == 0 {
+		return arbmath.SaturatingUAdd(keyset.totalWeight(), 1)
+	}
+	return keyset.totalWeight() - keyset.MaxTolerableFailures()
+}
+
+// VerifySignature checks that data was signed by enough of the members of the keyset in signersMask,
+// rejecting masks with bits set beyond the keyset with ErrMalformedSignersMask.
 func (keyset *DataAvailabilityKeyset) VerifySignature(signersMask uint64, data []byte, sig blsSignatures.Signature) error {
+	return keyset.verifySignature(signersMask, data, sig, true)
+}
+
+// verifySignature is VerifySignature, ignoring the bits of signersMask beyond the keyset unless
+// rejectExtraSigners is set, as recovery does before SignersMaskCheckArbOSVersion.
+func (keyset *DataAvailabilityKeyset) verifySignature(signersMask uint64, data []byte, sig blsSignatures.Signature, rejectExtraSigners bool) error {
+	aggregatedPubKey, err := keyset.signersPublicKey(signersMask, rejectExtraSigners)
+	if err != nil {
+		return err
+	}
+	success, err := blsSignatures.VerifySignature(sig, data, aggregatedPubKey)
+
+	if err != nil {
+		return err
+	}
+	if !success {
+		return errors.New("bad signature")
+	}
+	return nil
+}
+
+// signersPublicKey aggregates the public keys of the members in signersMask, failing if they're not
+// enough to trust a signature by them. Bits of signersMask beyond the keyset fail it with
+// ErrMalformedSignersMask if rejectExtraSigners is set, and are ignored otherwise.
+func (keyset *DataAvailabilityKeyset) signersPublicKey(signersMask uint64, rejectExtraSigners bool) (blsSignatures.PublicKey, error) {
+	if keyset.Weights != nil && len(keyset.Weights) != len(keyset.PubKeys) {
+		return blsSignatures.PublicKey{}, fmt.Errorf("keyset has %d weights for %d public keys", len(keyset.Weights), len(keyset.PubKeys))
+	}
+	// Bits past the last key would otherwise be ignored, letting a cert claim signers that don't exist.
+	if rejectExtraSigners && signersMask>>len(keyset.PubKeys) != 0 {
+		return blsSignatures.PublicKey{}, fmt.Errorf("%w: mask %#x for a keyset of %d keys", ErrMalformedSignersMask, signersMask, len(keyset.PubKeys))
+	}
 	pubkeys := []blsSignatures.PublicKey{}
-	numNonSigners := uint64(0)
+	nonSignersWeight := uint64(0)
 	for i := 0; i < len(keyset.PubKeys); i++ {
 		if (1<<i)&signersMask != 0 {
 			pubkeys = append(pubkeys, keyset.PubKeys[i])
 		} else {
-			numNonSigners++
+			nonSignersWeight = arbmath.SaturatingUAdd(nonSignersWeight, keyset.weight(i))
 		}
 	}
-	if numNonSigners >= keyset.AssumedHonest {
-		return errors.New("not enough signers")
+	if nonSignersWeight >= keyset.AssumedHonest {
+		return blsSignatures.PublicKey{}, errors.New("not enough signers")
 	}
-	aggregatedPubKey := blsSignatures.AggregatePublicKeys(pubkeys)
-	success, err := blsSignatures.VerifySignature(sig, data, aggregatedPubKey)
+	return blsSignatures.AggregatePublicKeys(pubkeys), nil
+}
 
+// VerifyCertSignature checks that the cert was signed by enough members of the given keyset,
+// without fetching anything. The keyset must be the one the cert references.
+func VerifyCertSignature(cert *DataAvailabilityCertificate, keyset *DataAvailabilityKeyset) error {
+	keysetHash, err := keyset.Hash()
 	if err != nil {
 		return err
 	}
-	if !success {
-		return errors.New("bad signature")
+	if keysetHash != cert.KeysetHash {
+		return fmt.Errorf("keyset hash %v does not match cert keyset hash %v", keysetHash, common.Hash(cert.KeysetHash))
 	}
-	return nil
+	return keyset.verifyCertSignature(cert, true)
+}
+
+// verifyCertSignature checks that the cert was signed by the members of the keyset in its signers
+// mask, as verifySignature does.
+func (keyset *DataAvailabilityKeyset) verifyCertSignature(cert *DataAvailabilityCertificate, rejectExtraSigners bool) error {
+	signableFields, err := cert.SerializeSignableFields()
+	if err != nil {
+		return err
+	}
+	return keyset.verifySignature(cert.SignersMask, signableFields, cert.Sig, rejectExtraSigners)
+}
+
+// ReSignCert returns a copy of the cert moved to a new keyset, as when a keyset is rotated and data
+// stored under the old one should stay available without being stored again. The new cert commits
+// to the same data and timeout, signed by the members of newKeyset in newSignersMask. newSig must
+// sign the SerializeSignableFields of the cert, which cover its DataHash, Timeout, Version and
+// DataHashes but not the keyset hash, and is checked to verify against newKeyset.
+func ReSignCert(cert *DataAvailabilityCertificate, newKeyset *DataAvailabilityKeyset, newSignersMask uint64, newSig blsSignatures.Signature) (*DataAvailabilityCertificate, error) {
+	keysetHash, err := newKeyset.Hash()
+	if err != nil {
+		return nil, err
+	}
+	resigned := cert.Clone()
+	resigned.KeysetHash = keysetHash
+	resigned.SignersMask = newSignersMask
+	resigned.Sig = newSig
+	if err := VerifyCertSignature(resigned, newKeyset); err != nil {
+		return nil, fmt.Errorf("re-signed cert doesn't verify against the new keyset: %w", err)
+	}
+	return resigned, nil
 }
 
 type ExpirationPolicy int64
@@ -430,21 +1680,72 @@ func StringToExpirationPolicy(s string) (ExpirationPolicy, error) {
 	}
 }
 
-func Serialize(c *DataAvailabilityCertificate) []byte {
-
+// headerFlags returns the header byte of the cert. The tree flag is set if and only if the cert
+// has a non-zero version, which is then serialized after the timeout.
+func (c *DataAvailabilityCertificate) headerFlags() byte {
 	flags := daprovider.DASMessageHeaderFlag
 	if c.Version != 0 {
 		flags |= daprovider.TreeDASMessageHeaderFlag
 	}
+	return flags
+}
+
+// SignatureEncoding selects how the BLS signature of a cert is serialized.
+type SignatureEncoding uint8
+
+const (
+	// UncompressedSignatures is the encoding of Serialize and DeserializeDASCertFrom, which certs
+	// posted on chain must use.
+	UncompressedSignatures SignatureEncoding = iota
+	// CompressedSignatures is the compressed encoding of other BLS libraries, for exchanging certs
+	// with them off chain.
+	CompressedSignatures
+)
+
+func (e SignatureEncoding) length() (int, error) {
+	switch e {
+	case UncompressedSignatures:
+		return blsSignatures.SignatureLength, nil
+	case CompressedSignatures:
+		return blsSignatures.CompressedSignatureLength, nil
+	default:
+		return 0, fmt.Errorf("unknown signature encoding %d", e)
+	}
+}
+
+func (e SignatureEncoding) signatureFromBytes(in []byte) (blsSignatures.Signature, error) {
+	if e == CompressedSignatures {
+		return blsSignatures.SignatureFromCompressedBytes(in)
+	}
+	return blsSignatures.SignatureFromBytes(in)
+}
+
+func (e SignatureEncoding) signatureToBytes(sig blsSignatures.Signature) []byte {
+	if e == CompressedSignatures {
+		return blsSignatures.SignatureToCompressedBytes(sig)
+	}
+	return blsSignatures.SignatureToBytes(sig)
+}
+
+func Serialize(c *DataAvailabilityCertificate) ([]byte, error) {
+	return SerializeWithSignatureEncoding(c, UncompressedSignatures)
+}
 
+// SerializeWithSignatureEncoding is Serialize with the signature in the given encoding. Only
+// UncompressedSignatures produces certs that can be posted on chain.
+func SerializeWithSignatureEncoding(c *DataAvailabilityCertificate, encoding SignatureEncoding) ([]byte, error) {
+	signableFields, err := c.SerializeSignableFields()
+	if err != nil {
+		return nil, err
+	}
 	buf := make([]byte, 0)
-	buf = append(buf, flags)
+	buf = append(buf, c.headerFlags())
 	buf = append(buf, c.KeysetHash[:]...)
-	buf = append(buf, c.SerializeSignableFields()...)
+	buf = append(buf, signableFields...)
 
 	var intData [8]byte
 	binary.BigEndian.PutUint64(intData[:], c.SignersMask)
 	buf = append(buf, intData[:]...)
 
-	return append(buf, blsSignatures.SignatureToBytes(c.Sig)...)
+	return append(buf, encoding.signatureToBytes(c.Sig)...), nil
 }
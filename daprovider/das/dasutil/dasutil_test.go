@@ -0,0 +1,819 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/daprovider"
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func makeSequencerMessage(t testing.TB, maxTimestamp uint64, cert *DataAvailabilityCertificate) []byte {
+	t.Helper()
+	msg := make([]byte, sequencerMsgHeaderLen)
+	binary.BigEndian.PutUint64(msg[8:16], maxTimestamp)
+	return append(msg, serializeTestCert(t, cert)...)
+}
+
+func serializeTestCert(t testing.TB, cert *DataAvailabilityCertificate) []byte {
+	t.Helper()
+	serialized, err := Serialize(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serialized
+}
+
+func signTestCert(t testing.TB, privKey blsSignatures.PrivateKey, cert *DataAvailabilityCertificate) blsSignatures.Signature {
+	t.Helper()
+	fields, err := cert.SerializeSignableFields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := blsSignatures.SignMessage(privKey, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func makeTestCert(t *testing.T, payload []byte, timeout uint64) *DataAvailabilityCertificate {
+	t.Helper()
+	_, privKey, err := blsSignatures.GenerateKeys()
+	Require(t, err)
+	cert := &DataAvailabilityCertificate{
+		KeysetHash:  dastree.Hash([]byte("keyset")),
+		DataHash:    dastree.Hash(payload),
+		Timeout:     timeout,
+		SignersMask: 1,
+		Version:     1,
+	}
+	cert.Sig = signTestCert(t, privKey, cert)
+	return cert
+}
+
+func makeTestKeyset(t *testing.T, numKeys int, assumedHonest uint64) (*DataAvailabilityKeyset, []blsSignatures.PrivateKey) {
+	t.Helper()
+	keyset := &DataAvailabilityKeyset{AssumedHonest: assumedHonest}
+	var privKeys []blsSignatures.PrivateKey
+	for i := 0; i < numKeys; i++ {
+		pubKey, privKey, err := blsSignatures.GenerateKeys()
+		Require(t, err)
+		keyset.PubKeys = append(keyset.PubKeys, pubKey)
+		privKeys = append(privKeys, privKey)
+	}
+	return keyset, privKeys
+}
+
+func TestDeserializeKeysetCollectingErrors(t *testing.T) {
+	keyset, _ := makeTestKeyset(t, 4, 2)
+	badIndex := 2
+
+	// Serialize by hand so that one of the keys can be replaced with garbage.
+	var buf bytes.Buffer
+	Require(t, util.Uint64ToWriter(keyset.AssumedHonest, &buf))
+	Require(t, util.Uint64ToWriter(uint64(len(keyset.PubKeys)), &buf))
+	for i, pk := range keyset.PubKeys {
+		pkBuf := blsSignatures.PublicKeyToBytes(pk)
+		if i == badIndex {
+			pkBuf = []byte{1, 2, 3, 4}
+		}
+		buf.Write([]byte{byte(len(pkBuf) / 256), byte(len(pkBuf) % 256)})
+		buf.Write(pkBuf)
+	}
+	serialized := buf.Bytes()
+
+	if _, err := DeserializeKeyset(bytes.NewReader(serialized), false); err == nil {
+		Fail(t, "expected strict deserialization to fail")
+	}
+
+	parsed, keyErrors, err := DeserializeKeysetCollectingErrors(bytes.NewReader(serialized), false)
+	Require(t, err)
+	if len(keyErrors) != 1 || keyErrors[0].Index != uint64(badIndex) {
+		Fail(t, "expected a single error for key", badIndex, keyErrors)
+	}
+	if len(parsed.PubKeys) != len(keyset.PubKeys) || parsed.AssumedHonest != keyset.AssumedHonest {
+		Fail(t, "unexpected keyset shape", len(parsed.PubKeys), parsed.AssumedHonest)
+	}
+	for i, pk := range parsed.PubKeys {
+		if i == badIndex {
+			continue
+		}
+		if !bytes.Equal(blsSignatures.PublicKeyToBytes(pk), blsSignatures.PublicKeyToBytes(keyset.PubKeys[i])) {
+			Fail(t, "key", i, "doesn't match")
+		}
+	}
+
+	// Truncated input is still a structural error.
+	if _, _, err := DeserializeKeysetCollectingErrors(bytes.NewReader(serialized[:len(serialized)-1]), false); err == nil {
+		Fail(t, "expected truncated keyset to fail")
+	}
+}
+
+// countStrictKeyValidations counts the public keys parsed with strict validation until the test ends.
+func countStrictKeyValidations(t *testing.T) *int {
+	t.Helper()
+	count := new(int)
+	publicKeyFromBytes = func(buf []byte, trustedSource bool) (blsSignatures.PublicKey, error) {
+		if !trustedSource {
+			*count++
+		}
+		return blsSignatures.PublicKeyFromBytes(buf, trustedSource)
+	}
+	t.Cleanup(func() { publicKeyFromBytes = blsSignatures.PublicKeyFromBytes })
+	return count
+}
+
+func serializeTestKeyset(t *testing.T, numKeys int) []byte {
+	t.Helper()
+	keyset, _ := makeTestKeyset(t, numKeys, 1)
+	var buf bytes.Buffer
+	Require(t, keyset.Serialize(&buf))
+	return buf.Bytes()
+}
+
+func TestDeserializeKeysetBytesValidatesOnce(t *testing.T) {
+	validations := countStrictKeyValidations(t)
+	first := serializeTestKeyset(t, 3)
+	second := serializeTestKeyset(t, 2)
+	hitsBefore := keysetValidationCacheCounter("", "hits").Snapshot().Count()
+	missesBefore := keysetValidationCacheCounter("", "misses").Snapshot().Count()
+
+	// Deserializing without validation doesn't mark the keyset as valid.
+	_, err := DeserializeKeysetBytes(first, true)
+	Require(t, err)
+	if *validations != 0 {
+		Fail(t, "unexpected strict validations", *validations)
+	}
+
+	for i := 0; i < 3; i++ {
+		keyset, err := DeserializeKeysetBytes(first, false)
+		Require(t, err)
+		if len(keyset.PubKeys) != 3 {
+			Fail(t, "unexpected number of keys", len(keyset.PubKeys))
+		}
+	}
+	if *validations != 3 {
+		Fail(t, "expected the keys of the first keyset to be validated once, got", *validations, "validations")
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := DeserializeKeysetBytes(second, false)
+		Require(t, err)
+	}
+	if *validations != 5 {
+		Fail(t, "expected the keys of each distinct keyset to be validated once, got", *validations, "validations")
+	}
+
+	// Keysets failing validation aren't remembered.
+	invalid := append([]byte{}, second...)
+	invalid[len(invalid)-1] ^= 0xff
+	for i := 0; i < 2; i++ {
+		if _, err := DeserializeKeysetBytes(invalid, false); err == nil {
+			Fail(t, "expected invalid keyset to fail validation")
+		}
+	}
+	if *validations != 9 {
+		Fail(t, "expected the invalid keyset to be validated every time, got", *validations, "validations")
+	}
+
+	hits := keysetValidationCacheCounter("", "hits").Snapshot().Count() - hitsBefore
+	misses := keysetValidationCacheCounter("", "misses").Snapshot().Count() - missesBefore
+	if hits != 4 || misses != 4 {
+		Fail(t, "expected 4 validation cache hits and 4 misses, got", hits, "hits and", misses, "misses")
+	}
+
+	// Cache events are counted in the namespace of the recovery, though the cache is shared.
+	_, err = deserializeKeysetBytes(first, false, true, "keyset_cache_test")
+	Require(t, err)
+	if count := keysetValidationCacheCounter("keyset_cache_test", "hits").Snapshot().Count(); count != 1 {
+		Fail(t, "expected 1 validation cache hit in the namespace, got", count)
+	}
+}
+
+func BenchmarkDeserializeKeysetStrict(b *testing.B) {
+	keyset := &DataAvailabilityKeyset{AssumedHonest: 1}
+	for i := 0; i < 16; i++ {
+		pubKey, _, err := blsSignatures.GenerateKeys()
+		if err != nil {
+			b.Fatal(err)
+		}
+		keyset.PubKeys = append(keyset.PubKeys, pubKey)
+	}
+	var buf bytes.Buffer
+	if err := keyset.Serialize(&buf); err != nil {
+		b.Fatal(err)
+	}
+	serialized := buf.Bytes()
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := DeserializeKeyset(bytes.NewReader(serialized), false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := DeserializeKeysetBytes(serialized, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestDeserializeDASCertFromMessage(t *testing.T) {
+	cert := makeTestCert(t, []byte("payload"), 12345)
+	msg := makeSequencerMessage(t, 678, cert)
+
+	parsed, maxTimestamp, err := DeserializeDASCertFromMessage(msg)
+	Require(t, err)
+	if maxTimestamp != 678 {
+		Fail(t, "unexpected max timestamp", maxTimestamp)
+	}
+	if parsed.KeysetHash != cert.KeysetHash || parsed.DataHash != cert.DataHash ||
+		parsed.Timeout != cert.Timeout || parsed.SignersMask != cert.SignersMask || parsed.Version != cert.Version {
+		Fail(t, "parsed cert doesn't match", parsed, cert)
+	}
+}
+
+func TestDeserializeDASCertsFromMessages(t *testing.T) {
+	first := makeTestCert(t, []byte("first payload"), 12345)
+	second := makeTestCert(t, []byte("second payload"), 23456)
+	truncated := makeSequencerMessage(t, 3, second)
+	msgs := [][]byte{
+		makeSequencerMessage(t, 1, first),
+		make([]byte, sequencerMsgHeaderLen),
+		makeSequencerMessage(t, 2, second),
+		truncated[:len(truncated)-10],
+	}
+
+	parsed, parseErrors := DeserializeDASCertsFromMessages(msgs)
+	if len(parsed) != 2 || len(parseErrors) != 2 {
+		Fail(t, "expected 2 parsed certs and 2 errors, got", len(parsed), len(parseErrors))
+	}
+	for i, expected := range []struct {
+		index        int
+		cert         *DataAvailabilityCertificate
+		maxTimestamp uint64
+	}{
+		{0, first, 1},
+		{2, second, 2},
+	} {
+		got := parsed[i]
+		if got.Index != expected.index || got.MaxTimestamp != expected.maxTimestamp || got.Cert.DataHash != expected.cert.DataHash {
+			Fail(t, "unexpected parsed cert", i, got.Index, got.MaxTimestamp, got.Cert)
+		}
+	}
+	for i, index := range []int{1, 3} {
+		parseErr := parseErrors[i]
+		if parseErr.Index != index || parseErr.Err == nil {
+			Fail(t, "unexpected parse error", i, parseErr)
+		}
+		// The error matches what parsing the message on its own returns.
+		if _, _, err := DeserializeDASCertFromMessage(msgs[index]); err == nil || err.Error() != parseErr.Err.Error() {
+			Fail(t, "expected the error of parsing message", index, "on its own, got", parseErr.Err)
+		}
+	}
+
+	parsed, parseErrors = DeserializeDASCertsFromMessages(nil)
+	if len(parsed) != 0 || parseErrors != nil {
+		Fail(t, "expected no results for no messages")
+	}
+}
+
+func TestDeserializeDASCertFromShortMessage(t *testing.T) {
+	cert := makeTestCert(t, []byte("payload"), 12345)
+	msg := makeSequencerMessage(t, 678, cert)
+
+	for _, length := range []int{0, 16, sequencerMsgHeaderLen, sequencerMsgHeaderLen + 10} {
+		if _, _, err := DeserializeDASCertFromMessage(msg[:length]); err == nil {
+			Fail(t, "expected error for message of length", length)
+		}
+	}
+}
+
+func TestCertSignatureEncodings(t *testing.T) {
+	for _, version := range []uint8{0, 1} {
+		cert := makeTestCert(t, []byte("payload"), 12345)
+		cert.Version = version
+		uncompressed, err := SerializeWithSignatureEncoding(cert, UncompressedSignatures)
+		Require(t, err)
+		if !bytes.Equal(uncompressed, serializeTestCert(t, cert)) {
+			Fail(t, "uncompressed signatures aren't the default encoding")
+		}
+		compressed, err := SerializeWithSignatureEncoding(cert, CompressedSignatures)
+		Require(t, err)
+		if len(uncompressed)-len(compressed) != blsSignatures.SignatureLength-blsSignatures.CompressedSignatureLength {
+			Fail(t, "unexpected compressed cert length", len(compressed), "uncompressed", len(uncompressed))
+		}
+
+		for encoding, serialized := range map[SignatureEncoding][]byte{UncompressedSignatures: uncompressed, CompressedSignatures: compressed} {
+			parsed, err := DeserializeDASCertWithSignatureEncoding(serialized, encoding)
+			Require(t, err)
+			if !bytes.Equal(serializeTestCert(t, parsed), serializeTestCert(t, cert)) {
+				Fail(t, "cert with signature encoding", encoding, "doesn't round trip", parsed, cert)
+			}
+		}
+		if _, err := DeserializeDASCertWithSignatureEncoding(uncompressed, CompressedSignatures); err == nil {
+			Fail(t, "expected cert with an uncompressed signature to be rejected as compressed")
+		}
+		if _, err := DeserializeDASCertWithSignatureEncoding(compressed, UncompressedSignatures); err == nil {
+			Fail(t, "expected cert with a compressed signature to be rejected as uncompressed")
+		}
+		if _, err := DeserializeDASCertWithSignatureEncoding(uncompressed, SignatureEncoding(2)); err == nil {
+			Fail(t, "expected unknown signature encoding to be rejected")
+		}
+	}
+}
+
+func TestCertHeaderFlagVersionCoupling(t *testing.T) {
+	cert := makeTestCert(t, []byte("payload"), 12345)
+
+	// serializeWithHeader lays out the cert by hand so that the header flag and the version byte
+	// can be chosen independently.
+	serializeWithHeader := func(treeFlag bool, version uint8) []byte {
+		flags := daprovider.DASMessageHeaderFlag
+		if treeFlag {
+			flags |= daprovider.TreeDASMessageHeaderFlag
+		}
+		buf := []byte{flags}
+		buf = append(buf, cert.KeysetHash[:]...)
+		buf = append(buf, cert.DataHash[:]...)
+		buf = binary.BigEndian.AppendUint64(buf, cert.Timeout)
+		if treeFlag {
+			buf = append(buf, version)
+		}
+		buf = binary.BigEndian.AppendUint64(buf, cert.SignersMask)
+		return append(buf, blsSignatures.SignatureToBytes(cert.Sig)...)
+	}
+
+	for _, tc := range []struct {
+		treeFlag bool
+		version  uint8
+		valid    bool
+	}{
+		{treeFlag: false, version: 0, valid: true},
+		{treeFlag: true, version: 1, valid: true},
+		{treeFlag: true, version: 2, valid: true},
+		{treeFlag: true, version: 0, valid: false},
+	} {
+		serialized := serializeWithHeader(tc.treeFlag, tc.version)
+		parsed, err := DeserializeDASCertFrom(bytes.NewReader(serialized))
+		if !tc.valid {
+			if err == nil {
+				Fail(t, "expected error for tree flag", tc.treeFlag, "version", tc.version)
+			}
+			continue
+		}
+		Require(t, err, "tree flag", tc.treeFlag, "version", tc.version)
+		if parsed.Version != tc.version {
+			Fail(t, "unexpected version", parsed.Version, "expected", tc.version)
+		}
+		if !bytes.Equal(serializeTestCert(t, parsed), serialized) {
+			Fail(t, "cert doesn't round-trip for tree flag", tc.treeFlag, "version", tc.version)
+		}
+	}
+
+	// Serialize sets the tree flag exactly when the version is non-zero.
+	for _, version := range []uint8{0, 1} {
+		cert.Version = version
+		header := serializeTestCert(t, cert)[0]
+		if daprovider.IsTreeDASMessageHeaderByte(header) != (version != 0) {
+			Fail(t, "unexpected header", header, "for version", version)
+		}
+	}
+}
+
+func TestCertClone(t *testing.T) {
+	cert := makeTestCert(t, []byte("payload"), 12345)
+	serialized := serializeTestCert(t, cert)
+
+	clone := cert.Clone()
+	if !bytes.Equal(serializeTestCert(t, clone), serialized) {
+		Fail(t, "clone doesn't serialize like the original")
+	}
+
+	other := makeTestCert(t, []byte("other payload"), 54321)
+	*clone.Sig = *other.Sig
+	clone.KeysetHash[0] ^= 0xff
+	clone.DataHash = other.DataHash
+	clone.Timeout++
+	clone.SignersMask = 3
+	clone.Version = 0
+	if !bytes.Equal(serializeTestCert(t, cert), serialized) {
+		Fail(t, "mutating the clone changed the original")
+	}
+
+	unsigned := &DataAvailabilityCertificate{Timeout: 1}
+	if unsigned.Clone().Sig != nil {
+		Fail(t, "clone of an unsigned cert has a signature")
+	}
+}
+
+func TestNumClaimedSigners(t *testing.T) {
+	for _, tc := range []struct {
+		mask    uint64
+		signers int
+	}{
+		{0, 0},
+		{1, 1},
+		{0b1011, 3},
+		{1 << 63, 1},
+		{math.MaxUint64, 64},
+	} {
+		cert := &DataAvailabilityCertificate{SignersMask: tc.mask}
+		if got := cert.NumClaimedSigners(); got != tc.signers {
+			Fail(t, "mask", tc.mask, "expected", tc.signers, "signers, got", got)
+		}
+	}
+}
+
+func TestVerifyCertSignature(t *testing.T) {
+	keyset, privKeys := makeTestKeyset(t, 3, 2)
+	keysetHash, err := keyset.Hash()
+	Require(t, err)
+	cert := &DataAvailabilityCertificate{
+		KeysetHash:  keysetHash,
+		DataHash:    dastree.Hash([]byte("payload")),
+		Timeout:     12345,
+		SignersMask: 0b011,
+		Version:     1,
+	}
+	var sigs []blsSignatures.Signature
+	for _, privKey := range privKeys[:2] {
+		sigs = append(sigs, signTestCert(t, privKey, cert))
+	}
+	cert.Sig = blsSignatures.AggregateSignatures(sigs)
+	Require(t, VerifyCertSignature(cert, keyset))
+
+	tampered := *cert
+	tampered.Sig = sigs[0]
+	if err := VerifyCertSignature(&tampered, keyset); err == nil {
+		Fail(t, "expected tampered signature to be rejected")
+	}
+
+	tampered = *cert
+	tampered.Timeout++
+	if err := VerifyCertSignature(&tampered, keyset); err == nil {
+		Fail(t, "expected signature over different fields to be rejected")
+	}
+
+	otherKeyset, _ := makeTestKeyset(t, 3, 2)
+	if err := VerifyCertSignature(cert, otherKeyset); err == nil {
+		Fail(t, "expected keyset hash mismatch to be rejected")
+	}
+}
+
+func TestReSignCert(t *testing.T) {
+	oldKeyset, oldPrivKeys := makeTestKeyset(t, 1, 1)
+	oldKeysetHash, err := oldKeyset.Hash()
+	Require(t, err)
+	cert := &DataAvailabilityCertificate{
+		KeysetHash:  oldKeysetHash,
+		DataHash:    dastree.Hash([]byte("payload")),
+		Timeout:     12345,
+		SignersMask: 1,
+		Version:     1,
+	}
+	cert.Sig = signTestCert(t, oldPrivKeys[0], cert)
+
+	newKeyset, newPrivKeys := makeTestKeyset(t, 3, 2)
+	newKeysetHash, err := newKeyset.Hash()
+	Require(t, err)
+	toSign := cert.Clone()
+	toSign.KeysetHash = newKeysetHash
+	toSign.SignersMask = 0b110
+	var sigs []blsSignatures.Signature
+	for _, privKey := range newPrivKeys[1:] {
+		sigs = append(sigs, signTestCert(t, privKey, toSign))
+	}
+	newSig := blsSignatures.AggregateSignatures(sigs)
+
+	resigned, err := ReSignCert(cert, newKeyset, 0b110, newSig)
+	Require(t, err)
+	Require(t, VerifyCertSignature(resigned, newKeyset))
+	if resigned.DataHash != cert.DataHash || resigned.Timeout != cert.Timeout || resigned.Version != cert.Version {
+		Fail(t, "re-signed cert commits to different data", resigned)
+	}
+	if resigned.KeysetHash != newKeysetHash || resigned.SignersMask != 0b110 {
+		Fail(t, "re-signed cert doesn't reference the new keyset", resigned)
+	}
+	// The original cert is left as it was.
+	Require(t, VerifyCertSignature(cert, oldKeyset))
+
+	if _, err := ReSignCert(cert, newKeyset, 0b110, cert.Sig); err == nil {
+		Fail(t, "expected the old signature not to verify against the new keyset")
+	}
+	if _, err := ReSignCert(cert, newKeyset, 0b011, newSig); err == nil {
+		Fail(t, "expected a signature by other signers than claimed to be rejected")
+	}
+}
+
+func TestVerifySignatureRejectsMaskBeyondKeyset(t *testing.T) {
+	keyset, privKeys := makeTestKeyset(t, 3, 2)
+	data := []byte("signed fields")
+	var sigs []blsSignatures.Signature
+	for _, privKey := range privKeys[:2] {
+		sig, err := blsSignatures.SignMessage(privKey, data)
+		Require(t, err)
+		sigs = append(sigs, sig)
+	}
+	sig := blsSignatures.AggregateSignatures(sigs)
+	Require(t, keyset.VerifySignature(0b011, data, sig))
+
+	// The signature of the real signers is valid, but the mask also claims a fourth key.
+	for _, mask := range []uint64{0b1011, 1<<63 | 0b011} {
+		if err := keyset.VerifySignature(mask, data, sig); !errors.Is(err, ErrMalformedSignersMask) {
+			Fail(t, "expected mask", mask, "to be rejected as malformed, got", err)
+		}
+	}
+}
+
+func TestKeysetHashWithMaxSize(t *testing.T) {
+	serializedSize := func(keyset *DataAvailabilityKeyset) int {
+		var buf bytes.Buffer
+		Require(t, keyset.Serialize(&buf))
+		return buf.Len()
+	}
+	smaller, _ := makeTestKeyset(t, 3, 2)
+	larger, _ := makeTestKeyset(t, 4, 2)
+	for _, maxSize := range []int{serializedSize(smaller), serializedSize(larger)} {
+		for _, keyset := range []*DataAvailabilityKeyset{smaller, larger} {
+			size := serializedSize(keyset)
+			hash, err := keyset.HashWithMaxSize(maxSize)
+			if size > maxSize {
+				if err == nil {
+					Fail(t, "expected keyset of", size, "bytes to exceed the limit of", maxSize)
+				}
+				continue
+			}
+			Require(t, err)
+			defaultHash, err := keyset.Hash()
+			Require(t, err)
+			if hash != defaultHash {
+				Fail(t, "keyset hash depends on the size limit")
+			}
+		}
+	}
+}
+
+func TestWeightedKeysetQuorum(t *testing.T) {
+	keyset, privKeys := makeTestKeyset(t, 3, 2)
+	message := []byte("signable fields")
+	signWith := func(signers ...int) (uint64, blsSignatures.Signature) {
+		var mask uint64
+		var sigs []blsSignatures.Signature
+		for _, i := range signers {
+			mask |= 1 << i
+			sig, err := blsSignatures.SignMessage(privKeys[i], message)
+			Require(t, err)
+			sigs = append(sigs, sig)
+		}
+		return mask, blsSignatures.AggregateSignatures(sigs)
+	}
+
+	// Unweighted, any two of the three members are enough.
+	mask, sig := signWith(1, 2)
+	Require(t, keyset.VerifySignature(mask, message, sig))
+
+	// The first member counts double, so it's enough together with either other member, but the
+	// other two members without it aren't.
+	keyset.Weights = []uint64{2, 1, 1}
+	for _, tc := range []struct {
+		signers []int
+		quorum  bool
+	}{
+		{[]int{0, 1}, true},
+		{[]int{0, 2}, true},
+		{[]int{0, 1, 2}, true},
+		{[]int{1, 2}, false},
+		{[]int{0}, false},
+	} {
+		mask, sig := signWith(tc.signers...)
+		err := keyset.VerifySignature(mask, message, sig)
+		if tc.quorum && err != nil {
+			Fail(t, "signers", tc.signers, "should reach quorum", err)
+		}
+		if !tc.quorum && err == nil {
+			Fail(t, "signers", tc.signers, "shouldn't reach quorum")
+		}
+	}
+
+	var buf bytes.Buffer
+	Require(t, keyset.Serialize(&buf))
+	parsed, err := DeserializeKeyset(bytes.NewReader(buf.Bytes()), false)
+	Require(t, err)
+	if len(parsed.Weights) != 3 || parsed.Weights[0] != 2 || parsed.Weights[1] != 1 || parsed.AssumedHonest != 2 {
+		Fail(t, "weighted keyset doesn't round-trip", parsed.Weights, parsed.AssumedHonest)
+	}
+
+	// Unweighted keysets keep the original format.
+	keyset.Weights = nil
+	buf.Reset()
+	Require(t, keyset.Serialize(&buf))
+	if binary.BigEndian.Uint64(buf.Bytes()[:8]) != keyset.AssumedHonest {
+		Fail(t, "unweighted keyset doesn't start with AssumedHonest")
+	}
+	parsed, err = DeserializeKeyset(bytes.NewReader(buf.Bytes()), false)
+	Require(t, err)
+	if parsed.Weights != nil {
+		Fail(t, "unweighted keyset parsed with weights", parsed.Weights)
+	}
+}
+
+type testDASWriter struct {
+	t    *testing.T
+	fail bool
+}
+
+func (w *testDASWriter) Store(ctx context.Context, message []byte, timeout uint64) (*DataAvailabilityCertificate, error) {
+	if w.fail {
+		return nil, ErrBatchToDasFailed
+	}
+	return makeTestCert(w.t, message, timeout), nil
+}
+
+func (w *testDASWriter) String() string {
+	return "testDASWriter"
+}
+
+func TestStoreWithDataHash(t *testing.T) {
+	ctx := context.Background()
+	message := []byte("batch data")
+
+	writer := NewWriterForDAS(&testDASWriter{t: t})
+	serialized, dataHash, err := writer.StoreWithDataHash(ctx, message, 12345, false)
+	Require(t, err)
+	if dataHash != dastree.Hash(message) {
+		Fail(t, "unexpected data hash", dataHash)
+	}
+	cert, err := DeserializeDASCertFrom(bytes.NewReader(serialized))
+	Require(t, err)
+	if cert.DataHash != dataHash {
+		Fail(t, "data hash doesn't match the cert", cert.DataHash, dataHash)
+	}
+
+	writer = NewWriterForDAS(&testDASWriter{t: t, fail: true})
+	serialized, dataHash, err = writer.StoreWithDataHash(ctx, message, 12345, false)
+	Require(t, err)
+	if !bytes.Equal(serialized, message) {
+		Fail(t, "expected fallback to return the message")
+	}
+	if dataHash != dastree.Hash(message) {
+		Fail(t, "unexpected data hash for fallback", dataHash)
+	}
+	if _, _, err := writer.StoreWithDataHash(ctx, message, 12345, true); err == nil {
+		Fail(t, "expected failure with fallback disabled")
+	}
+}
+
+func TestDASProviderResolvesFromRegistry(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("batch served by the registered DAS provider"), 1)
+	registry := daprovider.NewRegistry()
+	Require(t, registry.Register(DASProviderName, NewDASProviderFactory(r.reader, r.fetcher, &testDASWriter{t: t})))
+
+	reader, writer, err := registry.Resolve(ctx, DASProviderName)
+	Require(t, err)
+	if !reader.IsValidHeaderByte(ctx, r.msg[sequencerMsgHeaderLen]) {
+		Fail(t, "DAS provider doesn't accept the DAS header byte", r.msg[sequencerMsgHeaderLen])
+	}
+	payload, _, err := reader.RecoverPayloadFromBatch(ctx, 1, common.Hash{}, r.msg, nil, true)
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) {
+		Fail(t, "DAS provider recovered wrong payload", payload)
+	}
+	if writer == nil {
+		Fail(t, "expected the DAS provider to have a writer")
+	}
+	serialized, err := writer.Store(ctx, r.payload, 12345, true)
+	Require(t, err)
+	if _, err := DeserializeDASCertFrom(bytes.NewReader(serialized)); err != nil {
+		Fail(t, "DAS provider didn't store a cert, got", err)
+	}
+
+	// Without a DAS writer the provider is read-only.
+	registry = daprovider.NewRegistry()
+	Require(t, registry.Register(DASProviderName, NewDASProviderFactory(r.reader, r.fetcher, nil)))
+	_, writer, err = registry.Resolve(ctx, DASProviderName)
+	Require(t, err)
+	if writer != nil {
+		Fail(t, "expected no writer without a DAS writer, got", writer)
+	}
+}
+
+func TestWriterVerifiesStores(t *testing.T) {
+	ctx := context.Background()
+	message := []byte("batch data")
+	reader := newTestDASReader()
+	writer := NewWriterForDAS(&testDASWriter{t: t})
+	writer.VerifyStoresWith(reader)
+
+	// The writer claims to have stored the message, but the reader can't find it.
+	if _, err := writer.Store(ctx, message, 12345, false); !errors.Is(err, ErrStoreNotRetrievable) {
+		Fail(t, "expected a store that can't be read back to fail, got", err)
+	}
+	reader.data[dastree.Hash(message)] = []byte("other data")
+	if _, err := writer.Store(ctx, message, 12345, false); !errors.Is(err, ErrStoreNotRetrievable) {
+		Fail(t, "expected a store reading back other data to fail, got", err)
+	}
+	reader.data[dastree.Hash(message)] = message
+	serialized, err := writer.Store(ctx, message, 12345, false)
+	Require(t, err)
+	if _, err := DeserializeDASCertFrom(bytes.NewReader(serialized)); err != nil {
+		Fail(t, "expected a cert once the store can be read back, got", err)
+	}
+
+	// Messages falling back to on-chain storage aren't read back.
+	writer = NewWriterForDAS(&testDASWriter{t: t, fail: true})
+	writer.VerifyStoresWith(newTestDASReader())
+	serialized, err = writer.Store(ctx, message, 12345, false)
+	Require(t, err)
+	if !bytes.Equal(serialized, message) {
+		Fail(t, "expected fallback to return the message")
+	}
+}
+
+func TestKeysetQuorumSize(t *testing.T) {
+	message := []byte("signable fields")
+	for _, tc := range []struct {
+		numKeys         int
+		assumedHonest   uint64
+		maxFailures     uint64
+		quorum          uint64
+		verifiesWithAll bool
+	}{
+		{numKeys: 1, assumedHonest: 1, maxFailures: 0, quorum: 1, verifiesWithAll: true},
+		{numKeys: 3, assumedHonest: 1, maxFailures: 0, quorum: 3, verifiesWithAll: true},
+		{numKeys: 3, assumedHonest: 2, maxFailures: 1, quorum: 2, verifiesWithAll: true},
+		{numKeys: 5, assumedHonest: 2, maxFailures: 1, quorum: 4, verifiesWithAll: true},
+		{numKeys: 5, assumedHonest: 5, maxFailures: 4, quorum: 1, verifiesWithAll: true},
+		{numKeys: 4, assumedHonest: 10, maxFailures: 4, quorum: 0, verifiesWithAll: true},
+		{numKeys: 3, assumedHonest: 0, maxFailures: 0, quorum: 4, verifiesWithAll: false},
+	} {
+		keyset, privKeys := makeTestKeyset(t, tc.numKeys, tc.assumedHonest)
+		if got := keyset.MaxTolerableFailures(); got != tc.maxFailures {
+			Fail(t, "keyset", tc.numKeys, tc.assumedHonest, "expected max tolerable failures", tc.maxFailures, "got", got)
+		}
+		if got := keyset.QuorumSize(); got != tc.quorum {
+			Fail(t, "keyset", tc.numKeys, tc.assumedHonest, "expected quorum size", tc.quorum, "got", got)
+		}
+
+		// Check the numbers against VerifySignature, with the first signers members signing.
+		verifies := func(signers int) bool {
+			var mask uint64
+			sigs := []blsSignatures.Signature{}
+			for i := 0; i < signers; i++ {
+				mask |= 1 << i
+				sig, err := blsSignatures.SignMessage(privKeys[i], message)
+				Require(t, err)
+				sigs = append(sigs, sig)
+			}
+			return keyset.VerifySignature(mask, message, blsSignatures.AggregateSignatures(sigs)) == nil
+		}
+		if !tc.verifiesWithAll {
+			if verifies(tc.numKeys) {
+				Fail(t, "keyset", tc.numKeys, tc.assumedHonest, "shouldn't verify any cert")
+			}
+			continue
+		}
+		// #nosec G115
+		quorum := int(tc.quorum)
+		if quorum > 0 && !verifies(quorum) {
+			Fail(t, "keyset", tc.numKeys, tc.assumedHonest, "should verify with", quorum, "signers")
+		}
+		if quorum > 1 && verifies(quorum-1) {
+			Fail(t, "keyset", tc.numKeys, tc.assumedHonest, "shouldn't verify with", quorum-1, "signers")
+		}
+	}
+
+	// Weighted keysets count weight rather than members.
+	keyset, _ := makeTestKeyset(t, 3, 3)
+	keyset.Weights = []uint64{3, 1, 1}
+	if keyset.MaxTolerableFailures() != 2 || keyset.QuorumSize() != 3 {
+		Fail(t, "unexpected weighted quorum", keyset.MaxTolerableFailures(), keyset.QuorumSize())
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
@@ -0,0 +1,78 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrFallbackBuffered is returned by a writerForDAS with fallback coalescing enabled when a message
+// couldn't be stored in the DAS and was buffered to be posted on chain with the other fallbacks of
+// its group. The buffered messages are returned by FlushFallbacks.
+var ErrFallbackBuffered = errors.New("DAS store failed, message buffered for on-chain fallback")
+
+type FallbackCoalescingConfig struct {
+	// Window is how long after the first fallback of a group further fallbacks are added to it.
+	Window time.Duration
+	// MaxMessages flushes a group early once it contains this many messages (0 for no limit).
+	MaxMessages int
+}
+
+// fallbackCoalescer groups consecutive on-chain fallbacks so they can be posted together instead
+// of one L1 transaction each while the DAS is down.
+type fallbackCoalescer struct {
+	config FallbackCoalescingConfig
+	now    func() time.Time
+
+	mutex   sync.Mutex
+	pending [][]byte
+	firstAt time.Time
+}
+
+func newFallbackCoalescer(config FallbackCoalescingConfig) *fallbackCoalescer {
+	return &fallbackCoalescer{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+func (c *fallbackCoalescer) add(message []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.pending) == 0 {
+		c.firstAt = c.now()
+	}
+	c.pending = append(c.pending, message)
+}
+
+// ready returns whether the pending group is complete, either because its window has passed or
+// because it has reached the maximum size. Must be called with the mutex held.
+func (c *fallbackCoalescer) ready() bool {
+	if len(c.pending) == 0 {
+		return false
+	}
+	if c.config.MaxMessages > 0 && len(c.pending) >= c.config.MaxMessages {
+		return true
+	}
+	return c.now().Sub(c.firstAt) >= c.config.Window
+}
+
+func (c *fallbackCoalescer) flush(force bool) [][]byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !force && !c.ready() {
+		return nil
+	}
+	group := c.pending
+	c.pending = nil
+	return group
+}
+
+func (c *fallbackCoalescer) pendingCount() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.pending)
+}
@@ -0,0 +1,84 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWriterCoalescesFallbacks(t *testing.T) {
+	ctx := context.Background()
+	dasWriter := &testDASWriter{t: t, fail: true}
+	writer := NewWriterForDASWithFallbackCoalescing(dasWriter, FallbackCoalescingConfig{Window: time.Minute, MaxMessages: 10})
+	now := time.Now()
+	writer.coalescer.now = func() time.Time { return now }
+
+	var messages [][]byte
+	for i := 0; i < 3; i++ {
+		message := []byte{byte(i)}
+		messages = append(messages, message)
+		if _, err := writer.Store(ctx, message, 12345, false); !errors.Is(err, ErrFallbackBuffered) {
+			Fail(t, "expected fallback to be buffered, got", err)
+		}
+		now = now.Add(time.Second)
+		if group := writer.FlushFallbacks(false); group != nil {
+			Fail(t, "group flushed before its window passed", len(group))
+		}
+	}
+	if writer.PendingFallbacks() != len(messages) {
+		Fail(t, "unexpected number of pending fallbacks", writer.PendingFallbacks())
+	}
+
+	now = now.Add(time.Minute)
+	group := writer.FlushFallbacks(false)
+	if len(group) != len(messages) {
+		Fail(t, "expected all fallbacks to be grouped, got", len(group))
+	}
+	for i := range group {
+		if !bytes.Equal(group[i], messages[i]) {
+			Fail(t, "fallback", i, "out of order", group[i])
+		}
+	}
+	if writer.PendingFallbacks() != 0 || writer.FlushFallbacks(true) != nil {
+		Fail(t, "expected no pending fallbacks after flush")
+	}
+
+	// Disabling the fallback still fails without buffering anything.
+	if _, err := writer.Store(ctx, []byte("x"), 12345, true); err == nil || errors.Is(err, ErrFallbackBuffered) {
+		Fail(t, "expected store to fail with fallback disabled, got", err)
+	}
+	if writer.PendingFallbacks() != 0 {
+		Fail(t, "message buffered even though the fallback is disabled")
+	}
+}
+
+func TestWriterFlushesFullFallbackGroup(t *testing.T) {
+	ctx := context.Background()
+	writer := NewWriterForDASWithFallbackCoalescing(&testDASWriter{t: t, fail: true}, FallbackCoalescingConfig{Window: time.Hour, MaxMessages: 2})
+	for i := 0; i < 3; i++ {
+		if _, err := writer.Store(ctx, []byte{byte(i)}, 12345, false); !errors.Is(err, ErrFallbackBuffered) {
+			Fail(t, "expected fallback to be buffered, got", err)
+		}
+		if i == 1 {
+			if group := writer.FlushFallbacks(false); len(group) != 2 {
+				Fail(t, "expected a full group to be flushed, got", len(group))
+			}
+		}
+	}
+	if group := writer.FlushFallbacks(true); len(group) != 1 {
+		Fail(t, "expected forced flush of the partial group, got", len(group))
+	}
+
+	// Without coalescing the message is returned to be posted immediately.
+	plain := NewWriterForDAS(&testDASWriter{t: t, fail: true})
+	serialized, err := plain.Store(ctx, []byte("y"), 12345, false)
+	Require(t, err)
+	if !bytes.Equal(serialized, []byte("y")) || plain.FlushFallbacks(true) != nil {
+		Fail(t, "expected message to be returned for immediate posting")
+	}
+}
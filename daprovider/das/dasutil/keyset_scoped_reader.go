@@ -0,0 +1,84 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// KeysetAwareDASReader is implemented by DASReaders whose data depends on the keyset that signed
+// the cert being recovered. Recovery reads the payload through the reader for the cert's keyset.
+type KeysetAwareDASReader interface {
+	DASReader
+	ReaderForKeyset(keysetHash common.Hash) DASReader
+}
+
+// KeysetScopedDASReader routes reads to the store registered for the keyset of the cert being
+// recovered, for deployments where each committee has its own store. Reads for keysets without a
+// registered store, and reads made without a cert, go to the default reader.
+type KeysetScopedDASReader struct {
+	defaultReader DASReader
+
+	mutex   sync.RWMutex
+	readers map[common.Hash]DASReader
+}
+
+func NewKeysetScopedDASReader(defaultReader DASReader) *KeysetScopedDASReader {
+	return &KeysetScopedDASReader{
+		defaultReader: defaultReader,
+		readers:       make(map[common.Hash]DASReader),
+	}
+}
+
+// Register sets the reader for data signed by the keyset with the given hash.
+func (r *KeysetScopedDASReader) Register(keysetHash common.Hash, reader DASReader) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.readers[keysetHash] = reader
+}
+
+func (r *KeysetScopedDASReader) ReaderForKeyset(keysetHash common.Hash) DASReader {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if reader, ok := r.readers[keysetHash]; ok {
+		return reader
+	}
+	return r.defaultReader
+}
+
+func (r *KeysetScopedDASReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	return r.defaultReader.GetByHash(ctx, hash)
+}
+
+func (r *KeysetScopedDASReader) ExpirationPolicy(ctx context.Context) (ExpirationPolicy, error) {
+	return r.defaultReader.ExpirationPolicy(ctx)
+}
+
+// ExpirationPolicyForKeyset returns the expiration policy of the reader of data signed by the keyset
+// with the given hash, as ExpirationPolicy only reports the policy of the default reader.
+func (r *KeysetScopedDASReader) ExpirationPolicyForKeyset(ctx context.Context, keysetHash common.Hash) (ExpirationPolicy, error) {
+	return r.ReaderForKeyset(keysetHash).ExpirationPolicy(ctx)
+}
+
+// ExpirationPolicies returns the expiration policies of the readers of all registered keysets, so
+// that operators can see how long the data of each committee is kept.
+func (r *KeysetScopedDASReader) ExpirationPolicies(ctx context.Context) (map[common.Hash]ExpirationPolicy, error) {
+	r.mutex.RLock()
+	readers := maps.Clone(r.readers)
+	r.mutex.RUnlock()
+	policies := make(map[common.Hash]ExpirationPolicy, len(readers))
+	for keysetHash, reader := range readers {
+		policy, err := reader.ExpirationPolicy(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get expiration policy of keyset %v: %w", keysetHash, err)
+		}
+		policies[keysetHash] = policy
+	}
+	return policies, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestKeysetScopedDASReaderRouting(t *testing.T) {
+	ctx := context.Background()
+	// Each recovery is signed by its own keyset, with the payload in that committee's store.
+	first := newTestRecovery(t, []byte("first committee's batch"), 1)
+	second := newTestRecovery(t, []byte("second committee's batch"), 1)
+	fetcher := &testKeysetFetcher{keysets: map[common.Hash][]byte{
+		first.cert.KeysetHash:  first.keysetBytes,
+		second.cert.KeysetHash: second.keysetBytes,
+	}}
+
+	defaultReader := newTestDASReader()
+	scoped := NewKeysetScopedDASReader(defaultReader)
+	scoped.Register(first.cert.KeysetHash, first.reader)
+	scoped.Register(second.cert.KeysetHash, second.reader)
+
+	for _, r := range []*testRecovery{first, second} {
+		payload, _, err := RecoverPayloadFromDasBatch(ctx, 1, r.msg, scoped, fetcher, nil, true)
+		Require(t, err)
+		if !bytes.Equal(payload, r.payload) {
+			Fail(t, "recovered wrong payload", string(payload))
+		}
+		if len(r.reader.calls) != 1 || r.reader.calls[0] != r.cert.DataHash {
+			Fail(t, "expected the payload to be read from the keyset's store, got calls", r.reader.calls)
+		}
+	}
+	if len(defaultReader.calls) != 0 {
+		Fail(t, "default reader shouldn't have been used", defaultReader.calls)
+	}
+
+	// Keysets without a registered store fall back to the default reader.
+	unregistered := newTestRecovery(t, []byte("unregistered committee's batch"), 1)
+	defaultReader.data[unregistered.cert.DataHash] = unregistered.payload
+	payload, _, err := RecoverPayloadFromDasBatch(ctx, 1, unregistered.msg, scoped, unregistered.fetcher, nil, true)
+	Require(t, err)
+	if !bytes.Equal(payload, unregistered.payload) || len(defaultReader.calls) != 1 {
+		Fail(t, "expected the default reader to serve an unregistered keyset")
+	}
+}
+
+func TestKeysetScopedDASReaderExpirationPolicies(t *testing.T) {
+	ctx := context.Background()
+	committeeA := common.HexToHash("0xa")
+	committeeB := common.HexToHash("0xb")
+	scoped := NewKeysetScopedDASReader(&policyCountingReader{policy: MixedTimeout})
+	scoped.Register(committeeA, &policyCountingReader{policy: KeepForever})
+	scoped.Register(committeeB, &policyCountingReader{policy: DiscardAfterDataTimeout})
+
+	expected := map[common.Hash]ExpirationPolicy{
+		committeeA:              KeepForever,
+		committeeB:              DiscardAfterDataTimeout,
+		common.HexToHash("0xc"): MixedTimeout,
+	}
+	for keysetHash, expectedPolicy := range expected {
+		policy, err := scoped.ExpirationPolicyForKeyset(ctx, keysetHash)
+		Require(t, err)
+		if policy != expectedPolicy {
+			Fail(t, "keyset", keysetHash, "got policy", policy, "expected", expectedPolicy)
+		}
+	}
+
+	policies, err := scoped.ExpirationPolicies(ctx)
+	Require(t, err)
+	if len(policies) != 2 || policies[committeeA] != KeepForever || policies[committeeB] != DiscardAfterDataTimeout {
+		Fail(t, "got policies", policies)
+	}
+
+	scoped.Register(committeeB, &policyCountingReader{err: errors.New("backend unavailable")})
+	if _, err := scoped.ExpirationPolicies(ctx); err == nil {
+		Fail(t, "expected a failure to get a keyset's policy to be returned")
+	}
+}
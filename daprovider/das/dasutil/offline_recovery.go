@@ -0,0 +1,62 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/daprovider"
+)
+
+// RecoverPayloadOffline is the counterpart of RecoverPayloadFromDasBatch for deterministic replay and
+// proving, recovering the payload from preimages of the keyset and data that were already obtained,
+// without any I/O. The cert is parsed from the sequencer message, which also holds the max timestamp
+// its timeout is checked against. The keyset and data preimages are checked against the hashes of the
+// cert, its signature is verified and its timeout checked as when recovering online, so messages with
+// a bad signature or a cert expiring too soon are ignored, returning a nil payload. Multi-chunk certs
+// aren't supported, as their chunks are separate preimages.
+func RecoverPayloadOffline(
+	batchNum uint64,
+	sequencerMsg []byte,
+	keysetPreimage []byte,
+	dataPreimage []byte,
+	validateSeqMsg bool,
+) ([]byte, daprovider.PreimagesMap, error) {
+	ctx := context.Background()
+	// Multi-chunk certs are let through regardless of the ArbOS version, to be rejected with an error
+	// below rather than ignored.
+	r, err := startRecovery(batchNum, sequencerMsg, offlineDASReader{dataPreimage}, nil, RecoveryOptions{recoverAllCertVersions: true})
+	if r == nil {
+		return nil, nil, err
+	}
+	if r.cert.Version == MultiChunkCertVersion {
+		return nil, nil, fmt.Errorf("%w: multi-chunk certs can't be recovered offline", ErrUnsupportedCertVersion)
+	}
+	if err := r.setKeyset(keysetPreimage, validateSeqMsg); err != nil {
+		return nil, nil, err
+	}
+	err = r.verifyCertSignature()
+	if err != nil {
+		r.logBadSignature(err)
+		return nil, nil, nil
+	}
+	return r.finish(ctx, RecoveryOptions{})
+}
+
+// offlineDASReader serves the one data preimage of an offline recovery for any hash, leaving its
+// check against the cert's data hash to the recovery.
+type offlineDASReader struct {
+	data []byte
+}
+
+func (r offlineDASReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	return r.data, nil
+}
+
+func (r offlineDASReader) ExpirationPolicy(ctx context.Context) (ExpirationPolicy, error) {
+	return KeepForever, nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRecoverPayloadOffline(t *testing.T) {
+	for _, version := range []uint8{0, 1} {
+		r := newTestRecovery(t, []byte("batch replayed for a proof"), version)
+		payload, preimages, err := RecoverPayloadOffline(1, r.msg, r.keysetBytes, r.payload, true)
+		Require(t, err)
+		if !bytes.Equal(payload, r.payload) {
+			Fail(t, "version", version, "recovered wrong payload", payload)
+		}
+		// The preimages match those of the online recovery.
+		_, onlinePreimages, err := r.recover(context.Background(), nil, RecoveryOptions{})
+		Require(t, err)
+		if !reflect.DeepEqual(preimages, onlinePreimages) {
+			Fail(t, "version", version, "offline recovery recorded different preimages")
+		}
+
+		tamperedKeyset := append(bytes.Clone(r.keysetBytes), 0)
+		if _, _, err := RecoverPayloadOffline(1, r.msg, tamperedKeyset, r.payload, true); !errors.Is(err, ErrHashMismatch) {
+			Fail(t, "version", version, "expected a tampered keyset to be rejected, got", err)
+		}
+		tamperedData := bytes.Clone(r.payload)
+		tamperedData[0] ^= 0xff
+		if _, _, err := RecoverPayloadOffline(1, r.msg, r.keysetBytes, tamperedData, true); !errors.Is(err, ErrHashMismatch) {
+			Fail(t, "version", version, "expected tampered data to be rejected, got", err)
+		}
+
+		// Certs with a bad signature or expiring too soon are ignored, as when recovering online.
+		badSig := r.cert.Clone()
+		badSig.Timeout++
+		payload, _, err = RecoverPayloadOffline(1, makeSequencerMessage(t, 0, badSig), r.keysetBytes, r.payload, true)
+		if err != nil || payload != nil {
+			Fail(t, "version", version, "expected a cert with a bad signature to be ignored, got", payload, err)
+		}
+		payload, _, err = RecoverPayloadOffline(1, makeSequencerMessage(t, r.cert.Timeout, r.cert), r.keysetBytes, r.payload, true)
+		if err != nil || payload != nil {
+			Fail(t, "version", version, "expected a cert expiring too soon to be ignored, got", payload, err)
+		}
+	}
+}
@@ -0,0 +1,1161 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/daprovider"
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+var errTestNotFound = errors.New("not found")
+
+type testDASReader struct {
+	mutex sync.Mutex
+	data  map[common.Hash][]byte
+	calls []common.Hash
+}
+
+func newTestDASReader() *testDASReader {
+	return &testDASReader{data: make(map[common.Hash][]byte)}
+}
+
+func (r *testDASReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = append(r.calls, hash)
+	data, ok := r.data[hash]
+	if !ok {
+		return nil, errTestNotFound
+	}
+	return data, nil
+}
+
+func (r *testDASReader) ExpirationPolicy(ctx context.Context) (ExpirationPolicy, error) {
+	return KeepForever, nil
+}
+
+type testKeysetFetcher struct {
+	keysets map[common.Hash][]byte
+}
+
+func (f *testKeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	keyset, ok := f.keysets[hash]
+	if !ok {
+		return nil, errTestNotFound
+	}
+	return keyset, nil
+}
+
+// testRecovery holds a sequencer message whose DAS cert is signed by a single-key keyset,
+// together with a reader and keyset fetcher able to recover its payload.
+type testRecovery struct {
+	payload     []byte
+	cert        *DataAvailabilityCertificate
+	msg         []byte
+	keyset      *DataAvailabilityKeyset
+	keysetBytes []byte
+	privKeys    []blsSignatures.PrivateKey
+	reader      *testDASReader
+	fetcher     *testKeysetFetcher
+}
+
+func newTestRecovery(t testing.TB, payload []byte, version uint8) *testRecovery {
+	t.Helper()
+	pubKey, privKey, err := blsSignatures.GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyset := &DataAvailabilityKeyset{AssumedHonest: 1, PubKeys: []blsSignatures.PublicKey{pubKey}}
+	var keysetBuf bytes.Buffer
+	if err := keyset.Serialize(&keysetBuf); err != nil {
+		t.Fatal(err)
+	}
+	keysetHash, err := keyset.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := newTestDASReader()
+	cert := &DataAvailabilityCertificate{
+		KeysetHash:  keysetHash,
+		Timeout:     MinLifetimeSecondsForDataAvailabilityCert + 1000,
+		SignersMask: 1,
+		Version:     version,
+	}
+	if version == 0 {
+		cert.DataHash = crypto.Keccak256Hash(payload)
+	} else {
+		cert.DataHash = dastree.Hash(payload)
+	}
+	if version == MultiChunkCertVersion {
+		cert.DataHashes = [][32]byte{cert.DataHash}
+	}
+	reader.data[cert.DataHash] = payload
+	cert.Sig = signTestCert(t, privKey, cert)
+
+	return &testRecovery{
+		payload:     payload,
+		cert:        cert,
+		msg:         makeSequencerMessage(t, 0, cert),
+		keyset:      keyset,
+		keysetBytes: keysetBuf.Bytes(),
+		privKeys:    []blsSignatures.PrivateKey{privKey},
+		reader:      reader,
+		fetcher:     &testKeysetFetcher{keysets: map[common.Hash][]byte{keysetHash: keysetBuf.Bytes()}},
+	}
+}
+
+func (r *testRecovery) recover(ctx context.Context, preimages daprovider.PreimagesMap, opts RecoveryOptions) ([]byte, daprovider.PreimagesMap, error) {
+	return RecoverPayloadFromDasBatchWithOptions(ctx, 1, r.msg, r.reader, r.fetcher, preimages, true, opts)
+}
+
+func TestRecoverPayloadFromDasBatch(t *testing.T) {
+	ctx := context.Background()
+	for _, version := range []uint8{0, 1} {
+		r := newTestRecovery(t, []byte("some batch data"), version)
+		payload, _, err := RecoverPayloadFromDasBatch(ctx, 1, r.msg, r.reader, r.fetcher, nil, true)
+		Require(t, err)
+		if !bytes.Equal(payload, r.payload) {
+			Fail(t, "version", version, "recovered wrong payload", payload)
+		}
+	}
+}
+
+func TestRecoverPayloadAndCert(t *testing.T) {
+	ctx := context.Background()
+	for _, version := range []uint8{0, 1} {
+		r := newTestRecovery(t, []byte("some batch data"), version)
+		payload, _, cert, err := RecoverPayloadAndCertFromDasBatch(ctx, 1, r.msg, r.reader, r.fetcher, nil, true, RecoveryOptions{})
+		Require(t, err)
+		if !bytes.Equal(payload, r.payload) {
+			Fail(t, "version", version, "recovered wrong payload", payload)
+		}
+		parsed, _, err := DeserializeDASCertFromMessage(r.msg)
+		Require(t, err)
+		if cert == nil || !bytes.Equal(serializeTestCert(t, cert), serializeTestCert(t, parsed)) {
+			Fail(t, "version", version, "returned cert", cert, "doesn't match the parsed cert", parsed)
+		}
+	}
+
+	// The cert is returned even if the payload can't be recovered.
+	r := newTestRecovery(t, []byte("some batch data"), 1)
+	delete(r.reader.data, r.cert.DataHash)
+	payload, _, cert, err := RecoverPayloadAndCertFromDasBatch(ctx, 1, r.msg, r.reader, r.fetcher, nil, true, RecoveryOptions{})
+	if err == nil || payload != nil {
+		Fail(t, "expected recovery of missing data to fail, got", payload, err)
+	}
+	if cert == nil || cert.DataHash != r.cert.DataHash {
+		Fail(t, "expected the cert of the failed recovery, got", cert)
+	}
+}
+
+func TestRecoverPayloadTo(t *testing.T) {
+	ctx := context.Background()
+	payload := bytes.Repeat([]byte("streamed batch data "), 10000)
+	for _, version := range []uint8{0, 1} {
+		r := newTestRecovery(t, payload, version)
+		expected, expectedPreimages, err := RecoverPayloadFromDasBatch(ctx, 1, r.msg, r.reader, r.fetcher, nil, true)
+		Require(t, err)
+		var buf bytes.Buffer
+		n, preimages, err := RecoverPayloadTo(ctx, 1, r.msg, r.reader, r.fetcher, nil, true, RecoveryOptions{}, &buf)
+		Require(t, err)
+		if !bytes.Equal(buf.Bytes(), expected) || n != int64(len(expected)) {
+			Fail(t, "version", version, "streamed", n, "bytes not matching the recovered payload")
+		}
+		if !reflect.DeepEqual(preimages, expectedPreimages) {
+			Fail(t, "version", version, "recorded different preimages when streaming")
+		}
+	}
+
+	// Nothing is written for data that doesn't match its hash.
+	r := newTestRecovery(t, payload, 1)
+	r.reader.data[r.cert.DataHash] = []byte("tampered")
+	var buf bytes.Buffer
+	n, _, err := RecoverPayloadTo(ctx, 1, r.msg, r.reader, r.fetcher, nil, true, RecoveryOptions{}, &buf)
+	if !errors.Is(err, ErrHashMismatch) || n != 0 || buf.Len() != 0 {
+		Fail(t, "expected mismatching data not to be written, wrote", n, "err", err)
+	}
+}
+
+func TestRecoveryMinCertLifetime(t *testing.T) {
+	ctx := context.Background()
+	if lifetime := (RecoveryOptions{}).minCertLifetimeSeconds(); lifetime != MinLifetimeSecondsForDataAvailabilityCert {
+		Fail(t, "default minimum cert lifetime", lifetime, "doesn't match", MinLifetimeSecondsForDataAvailabilityCert)
+	}
+	r := newTestRecovery(t, []byte("near expiry batch"), 1)
+	testCases := []struct {
+		configured uint64
+		effective  uint64
+	}{
+		{0, MinLifetimeSecondsForDataAvailabilityCert},
+		{2 * MinLifetimeSecondsFloor, 2 * MinLifetimeSecondsFloor},
+		{MinLifetimeSecondsFloor, MinLifetimeSecondsFloor},
+		{1, MinLifetimeSecondsFloor},
+	}
+	for _, tc := range testCases {
+		opts := RecoveryOptions{MinCertLifetimeSeconds: tc.configured}
+		// The batch is accepted while the cert outlives its max timestamp by the lifetime.
+		atBoundary := r.addSignedBatch(t, r.payload, 1, r.cert.Timeout-tc.effective)
+		payload, _, err := RecoverPayloadFromDasBatchWithOptions(ctx, 1, atBoundary, r.reader, r.fetcher, nil, true, opts)
+		Require(t, err)
+		if !bytes.Equal(payload, r.payload) {
+			Fail(t, "lifetime", tc.configured, "rejected a cert at the boundary")
+		}
+		pastBoundary := r.addSignedBatch(t, r.payload, 1, r.cert.Timeout-tc.effective+1)
+		payload, _, err = RecoverPayloadFromDasBatchWithOptions(ctx, 1, pastBoundary, r.reader, r.fetcher, nil, true, opts)
+		Require(t, err)
+		if payload != nil {
+			Fail(t, "lifetime", tc.configured, "accepted a cert expiring too soon")
+		}
+	}
+}
+
+func TestRecoverPayloadSeqMsgValidationErrors(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("structurally invalid batches"), 1)
+	withByte := func(offset int, value byte) []byte {
+		msg := bytes.Clone(r.msg)
+		msg[offset] = value
+		return msg
+	}
+	testCases := []struct {
+		name string
+		msg  []byte
+	}{
+		{"short message", r.msg[:sequencerMsgHeaderLen]},
+		{"bad header", withByte(sequencerMsgHeaderLen, 0)},
+		{"truncated cert", r.msg[:len(r.msg)-1]},
+		{"tree cert of version 0", withByte(sequencerMsgHeaderLen+1+32+32+8, 0)},
+	}
+	strict := RecoveryOptions{RejectMalformedCerts: true}
+	for _, tc := range testCases {
+		payload, _, err := RecoverPayloadFromDasBatchWithOptions(ctx, 1, tc.msg, r.reader, r.fetcher, nil, true, strict)
+		if payload != nil || !errors.Is(err, daprovider.ErrSeqMsgValidation) {
+			Fail(t, tc.name, "expected a sequencer message validation error, got", err)
+		}
+		// Reading the inbox, the message is ignored as before, even when validating it, so that the
+		// node and the prover agree on it.
+		for _, validateSeqMsg := range []bool{true, false} {
+			payload, _, err = RecoverPayloadFromDasBatch(ctx, 1, tc.msg, r.reader, r.fetcher, nil, validateSeqMsg)
+			if payload != nil || err != nil {
+				Fail(t, tc.name, "expected the message to be ignored, got", payload, err)
+			}
+		}
+	}
+}
+
+func TestRecoverTreeCertOfVersion0(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("version 0 batch with a tree header"), 0)
+	// The signature of a version 0 cert doesn't cover its version, so it still verifies once the cert
+	// is given the tree header flag and a version byte of 0.
+	const versionOffset = sequencerMsgHeaderLen + 1 + 32 + 32 + 8
+	msg := bytes.Clone(r.msg[:versionOffset])
+	msg[sequencerMsgHeaderLen] |= daprovider.TreeDASMessageHeaderFlag
+	msg = append(msg, 0)
+	msg = append(msg, r.msg[versionOffset:]...)
+
+	// Until TreeHeaderVersionCheckArbOSVersion, the cert is recovered as a version 0 cert.
+	payload, _, err := RecoverPayloadFromDasBatch(ctx, 1, msg, r.reader, r.fetcher, nil, true)
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) {
+		Fail(t, "expected the payload of the cert to be recovered, got", payload)
+	}
+	opts := RecoveryOptions{ArbOSVersion: TreeHeaderVersionCheckArbOSVersion}
+	payload, _, err = RecoverPayloadFromDasBatchWithOptions(ctx, 1, msg, r.reader, r.fetcher, nil, true, opts)
+	if payload != nil || err != nil {
+		Fail(t, "expected the message to be ignored from TreeHeaderVersionCheckArbOSVersion on, got", payload, err)
+	}
+	opts = RecoveryOptions{RejectMalformedCerts: true}
+	if _, _, err := RecoverPayloadFromDasBatchWithOptions(ctx, 1, msg, r.reader, r.fetcher, nil, true, opts); !errors.Is(err, daprovider.ErrSeqMsgValidation) {
+		Fail(t, "expected a sequencer message validation error rejecting malformed certs, got", err)
+	}
+}
+
+func TestRecoverCertWithExtraSigners(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("batch claiming signers beyond its keyset"), 1)
+	// The signers mask isn't signed, so the signature still verifies with bits beyond the keyset set.
+	cert := r.cert.Clone()
+	cert.SignersMask |= 1 << 5
+	msg := makeSequencerMessage(t, 0, cert)
+
+	// Until SignersMaskCheckArbOSVersion, the extra bits are ignored.
+	for _, recoverPayload := range []func() ([]byte, error){
+		func() ([]byte, error) {
+			payload, _, err := RecoverPayloadFromDasBatch(ctx, 1, msg, r.reader, r.fetcher, nil, true)
+			return payload, err
+		},
+		func() ([]byte, error) {
+			result := BatchRecover(ctx, []BatchRecoveryRequest{{BatchNum: 1, SequencerMsg: msg}}, r.reader, r.fetcher, true, RecoveryOptions{})[0]
+			return result.Payload, result.Err
+		},
+	} {
+		payload, err := recoverPayload()
+		Require(t, err)
+		if !bytes.Equal(payload, r.payload) {
+			Fail(t, "expected the payload of the cert to be recovered, got", payload)
+		}
+	}
+	opts := RecoveryOptions{ArbOSVersion: SignersMaskCheckArbOSVersion}
+	payload, _, err := RecoverPayloadFromDasBatchWithOptions(ctx, 1, msg, r.reader, r.fetcher, nil, true, opts)
+	if payload != nil || err != nil {
+		Fail(t, "expected the message to be ignored from SignersMaskCheckArbOSVersion on, got", payload, err)
+	}
+	result := BatchRecover(ctx, []BatchRecoveryRequest{{BatchNum: 1, SequencerMsg: msg}}, r.reader, r.fetcher, true, opts)[0]
+	if result.Payload != nil || result.Err != nil {
+		Fail(t, "expected the message to be ignored by batch recovery from SignersMaskCheckArbOSVersion on, got", result)
+	}
+	// Checking the signature outside of recovery always rejects the mask.
+	if err := VerifyCertSignature(cert, r.keyset); !errors.Is(err, ErrMalformedSignersMask) {
+		Fail(t, "expected the signers mask to be rejected, got", err)
+	}
+}
+
+func TestRecoverWeightedKeysetGating(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("batch of a keyset starting with the versioned keyset marker"), 1)
+	// withKeyset returns the message of the cert referencing the given keyset instead, which its
+	// signature doesn't cover.
+	withKeyset := func(keyset *DataAvailabilityKeyset) []byte {
+		var buf bytes.Buffer
+		Require(t, keyset.Serialize(&buf))
+		hash, err := keyset.Hash()
+		Require(t, err)
+		r.fetcher.keysets[hash] = buf.Bytes()
+		cert := r.cert.Clone()
+		cert.KeysetHash = hash
+		return makeSequencerMessage(t, 0, cert)
+	}
+	// An unweighted keyset whose AssumedHonest is the versioned keyset marker.
+	markerMsg := withKeyset(&DataAvailabilityKeyset{AssumedHonest: versionedKeysetMarker, PubKeys: r.keyset.PubKeys})
+	weightedMsg := withKeyset(&DataAvailabilityKeyset{AssumedHonest: 1, PubKeys: r.keyset.PubKeys, Weights: []uint64{1}})
+
+	// Until WeightedKeysetArbOSVersion, keysets are parsed in the original format.
+	payload, _, err := RecoverPayloadFromDasBatch(ctx, 1, markerMsg, r.reader, r.fetcher, nil, true)
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) {
+		Fail(t, "expected the payload of the cert to be recovered, got", payload)
+	}
+	if _, _, err := RecoverPayloadFromDasBatch(ctx, 1, weightedMsg, r.reader, r.fetcher, nil, true); !errors.Is(err, daprovider.ErrSeqMsgValidation) {
+		Fail(t, "expected the weighted keyset not to parse, got", err)
+	}
+
+	opts := RecoveryOptions{ArbOSVersion: WeightedKeysetArbOSVersion}
+	payload, _, err = RecoverPayloadFromDasBatchWithOptions(ctx, 1, weightedMsg, r.reader, r.fetcher, nil, true, opts)
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) {
+		Fail(t, "expected the payload of the weighted keyset's cert to be recovered, got", payload)
+	}
+	if _, _, err := RecoverPayloadFromDasBatchWithOptions(ctx, 1, markerMsg, r.reader, r.fetcher, nil, true, opts); !errors.Is(err, daprovider.ErrSeqMsgValidation) {
+		Fail(t, "expected the marker to be read as a keyset version, got", err)
+	}
+}
+
+func TestRecoverPayloadWithProof(t *testing.T) {
+	ctx := context.Background()
+	for _, size := range []int{100, 3*dastree.BinSize + 100} {
+		payload := make([]byte, size)
+		for i := range payload {
+			payload[i] = byte(i * 7)
+		}
+		r := newTestRecovery(t, payload, 1)
+		recovered, _, proof, err := RecoverPayloadWithProof(ctx, 1, r.msg, r.reader, r.fetcher, nil, true, RecoveryOptions{})
+		Require(t, err)
+		if !bytes.Equal(recovered, payload) {
+			Fail(t, "recovered wrong payload of size", size)
+		}
+		for _, node := range proof {
+			if bytes.Contains(node, payload[:32]) {
+				Fail(t, "proof of size", size, "contains payload data")
+			}
+		}
+
+		bins, err := dastree.VerifyProof(r.cert.DataHash, proof)
+		Require(t, err)
+		if len(bins) != (size+dastree.BinSize-1)/dastree.BinSize {
+			Fail(t, "proof of size", size, "has", len(bins), "bins")
+		}
+		for i, binHash := range bins {
+			bin := payload[i*dastree.BinSize : min((i+1)*dastree.BinSize, size)]
+			if crypto.Keccak256Hash(bin) != binHash {
+				Fail(t, "bin", i, "of size", size, "doesn't match the proof")
+			}
+		}
+		if _, err := dastree.VerifyProof(dastree.Hash([]byte("other data")), proof); err == nil {
+			Fail(t, "proof of size", size, "verified against another data hash")
+		}
+		if len(proof) > 1 {
+			if _, err := dastree.VerifyProof(r.cert.DataHash, proof[1:]); err == nil {
+				Fail(t, "proof of size", size, "verified without all its nodes")
+			}
+		}
+	}
+
+	// Version 0 certs commit to the flat hash of the payload, so there's no proof.
+	r := newTestRecovery(t, []byte("version 0 batch"), 0)
+	recovered, _, proof, err := RecoverPayloadWithProof(ctx, 1, r.msg, r.reader, r.fetcher, nil, true, RecoveryOptions{})
+	Require(t, err)
+	if recovered == nil || proof != nil {
+		Fail(t, "expected a version 0 payload without a proof, got", proof)
+	}
+}
+
+func TestRecoverPayloadConcurrentPreimageIsolation(t *testing.T) {
+	ctx := context.Background()
+	const numRecoveries = 32
+
+	// All recoveries share one reader and keyset fetcher, but each records into its own map.
+	reader := newTestDASReader()
+	fetcher := &testKeysetFetcher{keysets: make(map[common.Hash][]byte)}
+	var recoveries []*testRecovery
+	for i := 0; i < numRecoveries; i++ {
+		// #nosec G115
+		r := newTestRecovery(t, []byte{byte(i), 'd', 'a', 't', 'a'}, uint8(i%2))
+		for hash, data := range r.reader.data {
+			reader.data[hash] = data
+		}
+		for hash, keyset := range r.fetcher.keysets {
+			fetcher.keysets[hash] = keyset
+		}
+		recoveries = append(recoveries, r)
+	}
+
+	results := make([]daprovider.PreimagesMap, numRecoveries)
+	errs := make([]error, numRecoveries)
+	var wg sync.WaitGroup
+	for i, r := range recoveries {
+		wg.Add(1)
+		go func(i int, r *testRecovery) {
+			defer wg.Done()
+			var payload []byte
+			payload, results[i], errs[i] = RecoverPayloadFromDasBatch(ctx, 1, r.msg, reader, fetcher, nil, true)
+			if errs[i] == nil && !bytes.Equal(payload, r.payload) {
+				errs[i] = errors.New("recovered wrong payload")
+			}
+		}(i, r)
+	}
+	wg.Wait()
+
+	for i, r := range recoveries {
+		Require(t, errs[i], "recovery", i)
+		recorded := results[i][arbutil.Keccak256PreimageType]
+		if !bytes.Equal(recorded[crypto.Keccak256Hash(r.payload)], r.payload) {
+			Fail(t, "recovery", i, "is missing its own payload preimage")
+		}
+		if !bytes.Equal(recorded[crypto.Keccak256Hash(r.keysetBytes)], r.keysetBytes) {
+			Fail(t, "recovery", i, "is missing its own keyset preimage")
+		}
+		for j, other := range recoveries {
+			if j == i {
+				continue
+			}
+			if _, ok := recorded[crypto.Keccak256Hash(other.payload)]; ok {
+				Fail(t, "recovery", i, "recorded the payload of recovery", j)
+			}
+			if _, ok := recorded[crypto.Keccak256Hash(other.keysetBytes)]; ok {
+				Fail(t, "recovery", i, "recorded the keyset of recovery", j)
+			}
+		}
+	}
+}
+
+func TestRecoverPayloadSkipTreeLeafRecording(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("version zero data"), 0)
+	treeLeaf := dastree.FlatHashToTreeLeaf(r.cert.DataHash)
+	treeLeafHash := crypto.Keccak256Hash(treeLeaf)
+
+	preimages := make(daprovider.PreimagesMap)
+	payload, _, err := r.recover(ctx, preimages, RecoveryOptions{})
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) {
+		Fail(t, "recovered wrong payload", payload)
+	}
+	if !bytes.Equal(preimages[arbutil.Keccak256PreimageType][treeLeafHash], treeLeaf) {
+		Fail(t, "expected tree leaf to be recorded by default")
+	}
+
+	preimages = make(daprovider.PreimagesMap)
+	payload, _, err = r.recover(ctx, preimages, RecoveryOptions{SkipTreeLeafRecording: true})
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) {
+		Fail(t, "recovered wrong payload", payload)
+	}
+	if _, ok := preimages[arbutil.Keccak256PreimageType][treeLeafHash]; ok {
+		Fail(t, "tree leaf recorded even though recording was skipped")
+	}
+	if !bytes.Equal(preimages[arbutil.Keccak256PreimageType][r.cert.DataHash], r.payload) {
+		Fail(t, "payload preimage should still be recorded")
+	}
+}
+
+func TestVersion0DataHashes(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("version zero data"), 0)
+	recorded := make(daprovider.PreimagesMap)
+	_, _, err := r.recover(ctx, recorded, RecoveryOptions{})
+	Require(t, err)
+
+	flatHash, treeHash, preimages, err := Version0DataHashes(r.cert.DataHash, r.payload)
+	Require(t, err)
+	if flatHash != common.Hash(r.cert.DataHash) {
+		Fail(t, "unexpected flat hash", flatHash)
+	}
+	// Recovery looks the data up under its tree hash first.
+	if len(r.reader.calls) == 0 || r.reader.calls[0] != treeHash {
+		Fail(t, "tree hash", treeHash, "doesn't match the hash recovery read first", r.reader.calls)
+	}
+	for hash, preimage := range preimages[arbutil.Keccak256PreimageType] {
+		if !bytes.Equal(recorded[arbutil.Keccak256PreimageType][hash], preimage) {
+			Fail(t, "preimage", hash, "wasn't recorded by recovery")
+		}
+	}
+	if len(preimages[arbutil.Keccak256PreimageType]) != 2 {
+		Fail(t, "expected the payload and tree leaf preimages, got", len(preimages[arbutil.Keccak256PreimageType]))
+	}
+
+	if _, _, _, err := Version0DataHashes(r.cert.DataHash, []byte("other data")); !errors.Is(err, ErrHashMismatch) {
+		Fail(t, "expected mismatching payload to be rejected, got", err)
+	}
+}
+
+func TestRecoverPayloadSizeHistogram(t *testing.T) {
+	ctx := context.Background()
+	for _, version := range SupportedCertVersions(MultiChunkCertArbOSVersion) {
+		histogram := recoveredPayloadSizeHistogram("", version)
+		before := histogram.Snapshot()
+		sizes := []int{1, 100, 4096}
+		for _, size := range sizes {
+			r := newTestRecovery(t, bytes.Repeat([]byte{0xcd}, size), version)
+			_, _, err := r.recover(ctx, nil, RecoveryOptions{ArbOSVersion: MultiChunkCertArbOSVersion})
+			Require(t, err)
+		}
+		after := histogram.Snapshot()
+		if after.Count()-before.Count() != int64(len(sizes)) {
+			Fail(t, "version", version, "expected", len(sizes), "samples, got", after.Count()-before.Count())
+		}
+		if after.Max() < 4096 {
+			Fail(t, "version", version, "largest payload size wasn't sampled", after.Max())
+		}
+	}
+
+	// Sizes recorded for unsupported versions are dropped rather than panicking.
+	RecordRecoveredPayloadSize("", MaxSupportedCertVersion+1, 100)
+	RecordRecoveredPayloadSize("", 0xff, 100)
+}
+
+func TestRecoverPayloadCertVersionGate(t *testing.T) {
+	ctx := context.Background()
+	versions := SupportedCertVersions(MultiChunkCertArbOSVersion)
+	if len(versions) == 0 || versions[len(versions)-1] != MaxSupportedCertVersion {
+		Fail(t, "unexpected supported versions", versions)
+	}
+	// The reported versions match what recovery accepts before multi-chunk certs are activated.
+	if versions := SupportedCertVersions(MultiChunkCertArbOSVersion - 1); slices.Contains(versions, MultiChunkCertVersion) {
+		Fail(t, "multi-chunk certs reported as supported before their ArbOS version", versions)
+	}
+
+	Require(t, ValidateCertVersion(MaxSupportedCertVersion, MultiChunkCertArbOSVersion))
+	if err := ValidateCertVersion(MultiChunkCertVersion, MultiChunkCertArbOSVersion-1); !errors.Is(err, ErrUnsupportedCertVersion) {
+		Fail(t, "expected multi-chunk certs to be rejected before their ArbOS version, got", err)
+	}
+	if err := ValidateCertVersion(MaxSupportedCertVersion+1, math.MaxUint64); !errors.Is(err, ErrUnsupportedCertVersion) {
+		Fail(t, "expected version past the max supported one to be rejected, got", err)
+	}
+	Require(t, ValidateWritableCertVersion(MaxWritableCertVersion))
+	if err := ValidateWritableCertVersion(MultiChunkCertVersion); !errors.Is(err, ErrUnsupportedCertVersion) {
+		Fail(t, "expected multi-chunk certs not to be writable, got", err)
+	}
+
+	r := newTestRecovery(t, []byte("some batch data"), MaxSupportedCertVersion)
+	payload, _, err := r.recover(ctx, nil, RecoveryOptions{ArbOSVersion: MultiChunkCertArbOSVersion})
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) {
+		Fail(t, "max supported version wasn't recovered", payload)
+	}
+
+	// Multi-chunk certs are ignored before the ArbOS version activating them, as by older nodes.
+	reads := len(r.reader.calls)
+	payload, _, err = r.recover(ctx, nil, RecoveryOptions{ArbOSVersion: MultiChunkCertArbOSVersion - 1})
+	Require(t, err)
+	if payload != nil {
+		Fail(t, "multi-chunk cert was recovered before its ArbOS version", payload)
+	}
+	if len(r.reader.calls) != reads {
+		Fail(t, "expected no reads for the inactive version", r.reader.calls)
+	}
+
+	// Re-sign the cert with an unsupported version, so that only the version gate can reject it.
+	cert := *r.cert
+	cert.Version = MaxSupportedCertVersion + 1
+	cert.Sig = signTestCert(t, r.privKeys[0], &cert)
+	r.msg = makeSequencerMessage(t, 0, &cert)
+	payload, _, err = r.recover(ctx, nil, RecoveryOptions{ArbOSVersion: math.MaxUint64})
+	Require(t, err)
+	if payload != nil {
+		Fail(t, "unsupported version was recovered", payload)
+	}
+	if len(r.reader.calls) != reads {
+		Fail(t, "expected no reads for the unsupported version", r.reader.calls)
+	}
+}
+
+func TestRecoverPayloadMultiChunk(t *testing.T) {
+	ctx := context.Background()
+	chunks := [][]byte{[]byte("first chunk of the batch, "), []byte("second chunk of the batch")}
+	payload := bytes.Join(chunks, nil)
+	r := newTestRecovery(t, payload, 1)
+	var chunkHashes [][32]byte
+	for _, chunk := range chunks {
+		chunkHash := dastree.Hash(chunk)
+		r.reader.data[chunkHash] = chunk
+		chunkHashes = append(chunkHashes, chunkHash)
+	}
+	signChunks := func(dataHashes [][32]byte) {
+		t.Helper()
+		cert := r.cert.Clone()
+		cert.Version = MultiChunkCertVersion
+		cert.DataHashes = dataHashes
+		cert.Sig = signTestCert(t, r.privKeys[0], cert)
+		r.msg = makeSequencerMessage(t, 0, cert)
+	}
+	signChunks(chunkHashes)
+	opts := RecoveryOptions{ArbOSVersion: MultiChunkCertArbOSVersion}
+
+	cert, _, err := DeserializeDASCertFromMessage(r.msg)
+	Require(t, err)
+	if !reflect.DeepEqual(cert.DataHashes, chunkHashes) {
+		Fail(t, "chunk hashes don't round trip through serialization", cert.DataHashes)
+	}
+	if _, err := DeserializeDASCertWithSignatureEncoding(serializeTestCert(t, cert), UncompressedSignatures); err != nil {
+		Fail(t, "multi-chunk cert rejected as the wrong length", err)
+	}
+
+	recovered, preimages, err := r.recover(ctx, nil, opts)
+	Require(t, err)
+	if !bytes.Equal(recovered, payload) {
+		Fail(t, "recovered wrong payload from chunks", string(recovered))
+	}
+	recorded := preimages[arbutil.Keccak256PreimageType]
+	oracle := func(hash common.Hash) ([]byte, error) {
+		preimage, ok := recorded[hash]
+		if !ok {
+			return nil, fmt.Errorf("preimage %v wasn't recorded", hash)
+		}
+		return preimage, nil
+	}
+	for _, root := range append([][32]byte{r.cert.DataHash}, chunkHashes...) {
+		if _, err := dastree.Content(root, oracle); err != nil {
+			Fail(t, "preimages of", common.Hash(root), "weren't recorded", err)
+		}
+	}
+
+	// A chunk not matching its hash is rejected.
+	r.reader.data[chunkHashes[1]] = []byte("tampered second chunk")
+	if _, _, err := r.recover(ctx, nil, opts); !errors.Is(err, ErrHashMismatch) {
+		Fail(t, "expected tampered chunk to be rejected, got", err)
+	}
+	r.reader.data[chunkHashes[1]] = chunks[1]
+
+	// Chunks matching their hashes must still concatenate to the payload the cert commits to.
+	signChunks([][32]byte{chunkHashes[1], chunkHashes[0]})
+	if _, _, err := r.recover(ctx, nil, opts); !errors.Is(err, ErrHashMismatch) {
+		Fail(t, "expected reordered chunks to be rejected, got", err)
+	}
+
+	signChunks(chunkHashes)
+	opts.MaxPayloadSize = uint64(len(chunks[0]) + 1)
+	if _, _, err := r.recover(ctx, nil, opts); !errors.Is(err, ErrPayloadTooLarge) {
+		Fail(t, "expected chunks exceeding the max payload size to be rejected, got", err)
+	}
+
+	// More chunks than their single byte count can hold aren't truncated.
+	tooMany := r.cert.Clone()
+	tooMany.Version = MultiChunkCertVersion
+	tooMany.DataHashes = make([][32]byte, maxCertDataChunks+1)
+	if _, err := tooMany.SerializeSignableFields(); err == nil {
+		Fail(t, "expected a cert with too many chunks not to be signable")
+	}
+	if _, err := Serialize(tooMany); err == nil {
+		Fail(t, "expected a cert with too many chunks not to be serializable")
+	}
+}
+
+func TestRecoverPayloadKeysetValidity(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("some batch data"), 1)
+	registered := map[common.Hash]bool{}
+	var checkedBatches []uint64
+	opts := RecoveryOptions{
+		CheckKeysetValidity: func(ctx context.Context, keysetHash common.Hash, batchNum uint64) (bool, error) {
+			checkedBatches = append(checkedBatches, batchNum)
+			return registered[keysetHash], nil
+		},
+	}
+
+	payload, _, err := r.recover(ctx, nil, opts)
+	if !errors.Is(err, ErrKeysetNotValid) {
+		Fail(t, "expected unregistered keyset to be rejected, got", err)
+	}
+	if payload != nil || len(r.reader.calls) != 0 {
+		Fail(t, "payload of a cert with an unregistered keyset was fetched", r.reader.calls)
+	}
+	results := BatchRecover(ctx, []BatchRecoveryRequest{{BatchNum: 7, SequencerMsg: r.msg}}, r.reader, r.fetcher, true, opts)
+	if !errors.Is(results[0].Err, ErrKeysetNotValid) {
+		Fail(t, "expected batch recovery of an unregistered keyset to be rejected, got", results[0].Err)
+	}
+	if !reflect.DeepEqual(checkedBatches, []uint64{1, 7}) {
+		Fail(t, "keyset validity checked for unexpected batches", checkedBatches)
+	}
+
+	registered[r.cert.KeysetHash] = true
+	payload, _, err = r.recover(ctx, nil, opts)
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) {
+		Fail(t, "recovered wrong payload with a registered keyset", payload)
+	}
+
+	checkErr := errors.New("parent chain unavailable")
+	opts.CheckKeysetValidity = func(context.Context, common.Hash, uint64) (bool, error) {
+		return false, checkErr
+	}
+	if _, _, err := r.recover(ctx, nil, opts); !errors.Is(err, checkErr) {
+		Fail(t, "expected failure of the keyset validity check to be returned, got", err)
+	}
+}
+
+func TestRecoverPayloadPostProcess(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("some batch data"), 1)
+	reverse := func(payload []byte) ([]byte, error) {
+		reversed := make([]byte, len(payload))
+		for i, b := range payload {
+			reversed[len(payload)-1-i] = b
+		}
+		return reversed, nil
+	}
+
+	preimages := make(daprovider.PreimagesMap)
+	payload, _, err := r.recover(ctx, preimages, RecoveryOptions{PostProcess: reverse})
+	Require(t, err)
+	if string(payload) != "atad hctab emos" {
+		Fail(t, "unexpected post-processed payload", string(payload))
+	}
+	if _, ok := preimages[arbutil.Keccak256PreimageType][crypto.Keccak256Hash(r.payload)]; !ok {
+		Fail(t, "expected preimages of the raw payload to be recorded")
+	}
+
+	failing := func(payload []byte) ([]byte, error) {
+		return nil, errors.New("bad payload")
+	}
+	if _, _, err := r.recover(ctx, nil, RecoveryOptions{PostProcess: failing}); err == nil {
+		Fail(t, "expected post-processing error to be returned")
+	}
+}
+
+// sizeLimitedTestDASReader records the limit passed to GetByHashWithSizeLimit.
+type sizeLimitedTestDASReader struct {
+	*testDASReader
+	limits []uint64
+}
+
+func (r *sizeLimitedTestDASReader) GetByHashWithSizeLimit(ctx context.Context, hash common.Hash, maxSize uint64) ([]byte, error) {
+	r.limits = append(r.limits, maxSize)
+	return r.GetByHash(ctx, hash)
+}
+
+func TestRecoverPayloadMaxPayloadSize(t *testing.T) {
+	ctx := context.Background()
+	payload := []byte("some batch data")
+	r := newTestRecovery(t, payload, 1)
+
+	recovered, _, err := r.recover(ctx, nil, RecoveryOptions{MaxPayloadSize: uint64(len(payload))})
+	Require(t, err)
+	if !bytes.Equal(recovered, payload) {
+		Fail(t, "recovered wrong payload", recovered)
+	}
+
+	// The DAS returns an oversized blob for the cert's hash. It must be rejected for its size rather
+	// than failing the hash comparison.
+	r.reader.data[r.cert.DataHash] = make([]byte, 1<<20)
+	if _, _, err := r.recover(ctx, nil, RecoveryOptions{MaxPayloadSize: 1 << 10}); !errors.Is(err, ErrPayloadTooLarge) {
+		Fail(t, "expected oversized payload to be rejected, got", err)
+	}
+	if _, _, err := r.recover(ctx, nil, RecoveryOptions{}); !errors.Is(err, ErrHashMismatch) {
+		Fail(t, "expected hash mismatch without a size limit, got", err)
+	}
+
+	// Readers that can limit their reads are given the limit.
+	limitedReader := &sizeLimitedTestDASReader{testDASReader: r.reader}
+	_, _, err = RecoverPayloadFromDasBatchWithOptions(ctx, 1, r.msg, limitedReader, r.fetcher, nil, true, RecoveryOptions{MaxPayloadSize: 1 << 10})
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		Fail(t, "expected oversized payload to be rejected, got", err)
+	}
+	if len(limitedReader.limits) != 1 || limitedReader.limits[0] != 1<<10 {
+		Fail(t, "expected the size limit to be passed to the reader, got", limitedReader.limits)
+	}
+}
+
+func TestRecoverKeysetLegacyFlatHash(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("some batch data"), 0)
+
+	// The cert references the keyset by its flat keccak hash, but storage only has it under the
+	// dastree hash that flat hash converts to.
+	cert := *r.cert
+	cert.KeysetHash = crypto.Keccak256Hash(r.keysetBytes)
+	reader := newTestDASReader()
+	reader.data[dastree.FlatHashToTreeHash(cert.KeysetHash)] = r.keysetBytes
+
+	keyset, err := cert.RecoverKeyset(ctx, reader, false)
+	Require(t, err)
+	if keyset.AssumedHonest != r.keyset.AssumedHonest || len(keyset.PubKeys) != len(r.keyset.PubKeys) {
+		Fail(t, "recovered wrong keyset", keyset)
+	}
+	if len(reader.calls) != 2 || reader.calls[0] != cert.KeysetHash {
+		Fail(t, "expected the cert's hash to be tried before the legacy hash", reader.calls)
+	}
+
+	// A keyset stored under the legacy hash must still match the cert's flat hash.
+	reader.data[dastree.FlatHashToTreeHash(cert.KeysetHash)] = append([]byte{}, r.keysetBytes[:len(r.keysetBytes)-1]...)
+	if _, err := cert.RecoverKeyset(ctx, reader, false); err == nil {
+		Fail(t, "expected mismatched legacy keyset to be rejected")
+	}
+
+	// A keyset stored only under its legacy flat hash is fetched by that hash directly.
+	flatOnly := newTestDASReader()
+	flatOnly.data[cert.KeysetHash] = r.keysetBytes
+	keyset, err = cert.RecoverKeyset(ctx, flatOnly, false)
+	Require(t, err)
+	if len(keyset.PubKeys) != len(r.keyset.PubKeys) || len(flatOnly.calls) != 1 {
+		Fail(t, "expected the keyset to be recovered by its flat hash, got calls", flatOnly.calls)
+	}
+
+	// Keysets stored under the cert's hash are unaffected.
+	keyset, err = r.cert.RecoverKeyset(ctx, &testDASReader{data: map[common.Hash][]byte{r.cert.KeysetHash: r.keysetBytes}}, false)
+	Require(t, err)
+	if len(keyset.PubKeys) != 1 {
+		Fail(t, "recovered wrong keyset", keyset)
+	}
+}
+
+func TestRecoverPayloadKeccakHashedKeyset(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("one of the oldest batches"), 0)
+	// Serialized weighted keysets start with a byte dastree.ValidHash takes for a tree node, so a
+	// flat keccak hash of one is only accepted for the version 0 certs predating tree hashing.
+	r.keyset.Weights = []uint64{1}
+	var keysetBuf bytes.Buffer
+	Require(t, r.keyset.Serialize(&keysetBuf))
+	keysetBytes := keysetBuf.Bytes()
+	keysetHash := crypto.Keccak256Hash(keysetBytes)
+	if dastree.ValidHash(keysetHash, keysetBytes) {
+		Fail(t, "expected the keccak hash of the keyset not to be a valid dastree hash")
+	}
+	fetcher := &testKeysetFetcher{keysets: map[common.Hash][]byte{keysetHash: keysetBytes}}
+	sign := func(version uint8) []byte {
+		cert := r.cert.Clone()
+		cert.KeysetHash = keysetHash
+		cert.Version = version
+		if version != 0 {
+			cert.DataHash = dastree.Hash(r.payload)
+			r.reader.data[cert.DataHash] = r.payload
+		}
+		cert.Sig = signTestCert(t, r.privKeys[0], cert)
+		return makeSequencerMessage(t, 0, cert)
+	}
+
+	payload, preimages, err := RecoverPayloadFromDasBatch(ctx, 1, sign(0), r.reader, fetcher, nil, true)
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) {
+		Fail(t, "recovered wrong payload", payload)
+	}
+	// The keyset is recorded under the hash the cert references it by.
+	if !bytes.Equal(preimages[arbutil.Keccak256PreimageType][keysetHash], keysetBytes) {
+		Fail(t, "expected the keyset to be recorded under its keccak hash")
+	}
+
+	if _, _, err := RecoverPayloadFromDasBatch(ctx, 1, sign(1), r.reader, fetcher, nil, true); !errors.Is(err, ErrHashMismatch) {
+		Fail(t, "expected a version 1 cert referencing a keccak-hashed keyset to be rejected, got", err)
+	}
+}
+
+func benchmarkRecoverVersion0(b *testing.B, opts RecoveryOptions) {
+	ctx := context.Background()
+	r := newTestRecovery(b, bytes.Repeat([]byte{0xab}, 4096), 0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := r.recover(ctx, make(daprovider.PreimagesMap), opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRecoverVersion0WithTreeLeafRecording(b *testing.B) {
+	benchmarkRecoverVersion0(b, RecoveryOptions{})
+}
+
+func BenchmarkRecoverVersion0SkipTreeLeafRecording(b *testing.B) {
+	benchmarkRecoverVersion0(b, RecoveryOptions{SkipTreeLeafRecording: true})
+}
+
+var errTestTransient = errors.New("transient failure")
+
+// flakyKeysetFetcher fails with errTestTransient the given number of times before fetching keysets.
+type flakyKeysetFetcher struct {
+	*testKeysetFetcher
+	failures int
+	calls    int
+}
+
+func (f *flakyKeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errTestTransient
+	}
+	return f.testKeysetFetcher.GetKeysetByHash(ctx, hash)
+}
+
+func TestRecoverPayloadRetriesTransientKeysetFetchFailures(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("some batch data"), 1)
+	opts := RecoveryOptions{
+		KeysetFetchRetries:    3,
+		KeysetFetchRetryDelay: time.Millisecond,
+		IsTransientError:      func(err error) bool { return errors.Is(err, errTestTransient) },
+	}
+	recoverWith := func(fetcher DASKeysetFetcher, opts RecoveryOptions) ([]byte, error) {
+		payload, _, err := RecoverPayloadFromDasBatchWithOptions(ctx, 1, r.msg, r.reader, fetcher, nil, true, opts)
+		return payload, err
+	}
+
+	fetcher := &flakyKeysetFetcher{testKeysetFetcher: r.fetcher, failures: 2}
+	payload, err := recoverWith(fetcher, opts)
+	Require(t, err)
+	if !bytes.Equal(payload, r.payload) || fetcher.calls != 3 {
+		Fail(t, "expected recovery after two retries, got", payload, "with", fetcher.calls, "fetches")
+	}
+
+	// Without retries enabled, the first failure is returned.
+	fetcher = &flakyKeysetFetcher{testKeysetFetcher: r.fetcher, failures: 2}
+	if _, err := recoverWith(fetcher, RecoveryOptions{}); !errors.Is(err, errTestTransient) || fetcher.calls != 1 {
+		Fail(t, "expected the transient failure without retries, got", err, "with", fetcher.calls, "fetches")
+	}
+
+	// Retries are bounded.
+	fetcher = &flakyKeysetFetcher{testKeysetFetcher: r.fetcher, failures: 10}
+	if _, err := recoverWith(fetcher, opts); !errors.Is(err, errTestTransient) || fetcher.calls != 4 {
+		Fail(t, "expected the transient failure once retries ran out, got", err, "with", fetcher.calls, "fetches")
+	}
+
+	// An unknown keyset is a permanent failure, which isn't retried.
+	unknown := &flakyKeysetFetcher{testKeysetFetcher: &testKeysetFetcher{keysets: map[common.Hash][]byte{}}}
+	if _, err := recoverWith(unknown, opts); !errors.Is(err, errTestNotFound) || unknown.calls != 1 {
+		Fail(t, "expected unknown keyset to fail without retries, got", err, "with", unknown.calls, "fetches")
+	}
+}
+
+func TestRecoverPayloadMetricsNamespace(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRecovery(t, []byte("some batch data"), 1)
+	_, _, err := r.recover(ctx, nil, RecoveryOptions{MetricsNamespace: "recovery-namespace-test"})
+	Require(t, err)
+	namespaced := metrics.DefaultRegistry.Get("arb/das/recovery-namespace-test/recover/payload/size/v1")
+	if namespaced == nil {
+		Fail(t, "missing namespaced recovery metric")
+	}
+	if namespaced == metrics.DefaultRegistry.Get("arb/das/recover/payload/size/v1") {
+		Fail(t, "namespaced recovery metric is shared with the default namespace")
+	}
+	if got := namespaced.(metrics.Histogram).Snapshot().Count(); got != 1 {
+		Fail(t, "expected one sample in the namespaced metric, got", got)
+	}
+}
+
+// policyCountingReader is a DASReader with a settable expiration policy, counting reads of the policy.
+type policyCountingReader struct {
+	testDASReader
+	policy      ExpirationPolicy
+	err         error
+	policyReads int
+}
+
+func (r *policyCountingReader) ExpirationPolicy(ctx context.Context) (ExpirationPolicy, error) {
+	r.policyReads++
+	return r.policy, r.err
+}
+
+func TestReaderForDASExpirationPolicy(t *testing.T) {
+	ctx := context.Background()
+	dasReader := &policyCountingReader{policy: DiscardAfterDataTimeout}
+	reader := NewReaderForDAS(dasReader, &testKeysetFetcher{})
+	now := time.Now()
+	reader.now = func() time.Time { return now }
+
+	check := func(expected ExpirationPolicy, expectedCalls int) {
+		t.Helper()
+		policy, err := reader.ExpirationPolicy(ctx)
+		Require(t, err)
+		if policy != expected || dasReader.policyReads != expectedCalls {
+			Fail(t, "got policy", policy, "after", dasReader.policyReads, "reads, expected", expected, "after", expectedCalls)
+		}
+	}
+	check(DiscardAfterDataTimeout, 1)
+	// The policy is cached until the cache duration passes.
+	dasReader.policy = KeepForever
+	now = now.Add(expirationPolicyCacheDuration - time.Second)
+	check(DiscardAfterDataTimeout, 1)
+	now = now.Add(time.Second)
+	check(KeepForever, 2)
+
+	// Failures aren't cached.
+	dasReader.err = errors.New("backend unavailable")
+	now = now.Add(expirationPolicyCacheDuration)
+	if _, err := reader.ExpirationPolicy(ctx); err == nil {
+		Fail(t, "expected the failure to read the policy to be returned")
+	}
+	dasReader.err = nil
+	check(KeepForever, 4)
+}
+
+func TestReaderForDASValidatedPayloadCache(t *testing.T) {
+	ctx := context.Background()
+	for _, version := range []uint8{0, 1} {
+		r := newTestRecovery(t, []byte("batch recovered again after a reorg"), version)
+		reader := NewReaderForDAS(r.reader, r.fetcher)
+		reader.EnableValidatedPayloadCache(1 << 20)
+
+		// A payload failing the hash check isn't cached.
+		r.reader.data[r.cert.DataHash] = []byte("corrupted")
+		if _, _, err := reader.RecoverPayloadFromBatch(ctx, 1, common.Hash{}, r.msg, nil, true); err == nil {
+			Fail(t, "version", version, "expected the corrupted payload to be rejected")
+		}
+		r.reader.data[r.cert.DataHash] = r.payload
+
+		var calls int
+		var firstPreimages daprovider.PreimagesMap
+		for i := 0; i < 2; i++ {
+			payload, preimages, err := reader.RecoverPayloadFromBatch(ctx, 1, common.Hash{}, r.msg, nil, true)
+			Require(t, err)
+			if !bytes.Equal(payload, r.payload) {
+				Fail(t, "version", version, "recovery", i, "got wrong payload", payload)
+			}
+			if i == 0 {
+				calls = len(r.reader.calls)
+				firstPreimages = preimages
+				continue
+			}
+			if len(r.reader.calls) != calls {
+				Fail(t, "version", version, "expected the repeat recovery not to fetch the payload, calls", r.reader.calls)
+			}
+			if !reflect.DeepEqual(preimages, firstPreimages) {
+				Fail(t, "version", version, "expected the repeat recovery to record the same preimages")
+			}
+		}
+	}
+}
+
+func TestRecoverPayloadEmpty(t *testing.T) {
+	ctx := context.Background()
+	for _, version := range []uint8{0, 1} {
+		r := newTestRecovery(t, []byte{}, version)
+		// Backends may return a stored empty value as nil, which must still count as recovered.
+		r.reader.data[r.cert.DataHash] = nil
+		payload, preimages, err := r.recover(ctx, nil, RecoveryOptions{})
+		Require(t, err)
+		if payload == nil || len(payload) != 0 {
+			Fail(t, "version", version, "expected an empty, non-nil payload, got", payload)
+		}
+		if _, ok := preimages[arbutil.Keccak256PreimageType][crypto.Keccak256Hash()]; !ok && version == 0 {
+			Fail(t, "version", version, "didn't record the preimage of the empty payload")
+		}
+		if len(preimages[arbutil.Keccak256PreimageType]) == 0 {
+			Fail(t, "version", version, "didn't record any preimages")
+		}
+
+		// A missing payload is an error rather than an empty payload.
+		delete(r.reader.data, r.cert.DataHash)
+		if payload, _, err := r.recover(ctx, nil, RecoveryOptions{}); err == nil {
+			Fail(t, "version", version, "expected a missing payload to fail, got", payload)
+		}
+	}
+}
+
+// delayedDASReader is a testDASReader taking delay to return each value.
+type delayedDASReader struct {
+	*testDASReader
+	delay time.Duration
+}
+
+func (r delayedDASReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	time.Sleep(r.delay)
+	return r.testDASReader.GetByHash(ctx, hash)
+}
+
+// delayedKeysetFetcher is a testKeysetFetcher taking delay to return each keyset.
+type delayedKeysetFetcher struct {
+	*testKeysetFetcher
+	delay time.Duration
+}
+
+func (f delayedKeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	time.Sleep(f.delay)
+	return f.testKeysetFetcher.GetKeysetByHash(ctx, hash)
+}
+
+func TestRecoverPayloadPhaseTimings(t *testing.T) {
+	ctx := context.Background()
+	const delay = 10 * time.Millisecond
+	for _, version := range []uint8{0, 1} {
+		r := newTestRecovery(t, []byte("a slow batch"), version)
+		reader := delayedDASReader{r.reader, delay}
+		fetcher := delayedKeysetFetcher{r.fetcher, delay}
+		var timings []RecoveryPhaseTimings
+		opts := RecoveryOptions{RecordPhaseTimings: func(phases RecoveryPhaseTimings) {
+			timings = append(timings, phases)
+		}}
+
+		payload, _, err := RecoverPayloadFromDasBatchWithOptions(ctx, 1, r.msg, reader, fetcher, nil, true, opts)
+		Require(t, err)
+		if !bytes.Equal(payload, r.payload) {
+			Fail(t, "version", version, "recovered wrong payload", payload)
+		}
+		if len(timings) != 1 {
+			Fail(t, "version", version, "expected the timings to be recorded once, got", len(timings))
+		}
+		phases := timings[0]
+		if phases.KeysetFetch < delay || phases.SignatureVerification <= 0 || phases.DataFetch < delay {
+			Fail(t, "version", version, "expected a duration for each phase, got", phases)
+		}
+
+		// The phases a failed recovery didn't reach aren't timed.
+		timings = nil
+		delete(r.fetcher.keysets, r.cert.KeysetHash)
+		if _, _, err := RecoverPayloadFromDasBatchWithOptions(ctx, 1, r.msg, reader, fetcher, nil, true, opts); err == nil {
+			Fail(t, "version", version, "expected the recovery to fail without its keyset")
+		}
+		if len(timings) != 1 || timings[0].KeysetFetch < delay || timings[0].SignatureVerification != 0 || timings[0].DataFetch != 0 {
+			Fail(t, "version", version, "expected only the keyset fetch to be timed, got", timings)
+		}
+	}
+}
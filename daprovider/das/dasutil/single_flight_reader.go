@@ -0,0 +1,57 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SingleFlightDASReader shares one fetch from the underlying reader among concurrent GetByHash
+// calls for the same hash, as when many recoveries of a sync reference the same data. Results are
+// only shared while the fetch is in flight, and calls for different hashes are never combined.
+// Each caller gets its own copy of the data, which recovery validates against the cert as usual.
+type SingleFlightDASReader struct {
+	reader DASReader
+	group  singleflight.Group
+}
+
+func NewSingleFlightDASReader(reader DASReader) *SingleFlightDASReader {
+	return &SingleFlightDASReader{reader: reader}
+}
+
+func (r *SingleFlightDASReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	for {
+		// The shared fetch runs with the context of the call that started it.
+		results := r.group.DoChan(string(hash.Bytes()), func() (interface{}, error) {
+			return r.reader.GetByHash(ctx, hash)
+		})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case res := <-results:
+			// Another caller's context ending mustn't fail this one, so fetch again.
+			if res.Shared && isContextError(res.Err) && ctx.Err() == nil {
+				continue
+			}
+			data, _ := res.Val.([]byte)
+			if res.Shared {
+				data = common.CopyBytes(data)
+			}
+			return data, res.Err
+		}
+	}
+}
+
+func (r *SingleFlightDASReader) ExpirationPolicy(ctx context.Context) (ExpirationPolicy, error) {
+	return r.reader.ExpirationPolicy(ctx)
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
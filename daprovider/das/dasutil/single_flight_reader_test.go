@@ -0,0 +1,126 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dasutil
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// gatedDASReader blocks reads until its gate is closed, so that concurrent reads overlap.
+type gatedDASReader struct {
+	*testDASReader
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func (r *gatedDASReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	r.started <- struct{}{}
+	select {
+	case <-r.gate:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return r.testDASReader.GetByHash(ctx, hash)
+}
+
+func TestSingleFlightDASReader(t *testing.T) {
+	ctx := context.Background()
+	const readers = 16
+	payload := []byte("data of a hot contract")
+	hash := CertDataHash(payload, 1)
+	backend := &gatedDASReader{
+		testDASReader: newTestDASReader(),
+		started:       make(chan struct{}, readers),
+		gate:          make(chan struct{}),
+	}
+	backend.data[hash] = payload
+	reader := NewSingleFlightDASReader(backend)
+
+	var wg sync.WaitGroup
+	results := make([][]byte, readers)
+	errs := make([]error, readers)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = reader.GetByHash(ctx, hash)
+		}(i)
+	}
+	<-backend.started
+	// Give the other callers time to join the fetch in flight before it completes.
+	time.Sleep(50 * time.Millisecond)
+	close(backend.gate)
+	wg.Wait()
+	for i := 0; i < readers; i++ {
+		Require(t, errs[i])
+		if !bytes.Equal(results[i], payload) {
+			Fail(t, "reader", i, "got wrong data", string(results[i]))
+		}
+	}
+	if len(backend.calls) != 1 {
+		Fail(t, "expected one backend call for concurrent identical fetches, got", len(backend.calls))
+	}
+	results[0][0] ^= 0xff
+	if !bytes.Equal(results[1], payload) {
+		Fail(t, "callers share the returned data")
+	}
+
+	// Results aren't kept once the fetch completes, and other hashes are fetched separately.
+	other := []byte("another payload")
+	otherHash := CertDataHash(other, 1)
+	backend.data[otherHash] = other
+	for _, h := range []common.Hash{hash, otherHash} {
+		_, err := reader.GetByHash(ctx, h)
+		Require(t, err)
+		<-backend.started
+	}
+	if len(backend.calls) != 3 {
+		Fail(t, "expected later and different fetches to reach the backend, got calls", backend.calls)
+	}
+}
+
+func TestSingleFlightDASReaderCanceledCaller(t *testing.T) {
+	payload := []byte("payload")
+	hash := CertDataHash(payload, 1)
+	backend := &gatedDASReader{
+		testDASReader: newTestDASReader(),
+		started:       make(chan struct{}, 2),
+		gate:          make(chan struct{}),
+	}
+	backend.data[hash] = payload
+	reader := NewSingleFlightDASReader(backend)
+
+	// The call starting the fetch is canceled, but the call sharing it fetches again.
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() {
+		_, err := reader.GetByHash(leaderCtx, hash)
+		leaderDone <- err
+	}()
+	<-backend.started
+	followerData := make(chan []byte, 1)
+	followerDone := make(chan error)
+	go func() {
+		data, err := reader.GetByHash(context.Background(), hash)
+		followerData <- data
+		followerDone <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-leaderDone; err == nil {
+		Fail(t, "expected the canceled call to fail")
+	}
+	<-backend.started
+	close(backend.gate)
+	Require(t, <-followerDone)
+	if data := <-followerData; !bytes.Equal(data, payload) {
+		Fail(t, "follower got wrong data", string(data))
+	}
+}
@@ -6,6 +6,7 @@ package das
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
@@ -43,6 +44,17 @@ var badgerDefaultOptions = badger.DefaultOptions("")
 
 const migratedMarker = "MIGRATED"
 
+// pinnedKeyPrefix prefixes the keys recording pinned values. The pin records hold the timeout the
+// value had when it was pinned, to be restored when it's unpinned.
+var pinnedKeyPrefix = []byte("pinned/")
+
+func pinnedKey(key common.Hash) []byte {
+	return append(append([]byte{}, pinnedKeyPrefix...), key.Bytes()...)
+}
+
+// badgerMaxEntryHeaderSize is the maximum size of the header BadgerDB writes before each value log entry.
+const badgerMaxEntryHeaderSize = 22
+
 var DefaultLocalDBStorageConfig = LocalDBStorageConfig{
 	Enable:              false,
 	DataDir:             "",
@@ -73,13 +85,15 @@ func LocalDBStorageConfigAddOptions(prefix string, f *flag.FlagSet) {
 type DBStorageService struct {
 	db                  *badger.DB
 	discardAfterTimeout bool
+	valueLogFileSize    int64
 	dirPath             string
 	stopWaiter          stopwaiter.StopWaiterSafe
 }
 
 // The DBStorageService is deprecated. This function will migrate data to the target
-// LocalFileStorageService if it is provided and migration hasn't already happened.
-func NewDBStorageService(ctx context.Context, config *LocalDBStorageConfig, target *LocalFileStorageService) (*DBStorageService, error) {
+// storage service, the LocalFileStorageService possibly wrapped to encrypt values, if it is provided
+// and migration hasn't already happened.
+func NewDBStorageService(ctx context.Context, config *LocalDBStorageConfig, target StorageService) (*DBStorageService, error) {
 	if alreadyMigrated(config.DataDir) {
 		log.Warn("local-db-storage already migrated, please remove it from the daserver configuration and restart. data-dir can be cleaned up manually now")
 		return nil, nil
@@ -103,6 +117,7 @@ func NewDBStorageService(ctx context.Context, config *LocalDBStorageConfig, targ
 	ret := &DBStorageService{
 		db:                  db,
 		discardAfterTimeout: config.DiscardAfterTimeout,
+		valueLogFileSize:    config.ValueLogFileSize,
 		dirPath:             config.DataDir,
 	}
 
@@ -174,15 +189,136 @@ func (dbs *DBStorageService) Put(ctx context.Context, data []byte, timeout uint6
 	logPut("das.DBStorageService.Put", data, timeout, dbs)
 
 	return dbs.db.Update(func(txn *badger.Txn) error {
-		e := badger.NewEntry(dastree.HashBytes(data), data)
-		if dbs.discardAfterTimeout && timeout <= math.MaxInt64 {
-			// #nosec G115
-			e = e.WithTTL(time.Until(time.Unix(int64(timeout), 0)))
+		key := common.BytesToHash(dastree.HashBytes(data))
+		pinnedTimeout, pinned, err := getPinnedTimeout(txn, key)
+		if err != nil {
+			return err
+		}
+		if pinned {
+			// Keep the value while it's pinned, and with the later of the timeouts once it's unpinned.
+			if timeout > pinnedTimeout {
+				if err := setPinnedTimeout(txn, key, timeout); err != nil {
+					return err
+				}
+			}
+			return txn.SetEntry(badger.NewEntry(key.Bytes(), data))
+		}
+		// A value stored again keeps the later of its timeouts, so storing it can't shorten its retention.
+		item, err := txn.Get(key.Bytes())
+		if err == nil && (item.ExpiresAt() == 0 || item.ExpiresAt() >= timeout) {
+			return nil
+		}
+		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+		return txn.SetEntry(dbs.newEntry(key, data, timeout))
+	})
+}
+
+func (dbs *DBStorageService) newEntry(key common.Hash, data []byte, timeout uint64) *badger.Entry {
+	e := badger.NewEntry(key.Bytes(), data)
+	if dbs.discardAfterTimeout && timeout <= math.MaxInt64 {
+		// #nosec G115
+		e = e.WithTTL(time.Until(time.Unix(int64(timeout), 0)))
+	}
+	return e
+}
+
+func getPinnedTimeout(txn *badger.Txn, key common.Hash) (uint64, bool, error) {
+	item, err := txn.Get(pinnedKey(key))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	var timeout uint64
+	err = item.Value(func(val []byte) error {
+		if len(val) != 8 {
+			return fmt.Errorf("invalid pin record for key %v", key)
+		}
+		timeout = binary.BigEndian.Uint64(val)
+		return nil
+	})
+	return timeout, true, err
+}
+
+func setPinnedTimeout(txn *badger.Txn, key common.Hash, timeout uint64) error {
+	return txn.Set(pinnedKey(key), binary.BigEndian.AppendUint64(nil, timeout))
+}
+
+// Pin removes the TTL of the stored value, recording its timeout to restore when it's unpinned.
+func (dbs *DBStorageService) Pin(ctx context.Context, key common.Hash) error {
+	log.Trace("das.DBStorageService.Pin", "key", pretty.PrettyHash(key), "this", dbs)
+	return dbs.db.Update(func(txn *badger.Txn) error {
+		if _, pinned, err := getPinnedTimeout(txn, key); err != nil || pinned {
+			return err
+		}
+		item, err := txn.Get(key.Bytes())
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		timeout := item.ExpiresAt()
+		if timeout == 0 {
+			timeout = math.MaxUint64
+		}
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if err := setPinnedTimeout(txn, key, timeout); err != nil {
+			return err
+		}
+		return txn.SetEntry(badger.NewEntry(key.Bytes(), value))
+	})
+}
+
+// Unpin restores the TTL the value had when it was pinned, deleting it if that timeout has passed.
+func (dbs *DBStorageService) Unpin(ctx context.Context, key common.Hash) error {
+	log.Trace("das.DBStorageService.Unpin", "key", pretty.PrettyHash(key), "this", dbs)
+	return dbs.db.Update(func(txn *badger.Txn) error {
+		timeout, pinned, err := getPinnedTimeout(txn, key)
+		if err != nil || !pinned {
+			return err
+		}
+		if err := txn.Delete(pinnedKey(key)); err != nil {
+			return err
+		}
+		item, err := txn.Get(key.Bytes())
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !dbs.discardAfterTimeout || timeout == math.MaxUint64 {
+			return nil
+		}
+		// #nosec G115
+		if timeout <= uint64(time.Now().Unix()) {
+			return txn.Delete(key.Bytes())
+		}
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
 		}
-		return txn.SetEntry(e)
+		return txn.SetEntry(dbs.newEntry(key, value, timeout))
 	})
 }
 
+// ValidatePut checks the value fits in a BadgerDB value log file along with its key.
+func (dbs *DBStorageService) ValidatePut(ctx context.Context, data []byte) error {
+	return checkPutSize(len(data), dbs.valueLogFileSize-int64(len(common.Hash{}))-badgerMaxEntryHeaderSize)
+}
+
+// Refresh rewrites the entry so that its TTL matches the new timeout, unless it already expires later.
+func (dbs *DBStorageService) Refresh(ctx context.Context, key common.Hash, timeout uint64) error {
+	return refreshByRestoring(ctx, dbs, key, timeout)
+}
+
 func (dbs *DBStorageService) migrateTo(ctx context.Context, s StorageService) error {
 	originExpirationPolicy, err := dbs.ExpirationPolicy(ctx)
 	if err != nil {
@@ -210,7 +346,15 @@ func (dbs *DBStorageService) migrateTo(ctx context.Context, s StorageService) er
 			}
 			item := it.Item()
 			k := item.Key()
+			if bytes.HasPrefix(k, pinnedKeyPrefix) {
+				continue
+			}
 			expiry := item.ExpiresAt()
+			if pinnedTimeout, pinned, err := getPinnedTimeout(txn, common.BytesToHash(k)); err != nil {
+				return err
+			} else if pinned {
+				expiry = pinnedTimeout
+			}
 			err := item.Value(func(v []byte) error {
 				log.Trace("migrated", "key", pretty.FirstFewBytes(k), "value", pretty.FirstFewBytes(v), "expiry", expiry)
 				return s.Put(ctx, v, expiry)
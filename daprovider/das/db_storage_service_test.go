@@ -0,0 +1,167 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v4"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+func TestDBStorageServiceValidatePut(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultLocalDBStorageConfig
+	config.DataDir = t.TempDir()
+	config.ValueLogFileSize = 1 << 20
+	dbService, err := NewDBStorageService(ctx, &config, nil)
+	Require(t, err)
+	defer func() {
+		Require(t, dbService.Close(ctx))
+	}()
+
+	valid := []byte("a valid value")
+	oversized := make([]byte, config.ValueLogFileSize)
+
+	if _, err := dbService.GetByHash(ctx, dastree.Hash(valid)); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected ErrNotFound, got", err)
+	}
+
+	Require(t, dbService.ValidatePut(ctx, valid))
+	if err := dbService.ValidatePut(ctx, oversized); !errors.Is(err, ErrValueTooLarge) {
+		Fail(t, "expected oversized value to be rejected, got", err)
+	}
+
+	// Decorators and redundant storage report their backends' rejections.
+	redundant, err := NewRedundantStorageService(ctx, []StorageService{NewMemoryBackedStorageService(ctx), dbService}, AggregateMostDurable)
+	Require(t, err)
+	cached := NewCacheStorageService(DefaultCacheConfig, redundant)
+	Require(t, cached.ValidatePut(ctx, valid))
+	if err := cached.ValidatePut(ctx, oversized); !errors.Is(err, ErrValueTooLarge) {
+		Fail(t, "expected oversized value to be rejected through the decorators, got", err)
+	}
+
+	// Validation must not have stored anything.
+	for _, value := range [][]byte{valid, oversized} {
+		if _, err := cached.GetByHash(ctx, dastree.Hash(value)); !errors.Is(err, ErrNotFound) {
+			Fail(t, "expected validated value not to be stored, got", err)
+		}
+	}
+}
+
+func TestDBStorageServiceRefresh(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultLocalDBStorageConfig
+	config.DataDir = t.TempDir()
+	config.DiscardAfterTimeout = true
+	dbService, err := NewDBStorageService(ctx, &config, nil)
+	Require(t, err)
+	defer func() {
+		Require(t, dbService.Close(ctx))
+	}()
+
+	expiresAt := func(key common.Hash) uint64 {
+		t.Helper()
+		var expiry uint64
+		Require(t, dbService.db.View(func(txn *badger.Txn) error {
+			item, err := txn.Get(key.Bytes())
+			if err != nil {
+				return err
+			}
+			expiry = item.ExpiresAt()
+			return nil
+		}))
+		return expiry
+	}
+
+	value := []byte("a value about to expire")
+	key := dastree.Hash(value)
+	// #nosec G115
+	nearExpiry := uint64(time.Now().Add(time.Minute).Unix())
+	Require(t, dbService.Put(ctx, value, nearExpiry))
+	if expiry := expiresAt(key); expiry > nearExpiry {
+		Fail(t, "unexpected initial expiry", expiry, "wanted at most", nearExpiry)
+	}
+
+	// #nosec G115
+	extended := uint64(time.Now().Add(time.Hour).Unix())
+	Require(t, dbService.Refresh(ctx, key, extended))
+	if expiry := expiresAt(key); expiry <= nearExpiry || expiry > extended {
+		Fail(t, "expected expiry to be extended to", extended, "got", expiry)
+	}
+	stored, err := dbService.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(stored, value) {
+		Fail(t, "refresh changed the stored value")
+	}
+
+	// Neither refreshing nor storing the value again with an earlier timeout shortens its retention.
+	Require(t, dbService.Refresh(ctx, key, nearExpiry))
+	Require(t, dbService.Put(ctx, value, nearExpiry))
+	if expiry := expiresAt(key); expiry <= nearExpiry {
+		Fail(t, "expected expiry to stay at", extended, "got", expiry)
+	}
+
+	if err := dbService.Refresh(ctx, dastree.Hash([]byte("never stored")), extended); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected refreshing a missing value to fail with ErrNotFound, got", err)
+	}
+}
+
+func TestDBStorageServicePin(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultLocalDBStorageConfig
+	config.DataDir = t.TempDir()
+	config.DiscardAfterTimeout = true
+	dbService, err := NewDBStorageService(ctx, &config, nil)
+	Require(t, err)
+
+	value := []byte("a preimage needed for a challenge")
+	key := dastree.Hash(value)
+	// #nosec G115
+	timeout := uint64(time.Now().Add(2 * time.Second).Unix())
+	Require(t, dbService.Put(ctx, value, timeout))
+	Require(t, dbService.Pin(ctx, key))
+	if err := dbService.Pin(ctx, dastree.Hash([]byte("never stored"))); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected pinning a missing value to fail with ErrNotFound, got", err)
+	}
+
+	// The pin survives a restart and outlives the original timeout.
+	Require(t, dbService.Close(ctx))
+	dbService, err = NewDBStorageService(ctx, &config, nil)
+	Require(t, err)
+	defer func() {
+		Require(t, dbService.Close(ctx))
+	}()
+	// #nosec G115
+	time.Sleep(time.Until(time.Unix(int64(timeout)+1, 0)))
+	stored, err := dbService.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(stored, value) {
+		Fail(t, "pinned value changed", stored)
+	}
+	// Storing it again doesn't undo the pin.
+	Require(t, dbService.Put(ctx, value, timeout))
+	if _, err := dbService.GetByHash(ctx, key); err != nil {
+		Fail(t, "expected pinned value to be kept after storing it again, got", err)
+	}
+
+	// Once unpinned, the original timeout applies again.
+	Require(t, dbService.Unpin(ctx, key))
+	if _, err := dbService.GetByHash(ctx, key); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected value to be discarded once unpinned after its timeout, got", err)
+	}
+}
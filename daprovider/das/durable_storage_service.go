@@ -0,0 +1,107 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+var ErrNoDurableStore = errors.New("data wasn't stored by any storage service keeping data forever")
+
+// DurableStorageService replicates data across a set of StorageServices like RedundantStorageService,
+// but only considers a Put successful once a service keeping data forever has stored it, so that data
+// is never reported stored while it only lives in an expiring cache. Failures of the other services
+// are logged but don't fail the Put. Services are classified by their ExpirationPolicy when the
+// DurableStorageService is created.
+type DurableStorageService struct {
+	*RedundantStorageService
+	durable   []StorageService
+	ephemeral []StorageService
+}
+
+func NewDurableStorageService(ctx context.Context, services []StorageService) (*DurableStorageService, error) {
+	d := &DurableStorageService{
+		RedundantStorageService: &RedundantStorageService{
+			innerServices:               slices.Clone(services),
+			expirationPolicyAggregation: AggregateMostDurable,
+		},
+	}
+	for _, s := range services {
+		expirationPolicy, err := s.ExpirationPolicy(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get expiration policy of %v: %w", s, err)
+		}
+		if expirationPolicy == dasutil.KeepForever {
+			d.durable = append(d.durable, s)
+		} else {
+			d.ephemeral = append(d.ephemeral, s)
+		}
+	}
+	if len(d.durable) == 0 {
+		return nil, fmt.Errorf("none of the storage services %v keeps data forever", services)
+	}
+	return d, nil
+}
+
+func (d *DurableStorageService) Put(ctx context.Context, data []byte, expirationTime uint64) error {
+	logPut("das.DurableStorageService.Store", data, expirationTime, d)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var durableErrs []error
+	put := func(s StorageService, durable bool) {
+		defer wg.Done()
+		err := s.Put(ctx, data, expirationTime)
+		if err == nil {
+			return
+		}
+		if !durable {
+			log.Warn("das.DurableStorageService.Store failed to store data in ephemeral storage", "storage", s, "err", err)
+			return
+		}
+		mutex.Lock()
+		durableErrs = append(durableErrs, fmt.Errorf("%v: %w", s, err))
+		mutex.Unlock()
+	}
+	wg.Add(len(d.durable) + len(d.ephemeral))
+	for _, s := range d.durable {
+		go put(s, true)
+	}
+	for _, s := range d.ephemeral {
+		go put(s, false)
+	}
+	wg.Wait()
+	if len(durableErrs) == len(d.durable) {
+		return fmt.Errorf("%w: %w", ErrNoDurableStore, errors.Join(durableErrs...))
+	}
+	for _, err := range durableErrs {
+		log.Warn("das.DurableStorageService.Store failed to store data in durable storage", "err", err)
+	}
+	return nil
+}
+
+// ValidatePut checks the value would be accepted by at least one of the services keeping data forever,
+// as Put only fails if none of them stores it.
+func (d *DurableStorageService) ValidatePut(ctx context.Context, data []byte) error {
+	var errs []error
+	for _, s := range d.durable {
+		err := s.ValidatePut(ctx, data)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%v: %w", s, err))
+	}
+	return fmt.Errorf("%w: %w", ErrNoDurableStore, errors.Join(errs...))
+}
+
+func (d *DurableStorageService) String() string {
+	return fmt.Sprintf("DurableStorageService(durable: %v, ephemeral: %v)", d.durable, d.ephemeral)
+}
@@ -0,0 +1,67 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+var errTestPutFailed = errors.New("put failed")
+
+// failingPutStorageService is a StorageService whose writes always fail.
+type failingPutStorageService struct {
+	StorageService
+}
+
+func (s *failingPutStorageService) Put(ctx context.Context, data []byte, expirationTime uint64) error {
+	return errTestPutFailed
+}
+
+func TestDurableStorageService(t *testing.T) {
+	ctx := context.Background()
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	value := []byte("batch data")
+	key := dastree.Hash(value)
+
+	ephemeral := withPolicy(ctx, dasutil.DiscardAfterDataTimeout)
+	failingDurable := &failingPutStorageService{withPolicy(ctx, dasutil.KeepForever)}
+	durableService, err := NewDurableStorageService(ctx, []StorageService{ephemeral, failingDurable})
+	Require(t, err)
+	// Only the ephemeral service stores the data, which isn't enough.
+	if err := durableService.Put(ctx, value, timeout); !errors.Is(err, ErrNoDurableStore) {
+		Fail(t, "expected put stored only in ephemeral storage to fail, got", err)
+	}
+	if _, err := ephemeral.GetByHash(ctx, key); err != nil {
+		Fail(t, "expected the ephemeral service to have stored the data anyway, got", err)
+	}
+
+	// A single durable service storing the data is enough, even if the others fail.
+	durable := withPolicy(ctx, dasutil.KeepForever)
+	failingEphemeral := &failingPutStorageService{withPolicy(ctx, dasutil.DiscardAfterDataTimeout)}
+	durableService, err = NewDurableStorageService(ctx, []StorageService{failingEphemeral, failingDurable, durable})
+	Require(t, err)
+	Require(t, durableService.Put(ctx, value, timeout))
+	stored, err := durableService.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(stored, value) {
+		Fail(t, "unexpected stored value", stored)
+	}
+	policy, err := durableService.ExpirationPolicy(ctx)
+	Require(t, err)
+	if policy != dasutil.KeepForever {
+		Fail(t, "expected durable storage to keep data forever, got policy", policy)
+	}
+
+	if _, err := NewDurableStorageService(ctx, []StorageService{ephemeral}); err == nil {
+		Fail(t, "expected storage without durable services to be rejected")
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+	"github.com/offchainlabs/nitro/util/pretty"
+)
+
+type EncryptionConfig struct {
+	Enable    bool   `koanf:"enable"`
+	KeyConfig string `koanf:"key-config"`
+}
+
+var DefaultEncryptionConfig = EncryptionConfig{}
+
+func EncryptionConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultEncryptionConfig.Enable, "enable AES-GCM encryption of stored batch data at rest, in every storage backend and in the Redis cache")
+	f.String(prefix+".key-config", DefaultEncryptionConfig.KeyConfig, "AES-256 encryption key, either as 32 bytes of hex or as the path to a file containing it")
+}
+
+var ErrDecryptionFailed = errors.New("failed to decrypt stored value")
+
+// valueSealer AES-GCM encrypts values stored under a key. The random nonce is stored in front of the
+// ciphertext, and the key is used as additional authenticated data so that ciphertexts can't be
+// swapped between keys.
+type valueSealer struct {
+	aead cipher.AEAD
+}
+
+func newValueSealer(config EncryptionConfig) (*valueSealer, error) {
+	key, err := secretKeyFromConfig(config.KeyConfig)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &valueSealer{aead: aead}, nil
+}
+
+func (s *valueSealer) seal(key common.Hash, value []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, value, key[:]), nil
+}
+
+func (s *valueSealer) open(key common.Hash, sealed []byte) ([]byte, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("%w: value for key %v is too short to contain a nonce", ErrDecryptionFailed, key)
+	}
+	plaintext, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], key[:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %v: %w", ErrDecryptionFailed, key, err)
+	}
+	return plaintext, nil
+}
+
+// overhead is the number of bytes sealing adds to a value.
+func (s *valueSealer) overhead() int {
+	return s.aead.NonceSize() + s.aead.Overhead()
+}
+
+// EncryptingStorageService is a StorageService that AES-GCM encrypts values before storing them in
+// its base storage. Values are stored under the dastree hash of the plaintext, so they remain
+// addressable by content hash for recovery. Values stored in plaintext before encryption was enabled
+// are still read, as long as they match the hash they're stored under.
+type EncryptingStorageService struct {
+	baseStorageService KeyedStorageService
+	sealer             *valueSealer
+}
+
+func NewEncryptingStorageService(config EncryptionConfig, baseStorageService KeyedStorageService) (*EncryptingStorageService, error) {
+	sealer, err := newValueSealer(config)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptingStorageService{
+		baseStorageService: baseStorageService,
+		sealer:             sealer,
+	}, nil
+}
+
+func (e *EncryptingStorageService) GetByHash(ctx context.Context, key common.Hash) ([]byte, error) {
+	log.Trace("das.EncryptingStorageService.GetByHash", "key", pretty.PrettyHash(key), "this", e)
+	sealed, err := e.baseStorageService.GetByHash(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := e.sealer.open(key, sealed)
+	if err != nil && dastree.ValidHash(key, sealed) {
+		// The value was stored before encryption was enabled.
+		log.Debug("Read unencrypted value", "key", pretty.PrettyHash(key))
+		return sealed, nil
+	}
+	return value, err
+}
+
+func (e *EncryptingStorageService) Put(ctx context.Context, value []byte, timeout uint64) error {
+	logPut("das.EncryptingStorageService.Store", value, timeout, e)
+	key := dastree.Hash(value)
+	sealed, err := e.sealer.seal(key, value)
+	if err != nil {
+		return err
+	}
+	return e.baseStorageService.PutWithKey(ctx, key, sealed, timeout)
+}
+
+// ValidatePut checks the base storage accepts a value of the size the encrypted value would have.
+func (e *EncryptingStorageService) ValidatePut(ctx context.Context, value []byte) error {
+	return e.baseStorageService.ValidatePutSize(ctx, len(value)+e.sealer.overhead())
+}
+
+// Refresh decrypts and re-encrypts the value, as the base storage can't check the sealed value
+// against the key it's stored under.
+func (e *EncryptingStorageService) Refresh(ctx context.Context, key common.Hash, timeout uint64) error {
+	return refreshByRestoring(ctx, e, key, timeout)
+}
+
+func (e *EncryptingStorageService) Sync(ctx context.Context) error {
+	return e.baseStorageService.Sync(ctx)
+}
+
+func (e *EncryptingStorageService) Close(ctx context.Context) error {
+	return e.baseStorageService.Close(ctx)
+}
+
+func (e *EncryptingStorageService) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
+	return e.baseStorageService.ExpirationPolicy(ctx)
+}
+
+func (e *EncryptingStorageService) String() string {
+	return fmt.Sprintf("EncryptingStorageService(%v)", e.baseStorageService)
+}
+
+func (e *EncryptingStorageService) HealthCheck(ctx context.Context) error {
+	return e.baseStorageService.HealthCheck(ctx)
+}
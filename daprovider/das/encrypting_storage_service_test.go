@@ -0,0 +1,127 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+const testEncryptionKey = "0b0c67f1f2a1ba5a4d4dc45bd1c6a6cb2e35a1d48b09ad2f0a95fbbba3bcb2e1"
+
+func TestEncryptingStorageService(t *testing.T) {
+	ctx := context.Background()
+	base := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+	encrypting, err := NewEncryptingStorageService(EncryptionConfig{Enable: true, KeyConfig: testEncryptionKey}, base)
+	Require(t, err)
+
+	val := []byte("The value to encrypt")
+	key := dastree.Hash(val)
+
+	_, err = encrypting.GetByHash(ctx, key)
+	if !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected ErrNotFound, got", err)
+	}
+
+	Require(t, encrypting.Put(ctx, val, 0))
+	res, err := encrypting.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(res, val) {
+		Fail(t, "round trip mismatch", res, val)
+	}
+
+	// The base storage holds the ciphertext under the plaintext hash.
+	stored, err := base.GetByHash(ctx, key)
+	Require(t, err)
+	if bytes.Contains(stored, val) {
+		Fail(t, "value stored in plaintext")
+	}
+
+	// Encrypting the same value again uses a fresh nonce.
+	Require(t, encrypting.Put(ctx, val, 0))
+	restored, err := base.GetByHash(ctx, key)
+	Require(t, err)
+	if bytes.Equal(stored, restored) {
+		Fail(t, "nonce was reused")
+	}
+}
+
+func TestEncryptingStorageServiceWrongKey(t *testing.T) {
+	ctx := context.Background()
+	base := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+	encrypting, err := NewEncryptingStorageService(EncryptionConfig{Enable: true, KeyConfig: testEncryptionKey}, base)
+	Require(t, err)
+	otherKey := "1b0c67f1f2a1ba5a4d4dc45bd1c6a6cb2e35a1d48b09ad2f0a95fbbba3bcb2e1"
+	wrongEncrypting, err := NewEncryptingStorageService(EncryptionConfig{Enable: true, KeyConfig: otherKey}, base)
+	Require(t, err)
+
+	val := []byte("The value to encrypt")
+	Require(t, encrypting.Put(ctx, val, 0))
+
+	_, err = wrongEncrypting.GetByHash(ctx, dastree.Hash(val))
+	if !errors.Is(err, ErrDecryptionFailed) {
+		Fail(t, "expected ErrDecryptionFailed, got", err)
+	}
+
+	// A truncated value fails cleanly rather than panicking.
+	Require(t, base.PutWithKey(ctx, dastree.Hash(val), []byte{1, 2, 3}, 0))
+	_, err = encrypting.GetByHash(ctx, dastree.Hash(val))
+	if !errors.Is(err, ErrDecryptionFailed) {
+		Fail(t, "expected ErrDecryptionFailed, got", err)
+	}
+}
+
+func TestEncryptingStorageServiceReadsUnencryptedValues(t *testing.T) {
+	ctx := context.Background()
+	base := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+	encrypting, err := NewEncryptingStorageService(EncryptionConfig{Enable: true, KeyConfig: testEncryptionKey}, base)
+	Require(t, err)
+
+	// A value stored before encryption was enabled is still read.
+	val := []byte("A value stored before encryption was enabled")
+	Require(t, base.Put(ctx, val, 0))
+	res, err := encrypting.GetByHash(ctx, dastree.Hash(val))
+	Require(t, err)
+	if !bytes.Equal(res, val) {
+		Fail(t, "unencrypted value mismatch", res, val)
+	}
+
+	// A value which neither decrypts nor matches its key isn't.
+	Require(t, base.PutWithKey(ctx, dastree.Hash(val), []byte("some other value"), 0))
+	_, err = encrypting.GetByHash(ctx, dastree.Hash(val))
+	if !errors.Is(err, ErrDecryptionFailed) {
+		Fail(t, "expected ErrDecryptionFailed, got", err)
+	}
+}
+
+// sizeLimitedStorageService is a MemoryBackedStorageService rejecting values larger than maxSize.
+type sizeLimitedStorageService struct {
+	*MemoryBackedStorageService
+	maxSize int64
+}
+
+func (s *sizeLimitedStorageService) ValidatePutSize(ctx context.Context, size int) error {
+	return checkPutSize(size, s.maxSize)
+}
+
+func TestEncryptingStorageServiceValidatePut(t *testing.T) {
+	ctx := context.Background()
+	base := &sizeLimitedStorageService{
+		MemoryBackedStorageService: NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService),
+		maxSize:                    100,
+	}
+	encrypting, err := NewEncryptingStorageService(EncryptionConfig{Enable: true, KeyConfig: testEncryptionKey}, base)
+	Require(t, err)
+
+	// The base storage checks the size of the encrypted value.
+	fitting := make([]byte, 100-encrypting.sealer.overhead())
+	Require(t, encrypting.ValidatePut(ctx, fitting))
+	if err := encrypting.ValidatePut(ctx, append(fitting, 0)); !errors.Is(err, ErrValueTooLarge) {
+		Fail(t, "expected a value too large once encrypted to be rejected, got", err)
+	}
+}
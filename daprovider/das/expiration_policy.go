@@ -0,0 +1,105 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+// ExpirationPolicyAggregation selects how a storage service composed of several backends
+// reports a single ExpirationPolicy. It applies to RedundantStorageService, which writes to all of
+// its backends, and to the chained decorators keeping data themselves in front of a base storage:
+// CacheStorageService, RedisStorageService and FallbackStorageService. Decorators that don't keep
+// data themselves, such as EncryptingStorageService, report the policy of their base.
+type ExpirationPolicyAggregation int
+
+const (
+	// AggregateMostDurable reports the policy of the most durable backend, since data
+	// written to every backend survives at least as long as it does there.
+	AggregateMostDurable ExpirationPolicyAggregation = iota
+	// AggregateLeastDurable reports the policy of the least durable backend, which is
+	// how long data is guaranteed to be available from every backend.
+	AggregateLeastDurable
+)
+
+// ParseExpirationPolicyAggregation parses an aggregation mode; an empty string selects the default, most-durable.
+func ParseExpirationPolicyAggregation(s string) (ExpirationPolicyAggregation, error) {
+	switch s {
+	case "", "most-durable":
+		return AggregateMostDurable, nil
+	case "least-durable":
+		return AggregateLeastDurable, nil
+	default:
+		return -1, fmt.Errorf("invalid expiration policy aggregation: %s", s)
+	}
+}
+
+// cacheTierExpirationPolicy is the policy of a cache tier in front of a base storage. Caches drop
+// data whenever they need room or their own expiration passes, so they don't guarantee keeping it
+// at all: the tier only makes a chain less durable under AggregateLeastDurable.
+const cacheTierExpirationPolicy = dasutil.DiscardImmediately
+
+// expirationPolicyDurability ranks expiration policies from least to most durable, by how long data
+// is guaranteed to be kept:
+//
+//	DiscardImmediately < DiscardAfterDataTimeout < MixedTimeout < DiscardAfterArchiveTimeout < KeepForever
+//
+// The archive timeout is assumed to be at least as long as the data timeout. MixedTimeout is
+// reported by services whose backends mix the two timeouts, so some of its data is kept only until
+// the data timeout and the rest until the archive timeout: it ranks above DiscardAfterDataTimeout,
+// as it may keep data longer, and below DiscardAfterArchiveTimeout, as it may not.
+func expirationPolicyDurability(policy dasutil.ExpirationPolicy) (int, error) {
+	switch policy {
+	case dasutil.DiscardImmediately:
+		return 0, nil
+	case dasutil.DiscardAfterDataTimeout:
+		return 1, nil
+	case dasutil.MixedTimeout:
+		return 2, nil
+	case dasutil.DiscardAfterArchiveTimeout:
+		return 3, nil
+	case dasutil.KeepForever:
+		return 4, nil
+	default:
+		return -1, errors.New("unknown expiration policy")
+	}
+}
+
+// AggregateExpirationPolicies combines the expiration policies of several backends into one, the
+// most or least durable of them by the ranking of expirationPolicyDurability.
+func AggregateExpirationPolicies(aggregation ExpirationPolicyAggregation, policies ...dasutil.ExpirationPolicy) (dasutil.ExpirationPolicy, error) {
+	if len(policies) == 0 {
+		return -1, errors.New("no expiration policies to aggregate")
+	}
+	res := policies[0]
+	resDurability, err := expirationPolicyDurability(res)
+	if err != nil {
+		return -1, err
+	}
+	for _, policy := range policies[1:] {
+		durability, err := expirationPolicyDurability(policy)
+		if err != nil {
+			return -1, err
+		}
+		if (aggregation == AggregateMostDurable && durability > resDurability) ||
+			(aggregation == AggregateLeastDurable && durability < resDurability) {
+			res, resDurability = policy, durability
+		}
+	}
+	return res, nil
+}
+
+// chainedExpirationPolicy returns the policy of a decorator keeping data under tierPolicy in front
+// of base, aggregating it with the policy of base.
+func chainedExpirationPolicy(ctx context.Context, aggregation ExpirationPolicyAggregation, tierPolicy dasutil.ExpirationPolicy, base dasutil.DASReader) (dasutil.ExpirationPolicy, error) {
+	basePolicy, err := base.ExpirationPolicy(ctx)
+	if err != nil {
+		return -1, err
+	}
+	return AggregateExpirationPolicies(aggregation, basePolicy, tierPolicy)
+}
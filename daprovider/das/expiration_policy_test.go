@@ -0,0 +1,181 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+type fixedPolicyStorageService struct {
+	StorageService
+	policy dasutil.ExpirationPolicy
+}
+
+func (s *fixedPolicyStorageService) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
+	return s.policy, nil
+}
+
+func withPolicy(ctx context.Context, policy dasutil.ExpirationPolicy) StorageService {
+	return &fixedPolicyStorageService{NewMemoryBackedStorageService(ctx), policy}
+}
+
+func TestRedundantStorageExpirationPolicyAggregation(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		policies     []dasutil.ExpirationPolicy
+		mostDurable  dasutil.ExpirationPolicy
+		leastDurable dasutil.ExpirationPolicy
+	}{
+		{
+			policies:     []dasutil.ExpirationPolicy{dasutil.DiscardAfterDataTimeout, dasutil.KeepForever},
+			mostDurable:  dasutil.KeepForever,
+			leastDurable: dasutil.DiscardAfterDataTimeout,
+		},
+		{
+			policies:     []dasutil.ExpirationPolicy{dasutil.DiscardAfterArchiveTimeout, dasutil.DiscardAfterDataTimeout, dasutil.MixedTimeout},
+			mostDurable:  dasutil.DiscardAfterArchiveTimeout,
+			leastDurable: dasutil.DiscardAfterDataTimeout,
+		},
+		{
+			policies:     []dasutil.ExpirationPolicy{dasutil.KeepForever, dasutil.DiscardImmediately},
+			mostDurable:  dasutil.KeepForever,
+			leastDurable: dasutil.DiscardImmediately,
+		},
+		{
+			policies:     []dasutil.ExpirationPolicy{dasutil.KeepForever, dasutil.KeepForever},
+			mostDurable:  dasutil.KeepForever,
+			leastDurable: dasutil.KeepForever,
+		},
+	}
+	for i, tc := range testCases {
+		var services []StorageService
+		for _, policy := range tc.policies {
+			services = append(services, withPolicy(ctx, policy))
+		}
+		for aggregation, expected := range map[ExpirationPolicyAggregation]dasutil.ExpirationPolicy{
+			AggregateMostDurable:  tc.mostDurable,
+			AggregateLeastDurable: tc.leastDurable,
+		} {
+			redundantService, err := NewRedundantStorageService(ctx, services, aggregation)
+			Require(t, err)
+			policy, err := redundantService.ExpirationPolicy(ctx)
+			Require(t, err)
+			if policy != expected {
+				Fail(t, "test case", i, "aggregation", aggregation, "expected", expected, "got", policy)
+			}
+		}
+	}
+}
+
+func TestExpirationPolicyAggregationOfChainedCache(t *testing.T) {
+	ctx := context.Background()
+	// An ephemeral cache in front of a durable base reports the durability of the base.
+	cache := NewCacheStorageService(TestCacheConfig, withPolicy(ctx, dasutil.KeepForever))
+	policy, err := cache.ExpirationPolicy(ctx)
+	Require(t, err)
+	if policy != dasutil.KeepForever {
+		Fail(t, "expected chained cache to report the base's policy, got", policy)
+	}
+}
+
+func TestExpirationPolicyAggregationOfDecorators(t *testing.T) {
+	ctx := context.Background()
+	server, err := miniredis.Run()
+	Require(t, err)
+	defer server.Close()
+	redisConfig := RedisConfig{
+		Enable:     true,
+		Url:        "redis://" + server.Addr(),
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+	}
+	type aggregatingService interface {
+		StorageService
+		SetExpirationPolicyAggregation(aggregation ExpirationPolicyAggregation)
+	}
+	decorators := map[string]func(base StorageService) aggregatingService{
+		"cache": func(base StorageService) aggregatingService {
+			return NewCacheStorageService(TestCacheConfig, base)
+		},
+		"redis": func(base StorageService) aggregatingService {
+			redisService, err := NewRedisStorageService(redisConfig, base)
+			Require(t, err)
+			return redisService.(*RedisStorageService)
+		},
+		"fallback": func(base StorageService) aggregatingService {
+			// The primary keeps data until the data timeout, the backup is base.
+			return NewFallbackStorageService(withPolicy(ctx, dasutil.DiscardAfterDataTimeout), base, nil, 60*60, true, true)
+		},
+	}
+	for name, decorate := range decorators {
+		// Caches keep no data of their own, and the fallback's primary keeps it until the data
+		// timeout, so they're the least durable tier of the chain.
+		leastDurable := cacheTierExpirationPolicy
+		if name == "fallback" {
+			leastDurable = dasutil.DiscardAfterDataTimeout
+		}
+		for aggregation, expected := range map[ExpirationPolicyAggregation]dasutil.ExpirationPolicy{
+			AggregateMostDurable:  dasutil.KeepForever,
+			AggregateLeastDurable: leastDurable,
+		} {
+			service := decorate(withPolicy(ctx, dasutil.KeepForever))
+			service.SetExpirationPolicyAggregation(aggregation)
+			policy, err := service.ExpirationPolicy(ctx)
+			Require(t, err)
+			if policy != expected {
+				Fail(t, name, "with aggregation", aggregation, "expected", expected, "got", policy)
+			}
+		}
+	}
+
+	// Decorators that don't keep data themselves report the policy of their base.
+	verifying := NewVerifyingStorageService(withPolicy(ctx, dasutil.DiscardAfterArchiveTimeout))
+	policy, err := verifying.ExpirationPolicy(ctx)
+	Require(t, err)
+	if policy != dasutil.DiscardAfterArchiveTimeout {
+		Fail(t, "expected the verifying decorator to report its base's policy, got", policy)
+	}
+}
+
+func TestExpirationPolicyDurabilityOrder(t *testing.T) {
+	ordered := []dasutil.ExpirationPolicy{
+		dasutil.DiscardImmediately,
+		dasutil.DiscardAfterDataTimeout,
+		dasutil.MixedTimeout,
+		dasutil.DiscardAfterArchiveTimeout,
+		dasutil.KeepForever,
+	}
+	for i := 1; i < len(ordered); i++ {
+		less, err := expirationPolicyDurability(ordered[i-1])
+		Require(t, err)
+		more, err := expirationPolicyDurability(ordered[i])
+		Require(t, err)
+		if less >= more {
+			Fail(t, "expected", ordered[i-1], "to be less durable than", ordered[i])
+		}
+	}
+}
+
+func TestParseExpirationPolicyAggregation(t *testing.T) {
+	for s, expected := range map[string]ExpirationPolicyAggregation{
+		"":              AggregateMostDurable,
+		"most-durable":  AggregateMostDurable,
+		"least-durable": AggregateLeastDurable,
+	} {
+		aggregation, err := ParseExpirationPolicyAggregation(s)
+		Require(t, err)
+		if aggregation != expected {
+			Fail(t, "unexpected aggregation for", s, aggregation)
+		}
+	}
+	if _, err := ParseExpirationPolicyAggregation("most-conservative"); err == nil {
+		Fail(t, "expected invalid aggregation to be rejected")
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 
+	"github.com/offchainlabs/nitro/daprovider"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/util/headerreader"
 	"github.com/offchainlabs/nitro/util/signature"
@@ -23,6 +26,7 @@ func CreatePersistentStorageService(
 	config *DataAvailabilityConfig,
 ) (StorageService, *LifecycleManager, error) {
 	storageServices := make([]StorageService, 0, 10)
+	healthCheckers := make(map[string]DataAvailabilityServiceHealthChecker)
 	var lifecycleManager LifecycleManager
 	var err error
 
@@ -38,21 +42,30 @@ func CreatePersistentStorageService(
 		}
 		lifecycleManager.Register(fs)
 		storageServices = append(storageServices, fs)
+		healthCheckers["local-file-storage"] = fs
 	}
 
 	if config.LocalDBStorage.Enable {
-		var s *DBStorageService
-		if config.MigrateLocalDBToFileStorage {
-			s, err = NewDBStorageService(ctx, &config.LocalDBStorage, fs)
-		} else {
-			s, err = NewDBStorageService(ctx, &config.LocalDBStorage, nil)
+		var migrationTarget StorageService
+		if config.MigrateLocalDBToFileStorage && fs != nil {
+			migrationTarget = fs
+			if config.Encryption.Enable {
+				// The migrated values are encrypted, as the file storage is wrapped below to read
+				// them back through decryption.
+				migrationTarget, err = NewEncryptingStorageService(config.Encryption, fs)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
 		}
+		s, err := NewDBStorageService(ctx, &config.LocalDBStorage, migrationTarget)
 		if err != nil {
 			return nil, nil, err
 		}
 		if s != nil {
 			lifecycleManager.Register(s)
 			storageServices = append(storageServices, s)
+			healthCheckers["local-db-storage"] = s
 		}
 	}
 
@@ -63,6 +76,7 @@ func CreatePersistentStorageService(
 		}
 		lifecycleManager.Register(s)
 		storageServices = append(storageServices, s)
+		healthCheckers["s3-storage"] = s
 	}
 
 	if config.GoogleCloudStorage.Enable {
@@ -72,24 +86,80 @@ func CreatePersistentStorageService(
 		}
 		lifecycleManager.Register(s)
 		storageServices = append(storageServices, s)
+		healthCheckers["google-cloud-storage"] = s
+	}
+
+	if config.Encryption.Enable {
+		// Every backend is encrypted, so that no backend holds data in plaintext.
+		for i, storageService := range storageServices {
+			keyedStorageService, ok := storageService.(KeyedStorageService)
+			if !ok {
+				return nil, nil, fmt.Errorf("data-availability.encryption is not supported by %v", storageService)
+			}
+			s, err := NewEncryptingStorageService(config.Encryption, keyedStorageService)
+			if err != nil {
+				return nil, nil, err
+			}
+			storageServices[i] = s
+		}
 	}
 
+	if len(storageServices) > 0 && !config.AllowEphemeralStorage {
+		if err := requireDurableStorage(ctx, storageServices); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(storageServices) == 0 {
+		return nil, nil, errors.New("No data-availability storage backend has been configured")
+	}
+	storageService := storageServices[0]
 	if len(storageServices) > 1 {
-		s, err := NewRedundantStorageService(ctx, storageServices)
+		expirationPolicyAggregation, err := ParseExpirationPolicyAggregation(config.ExpirationPolicyAggregation)
+		if err != nil {
+			return nil, nil, err
+		}
+		s, err := NewRedundantStorageService(ctx, storageServices, expirationPolicyAggregation)
 		if err != nil {
 			return nil, nil, err
 		}
 		lifecycleManager.Register(s)
-		return s, &lifecycleManager, nil
-	}
-	if len(storageServices) == 1 {
-		return storageServices[0], &lifecycleManager, nil
+		storageService = s
 	}
-	if len(storageServices) == 0 {
-		return nil, nil, errors.New("No data-availability storage backend has been configured")
+
+	// The health check scheduler is started once nothing else can fail, as its goroutine would
+	// otherwise be leaked along with the lifecycle manager.
+	if config.HealthCheck.Enable && len(healthCheckers) > 0 {
+		scheduler, err := NewHealthCheckScheduler(config.HealthCheck, config.MetricsNamespace, healthCheckers)
+		if err != nil {
+			return nil, nil, err
+		}
+		scheduler.Start(ctx)
+		lifecycleManager.Register(scheduler)
 	}
 
-	return nil, &lifecycleManager, nil
+	return storageService, &lifecycleManager, nil
+}
+
+// requireDurableStorage fails unless at least one of the storage backends keeps data forever, as
+// otherwise everything stored is eventually lost.
+func requireDurableStorage(ctx context.Context, storageServices []StorageService) error {
+	var policies []string
+	for _, s := range storageServices {
+		policy, err := s.ExpirationPolicy(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get expiration policy of %v: %w", s, err)
+		}
+		if policy == dasutil.KeepForever {
+			return nil
+		}
+		policyString, err := policy.String()
+		if err != nil {
+			return err
+		}
+		policies = append(policies, fmt.Sprintf("%v: %s", s, policyString))
+	}
+	return fmt.Errorf("no data-availability storage backend keeps data forever (%s), set data-availability.allow-ephemeral-storage to start anyway", strings.Join(policies, ", "))
 }
 
 func WrapStorageWithCache(
@@ -102,16 +172,35 @@ func WrapStorageWithCache(
 	}
 
 	// Enable caches, Redis and (local) Cache. Local is the outermost, so it will be tried first.
-	var err error
+	expirationPolicyAggregation, err := ParseExpirationPolicyAggregation(config.ExpirationPolicyAggregation)
+	if err != nil {
+		return nil, err
+	}
 	if config.RedisCache.Enable {
-		storageService, err = NewRedisStorageService(config.RedisCache, storageService)
-		lifecycleManager.Register(storageService)
+		// The storage service encrypts values itself if encryption is enabled, but Redis caches the
+		// values it's given, so it encrypts them too.
+		if config.Encryption.Enable {
+			storageService, err = NewEncryptingRedisStorageService(config.RedisCache, config.Encryption, storageService)
+		} else {
+			storageService, err = NewRedisStorageService(config.RedisCache, storageService)
+		}
 		if err != nil {
 			return nil, err
 		}
+		redisService := storageService.(*RedisStorageService)
+		redisService.SetExpirationPolicyAggregation(expirationPolicyAggregation)
+		if config.RedisCache.Sweep.Enable {
+			// The sweeper is registered first, so that it's stopped before the Redis client is closed.
+			sweeper := NewRedisSweeper(redisService, config.RedisCache.Sweep, config.MetricsNamespace)
+			sweeper.Start(ctx)
+			lifecycleManager.Register(sweeper)
+		}
+		lifecycleManager.Register(storageService)
 	}
 	if config.LocalCache.Enable {
-		storageService = NewCacheStorageService(config.LocalCache, storageService)
+		cacheService := NewCacheStorageService(config.LocalCache, storageService)
+		cacheService.SetExpirationPolicyAggregation(expirationPolicyAggregation)
+		storageService = cacheService
 		lifecycleManager.Register(storageService)
 	}
 	return storageService, nil
@@ -129,6 +218,9 @@ func CreateDAReaderAndWriter(
 	}
 
 	// Check config requirements
+	if config.Encryption.Enable {
+		return nil, nil, nil, nil, errors.New("data-availability.encryption only applies to local storage backends, which a Batch Poster in AnyTrust mode doesn't use")
+	}
 	if !config.RPCAggregator.Enable || !config.RestAggregator.Enable {
 		return nil, nil, nil, nil, errors.New("--node.data-availability.rpc-aggregator.enable and rest-aggregator.enable must be set when running a Batch Poster in AnyTrust mode")
 	}
@@ -148,7 +240,7 @@ func CreateDAReaderAndWriter(
 	var lifecycleManager LifecycleManager
 	lifecycleManager.Register(restAgg)
 	var daReader DataAvailabilityServiceReader = restAgg
-	keysetFetcher, err := NewKeysetFetcher(l1Reader, sequencerInboxAddr)
+	keysetFetcher, err := NewKeysetFetcher(l1Reader, sequencerInboxAddr, config.keysetCacheConfig())
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
@@ -196,12 +288,16 @@ func CreateDAComponentsForDaserver(
 
 		syncConf := &config.RestAggregator.SyncToStorage
 		retentionPeriodSeconds := uint64(syncConf.RetentionPeriod.Seconds())
+		expirationPolicyAggregation, err := ParseExpirationPolicyAggregation(config.ExpirationPolicyAggregation)
+		if err != nil {
+			return nil, nil, nil, nil, nil, err
+		}
 
 		if syncConf.Eager {
 			if l1Reader == nil || seqInboxAddress == nil {
 				return nil, nil, nil, nil, nil, errors.New("l1-node-url and sequencer-inbox-address must be specified along with sync-to-storage.eager")
 			}
-			storageService, err = NewSyncingFallbackStorageService(
+			syncingService, err := NewSyncingFallbackStorageService(
 				ctx,
 				storageService,
 				restAgg,
@@ -209,13 +305,17 @@ func CreateDAComponentsForDaserver(
 				l1Reader,
 				*seqInboxAddress,
 				syncConf)
-			dasLifecycleManager.Register(storageService)
 			if err != nil {
 				return nil, nil, nil, nil, nil, err
 			}
+			syncingService.SetExpirationPolicyAggregation(expirationPolicyAggregation)
+			storageService = syncingService
+			dasLifecycleManager.Register(storageService)
 		} else {
-			storageService = NewFallbackStorageService(storageService, restAgg, restAgg,
+			fallbackService := NewFallbackStorageService(storageService, restAgg, restAgg,
 				retentionPeriodSeconds, syncConf.IgnoreWriteErrors, true)
+			fallbackService.SetExpirationPolicyAggregation(expirationPolicyAggregation)
+			storageService = fallbackService
 			dasLifecycleManager.Register(storageService)
 		}
 
@@ -271,6 +371,9 @@ func CreateDAReader(
 	if config.RPCAggregator.Enable {
 		return nil, nil, nil, errors.New("node.data-availability.rpc-aggregator is only for Batch Poster mode")
 	}
+	if config.Encryption.Enable {
+		return nil, nil, nil, errors.New("data-availability.encryption only applies to local storage backends, which a node reading from the REST aggregator doesn't use")
+	}
 
 	if !config.RestAggregator.Enable {
 		return nil, nil, nil, fmt.Errorf("--node.data-availability.enable was set but not --node.data-availability.rest-aggregator. When running a Nitro Anytrust node in non-Batch Poster mode, some way to get the batch data is required.")
@@ -296,7 +399,7 @@ func CreateDAReader(
 		if err != nil {
 			return nil, nil, nil, err
 		}
-		keysetFetcher, err = NewKeysetFetcherWithSeqInbox(seqInbox)
+		keysetFetcher, err = NewKeysetFetcherWithSeqInbox(seqInbox, config.keysetCacheConfig())
 		if err != nil {
 			return nil, nil, nil, err
 		}
@@ -305,3 +408,68 @@ func CreateDAReader(
 
 	return daReader, keysetFetcher, &lifecycleManager, nil
 }
+
+// DASStack is a DAS reader and writer assembled from a DataAvailabilityConfig, along with the
+// components they're built from.
+type DASStack struct {
+	Reader           daprovider.Reader
+	Writer           daprovider.Writer // nil unless a signing key is configured
+	StorageService   StorageService
+	KeysetFetcher    dasutil.DASKeysetFetcher
+	LifecycleManager *LifecycleManager
+}
+
+// BuildDASReaderWriter assembles a DAS reader and writer over locally configured storage: the
+// persistent storage backends wrapped in the configured caches, a writer signing certificates
+// with the configured key, and a cached keyset fetcher. Keysets are fetched from the parent
+// chain if l1client and a sequencer inbox address are given, and otherwise only the keyset of the
+// configured key can be resolved.
+func BuildDASReaderWriter(ctx context.Context, config *DataAvailabilityConfig, l1client *ethclient.Client) (*DASStack, error) {
+	if !config.Enable {
+		return nil, nil
+	}
+
+	storageService, lifecycleManager, err := CreatePersistentStorageService(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	storageService, err = WrapStorageWithCache(ctx, config, storageService, lifecycleManager)
+	if err != nil {
+		return nil, err
+	}
+
+	var signer *SignAfterStoreDASWriter
+	if config.Key.KeyDir != "" || config.Key.PrivKey != "" {
+		signer, err = NewSignAfterStoreDASWriter(ctx, *config, storageService)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var keysetFetcher dasutil.DASKeysetFetcher
+	switch {
+	case l1client != nil && config.SequencerInboxAddress != "":
+		if !common.IsHexAddress(config.SequencerInboxAddress) {
+			return nil, fmt.Errorf("invalid sequencer-inbox-address: %v", config.SequencerInboxAddress)
+		}
+		keysetFetcher, err = NewKeysetFetcher(l1client, common.HexToAddress(config.SequencerInboxAddress), config.keysetCacheConfig())
+		if err != nil {
+			return nil, err
+		}
+	case signer != nil:
+		keysetFetcher = NewCachingKeysetFetcher(config.keysetCacheConfig(), signer)
+	default:
+		return nil, errors.New("a parent chain client and sequencer-inbox-address, or a signing key, are required to resolve keysets")
+	}
+
+	stack := &DASStack{
+		Reader:           dasutil.NewReaderForDASWithOptions(storageService, keysetFetcher, dasutil.RecoveryOptions{MetricsNamespace: config.MetricsNamespace}),
+		StorageService:   storageService,
+		KeysetFetcher:    keysetFetcher,
+		LifecycleManager: lifecycleManager,
+	}
+	if signer != nil {
+		stack.Writer = dasutil.NewWriterForDAS(signer)
+	}
+	return stack, nil
+}
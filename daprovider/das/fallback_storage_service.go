@@ -5,6 +5,7 @@ package das
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -19,13 +20,24 @@ import (
 
 type FallbackStorageService struct {
 	StorageService
-	backup                     dasutil.DASReader
-	backupHealthChecker        DataAvailabilityServiceHealthChecker
-	backupRetentionSeconds     uint64
-	ignoreRetentionWriteErrors bool
-	preventRecursiveGets       bool
-	currentlyFetching          map[[32]byte]bool
-	currentlyFetchingMutex     sync.RWMutex
+	backup                      dasutil.DASReader
+	backupHealthChecker         DataAvailabilityServiceHealthChecker
+	backupRetentionSeconds      uint64
+	ignoreRetentionWriteErrors  bool
+	preventRecursiveGets        bool
+	currentlyFetching           map[[32]byte]bool
+	currentlyFetchingMutex      sync.RWMutex
+	recordSource                SourceRecorder
+	expirationPolicyAggregation ExpirationPolicyAggregation
+}
+
+// SourceRecorder is told the name of the backend that served each value read through a stack of
+// fallback storage services, e.g. to diagnose which tiers miss.
+type SourceRecorder func(key common.Hash, backend string)
+
+// sourceRecordingReader is a reader that can report which of its backends served each value.
+type sourceRecordingReader interface {
+	SetSourceRecorder(recorder SourceRecorder)
 }
 
 // NewFallbackStorageService is a StorageService that relies on a "primary" StorageService and a "backup". Puts go to the primary.
@@ -48,6 +60,38 @@ func NewFallbackStorageService(
 		preventRecursiveGets,
 		make(map[[32]byte]bool),
 		sync.RWMutex{},
+		nil,
+		AggregateMostDurable,
+	}
+}
+
+// SetSourceRecorder makes the service call recorder on each successful GetByHash with the name of
+// the backend that served the value. Backends that are themselves fallback storage services report
+// which of their own backends served it instead, so the recorder always gets the innermost backend.
+// It must be called before the service is used.
+func (f *FallbackStorageService) SetSourceRecorder(recorder SourceRecorder) {
+	f.recordSource = recorder
+	if inner, ok := f.StorageService.(sourceRecordingReader); ok {
+		inner.SetSourceRecorder(recorder)
+	}
+	if inner, ok := f.backup.(sourceRecordingReader); ok {
+		inner.SetSourceRecorder(recorder)
+	}
+}
+
+// recordServedBy records that backend served the value with the given key, unless there's no
+// recorder or the backend records its own sources.
+func (f *FallbackStorageService) recordServedBy(key common.Hash, backend any) {
+	if f.recordSource == nil {
+		return
+	}
+	if _, ok := backend.(sourceRecordingReader); ok {
+		return
+	}
+	if stringer, ok := backend.(fmt.Stringer); ok {
+		f.recordSource(key, stringer.String())
+	} else {
+		f.recordSource(key, fmt.Sprintf("%T", backend))
 	}
 }
 
@@ -64,7 +108,9 @@ func (f *FallbackStorageService) GetByHash(ctx context.Context, key common.Hash)
 	}
 
 	data, err := f.StorageService.GetByHash(ctx, key)
-	if err != nil {
+	if err == nil {
+		f.recordServedBy(key, f.StorageService)
+	} else {
 		doDelete := false
 		if f.preventRecursiveGets {
 			f.currentlyFetchingMutex.Lock()
@@ -93,10 +139,27 @@ func (f *FallbackStorageService) GetByHash(ctx context.Context, key common.Hash)
 				return nil, err
 			}
 		}
+		f.recordServedBy(key, f.backup)
 	}
 	return data, err
 }
 
+// SetExpirationPolicyAggregation sets how the policies of the primary and the backup are aggregated,
+// AggregateMostDurable by default. It must be called before the service is used.
+func (f *FallbackStorageService) SetExpirationPolicyAggregation(aggregation ExpirationPolicyAggregation) {
+	f.expirationPolicyAggregation = aggregation
+}
+
+// ExpirationPolicy aggregates the policy of the primary with the policy of the backup, which data
+// missing from the primary is read from.
+func (f *FallbackStorageService) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
+	primaryPolicy, err := f.StorageService.ExpirationPolicy(ctx)
+	if err != nil {
+		return -1, err
+	}
+	return chainedExpirationPolicy(ctx, f.expirationPolicyAggregation, primaryPolicy, f.backup)
+}
+
 func (f *FallbackStorageService) String() string {
 	return "FallbackStorageService(storageService:" + f.StorageService.String() + ")"
 }
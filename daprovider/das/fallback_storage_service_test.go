@@ -10,6 +10,8 @@ import (
 	"math"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/offchainlabs/nitro/daprovider/das/dastree"
 )
 
@@ -68,3 +70,46 @@ func TestFallbackStorageServiceRecursive(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// namedStorageService is a StorageService with a distinct name for its backend to be reported by.
+type namedStorageService struct {
+	StorageService
+	name string
+}
+
+func (s *namedStorageService) String() string {
+	return s.name
+}
+
+func TestFallbackStorageServiceSourceRecorder(t *testing.T) {
+	ctx := context.Background()
+	tiers := make([]StorageService, 3)
+	for i, name := range []string{"tier one", "tier two", "tier three"} {
+		tiers[i] = &namedStorageService{NewMemoryBackedStorageService(ctx), name}
+	}
+	value := []byte("a value only tier two has")
+	key := dastree.Hash(value)
+	Require(t, tiers[1].Put(ctx, value, math.MaxUint64))
+
+	inner := NewFallbackStorageService(tiers[1], tiers[2], tiers[2], 60*60, true, true)
+	outer := NewFallbackStorageService(tiers[0], inner, inner, 60*60, true, true)
+	var sources []string
+	outer.SetSourceRecorder(func(recordedKey common.Hash, backend string) {
+		if recordedKey != key {
+			Fail(t, "recorded unexpected key", recordedKey)
+		}
+		sources = append(sources, backend)
+	})
+
+	res, err := outer.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(res, value) {
+		Fail(t, "unexpected value", res)
+	}
+	// The value has now been copied to tier one, which serves the next read.
+	_, err = outer.GetByHash(ctx, key)
+	Require(t, err)
+	if len(sources) != 2 || sources[0] != "tier two" || sources[1] != "tier one" {
+		Fail(t, "expected reads served by tier two and then tier one, got", sources)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+	"github.com/offchainlabs/nitro/util/pretty"
+)
+
+var ErrKeysetEnumerationNotSupported = errors.New("keyset fetcher can't enumerate its keysets")
+
+// KeysetEnumerator is implemented by keyset fetchers that can list the hashes of the keysets they
+// can currently resolve without fetching them from elsewhere.
+type KeysetEnumerator interface {
+	AvailableKeysets(ctx context.Context) ([]common.Hash, error)
+}
+
+// AvailableKeysets returns the hashes of the keysets fetcher can currently resolve, in ascending
+// order, or ErrKeysetEnumerationNotSupported if it can't enumerate them.
+func AvailableKeysets(ctx context.Context, fetcher dasutil.DASKeysetFetcher) ([]common.Hash, error) {
+	enumerator, ok := fetcher.(KeysetEnumerator)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrKeysetEnumerationNotSupported, fetcher)
+	}
+	return enumerator.AvailableKeysets(ctx)
+}
+
+func sortHashes(hashes []common.Hash) {
+	sort.Slice(hashes, func(i, j int) bool {
+		return hashes[i].Cmp(hashes[j]) < 0
+	})
+}
+
+// FileKeysetFetcher is a DASKeysetFetcher reading keysets from a directory, with each keyset stored
+// in a file named after its hash as encoded by EncodeStorageServiceKey.
+type FileKeysetFetcher struct {
+	dir string
+}
+
+func NewFileKeysetFetcher(dir string) *FileKeysetFetcher {
+	return &FileKeysetFetcher{dir: dir}
+}
+
+func (f *FileKeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	log.Trace("das.FileKeysetFetcher.GetKeysetByHash", "hash", pretty.PrettyHash(hash))
+	keyset, err := os.ReadFile(filepath.Join(f.dir, EncodeStorageServiceKey(hash)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: keyset %v", ErrNotFound, hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !dastree.ValidHash(hash, keyset) {
+		return nil, fmt.Errorf("keyset file for %v doesn't match its hash", hash)
+	}
+	return keyset, nil
+}
+
+// AvailableKeysets lists the keysets in the directory. Files whose name isn't a hash are ignored,
+// and the contents of the files aren't checked until they're fetched.
+func (f *FileKeysetFetcher) AvailableKeysets(ctx context.Context) ([]common.Hash, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	var hashes []common.Hash
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || len(entry.Name()) != 2*len(common.Hash{}) {
+			continue
+		}
+		hash, err := DecodeStorageServiceKey(entry.Name())
+		if err != nil {
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+	sortHashes(hashes)
+	return hashes, nil
+}
+
+func (f *FileKeysetFetcher) String() string {
+	return fmt.Sprintf("FileKeysetFetcher(%v)", f.dir)
+}
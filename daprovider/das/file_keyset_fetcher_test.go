@@ -0,0 +1,94 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+func TestFileKeysetFetcherAvailableKeysets(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	keysets := [][]byte{[]byte("first keyset"), []byte("second keyset"), []byte("third keyset")}
+	var expected []common.Hash
+	for _, keyset := range keysets {
+		hash := dastree.Hash(keyset)
+		expected = append(expected, hash)
+		Require(t, os.WriteFile(filepath.Join(dir, EncodeStorageServiceKey(hash)), keyset, 0o600))
+	}
+	sortHashes(expected)
+	// Other files and directories are ignored.
+	Require(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a keyset"), 0o600))
+	Require(t, os.Mkdir(filepath.Join(dir, EncodeStorageServiceKey(common.Hash{1})), 0o700))
+
+	fetcher := NewFileKeysetFetcher(dir)
+	available, err := AvailableKeysets(ctx, fetcher)
+	Require(t, err)
+	if len(available) != len(expected) {
+		Fail(t, "expected", len(expected), "keysets, got", available)
+	}
+	for i := range expected {
+		if available[i] != expected[i] {
+			Fail(t, "unexpected keyset", i, available[i], "wanted", expected[i])
+		}
+	}
+
+	for _, keyset := range keysets {
+		fetched, err := fetcher.GetKeysetByHash(ctx, dastree.Hash(keyset))
+		Require(t, err)
+		if !bytes.Equal(fetched, keyset) {
+			Fail(t, "fetched keyset doesn't match", fetched)
+		}
+	}
+	if _, err := fetcher.GetKeysetByHash(ctx, common.Hash{2}); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected ErrNotFound for a missing keyset, got", err)
+	}
+}
+
+func TestCachingKeysetFetcherAvailableKeysets(t *testing.T) {
+	ctx := context.Background()
+	keyset := []byte("a keyset")
+	hash := dastree.Hash(keyset)
+	inner := &countingKeysetFetcher{keysets: map[common.Hash][]byte{hash: keyset}}
+
+	if _, err := AvailableKeysets(ctx, inner); !errors.Is(err, ErrKeysetEnumerationNotSupported) {
+		Fail(t, "expected enumeration not to be supported, got", err)
+	}
+
+	// Only fetched keysets are known when the underlying fetcher can't enumerate them.
+	fetcher := NewCachingKeysetFetcher(DefaultKeysetCacheConfig, inner)
+	available, err := fetcher.AvailableKeysets(ctx)
+	Require(t, err)
+	if len(available) != 0 {
+		Fail(t, "expected no keysets before fetching, got", available)
+	}
+	_, err = fetcher.GetKeysetByHash(ctx, hash)
+	Require(t, err)
+	available, err = fetcher.AvailableKeysets(ctx)
+	Require(t, err)
+	if len(available) != 1 || available[0] != hash {
+		Fail(t, "expected the fetched keyset to be available, got", available)
+	}
+
+	// Cached keysets are merged with those of an enumerable fetcher.
+	dir := t.TempDir()
+	fileKeyset := []byte("a keyset on disk")
+	Require(t, os.WriteFile(filepath.Join(dir, EncodeStorageServiceKey(dastree.Hash(fileKeyset))), fileKeyset, 0o600))
+	fileFetcher := NewCachingKeysetFetcher(DefaultKeysetCacheConfig, NewFileKeysetFetcher(dir))
+	available, err = fileFetcher.AvailableKeysets(ctx)
+	Require(t, err)
+	if len(available) != 1 || available[0] != dastree.Hash(fileKeyset) {
+		Fail(t, "expected the keyset on disk to be available, got", available)
+	}
+}
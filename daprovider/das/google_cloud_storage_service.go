@@ -2,6 +2,7 @@ package das
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -127,9 +128,23 @@ func (gcs *GoogleCloudStorageService) Put(ctx context.Context, value []byte, tim
 	return nil
 }
 
+// gcsMaxObjectSize is the maximum size of a Google Cloud Storage object.
+const gcsMaxObjectSize = 5 << 40
+
+func (gcs *GoogleCloudStorageService) ValidatePut(ctx context.Context, value []byte) error {
+	return checkPutSize(len(value), gcsMaxObjectSize)
+}
+
+func (gcs *GoogleCloudStorageService) Refresh(ctx context.Context, key common.Hash, timeout uint64) error {
+	return refreshByRestoring(ctx, gcs, key, timeout)
+}
+
 func (gcs *GoogleCloudStorageService) GetByHash(ctx context.Context, key common.Hash) ([]byte, error) {
 	log.Trace("das.GoogleCloudStorageService.GetByHash", "key", pretty.PrettyHash(key), "this", gcs)
 	buf, err := gcs.operator.Download(ctx, gcs.bucket, gcs.objectPrefix, key)
+	if errors.Is(err, googlestorage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
 	if err != nil {
 		log.Error("das.GoogleCloudStorageService.GetByHash", "err", err)
 		return nil, err
@@ -25,7 +25,7 @@ func (c *mockGCSClient) Bucket(name string) *googlestorage.BucketHandle {
 func (c *mockGCSClient) Download(ctx context.Context, bucket, objectPrefix string, key common.Hash) ([]byte, error) {
 	value, ok := c.storage[objectPrefix+EncodeStorageServiceKey(key)]
 	if !ok {
-		return nil, ErrNotFound
+		return nil, googlestorage.ErrObjectNotExist
 	}
 	return value, nil
 }
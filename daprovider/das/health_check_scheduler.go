@@ -0,0 +1,160 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+type HealthCheckSchedulerConfig struct {
+	Enable     bool          `koanf:"enable"`
+	Interval   time.Duration `koanf:"interval"`
+	MaxBackoff time.Duration `koanf:"max-backoff"`
+	Timeout    time.Duration `koanf:"timeout"`
+}
+
+var DefaultHealthCheckSchedulerConfig = HealthCheckSchedulerConfig{
+	Enable:     false,
+	Interval:   30 * time.Second,
+	MaxBackoff: 10 * time.Minute,
+	Timeout:    5 * time.Second,
+}
+
+func HealthCheckSchedulerConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultHealthCheckSchedulerConfig.Enable, "periodically health check the storage backends and export the results as metrics")
+	f.Duration(prefix+".interval", DefaultHealthCheckSchedulerConfig.Interval, "interval between health checks of a healthy storage backend")
+	f.Duration(prefix+".max-backoff", DefaultHealthCheckSchedulerConfig.MaxBackoff, "maximum interval between health checks of an unhealthy storage backend (backoff is disabled if not greater than interval)")
+	f.Duration(prefix+".timeout", DefaultHealthCheckSchedulerConfig.Timeout, "timeout of a single health check")
+}
+
+type healthCheckTarget struct {
+	name    string
+	checker DataAvailabilityServiceHealthChecker
+
+	healthyGauge     *metrics.Gauge
+	lastSuccessGauge *metrics.Gauge
+
+	consecutiveFailures int
+	nextCheck           time.Time
+}
+
+// HealthCheckScheduler periodically calls HealthCheck on a set of storage backends, exporting
+// whether each one is healthy and when it was last healthy. Unhealthy backends are checked with
+// exponential backoff so that the checks don't add to the load of a struggling backend.
+type HealthCheckScheduler struct {
+	stopwaiter.StopWaiter
+	config  HealthCheckSchedulerConfig
+	targets []*healthCheckTarget
+	now     func() time.Time
+}
+
+// NewHealthCheckScheduler returns a scheduler checking the given backends, keyed by the names their
+// metrics are exported under within metricsNamespace (see dasutil.MetricName).
+func NewHealthCheckScheduler(config HealthCheckSchedulerConfig, metricsNamespace string, checkers map[string]DataAvailabilityServiceHealthChecker) (*HealthCheckScheduler, error) {
+	if config.Interval <= 0 {
+		return nil, errors.New("health check interval must be positive")
+	}
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	targets := make([]*healthCheckTarget, 0, len(names))
+	for _, name := range names {
+		targets = append(targets, &healthCheckTarget{
+			name:             name,
+			checker:          checkers[name],
+			healthyGauge:     metrics.GetOrRegisterGauge(dasutil.MetricName(metricsNamespace, "healthcheck/"+name+"/healthy"), nil),
+			lastSuccessGauge: metrics.GetOrRegisterGauge(dasutil.MetricName(metricsNamespace, "healthcheck/"+name+"/lastsuccess"), nil),
+		})
+	}
+	return &HealthCheckScheduler{
+		config:  config,
+		targets: targets,
+		now:     time.Now,
+	}, nil
+}
+
+func (s *HealthCheckScheduler) Start(ctx context.Context) {
+	s.StopWaiter.Start(ctx, s)
+	s.CallIteratively(func(ctx context.Context) time.Duration {
+		s.checkAll(ctx)
+		return s.config.Interval
+	})
+}
+
+// checkAll runs the health check of every backend that is due for one.
+func (s *HealthCheckScheduler) checkAll(ctx context.Context) {
+	now := s.now()
+	for _, target := range s.targets {
+		if now.Before(target.nextCheck) {
+			continue
+		}
+		s.check(ctx, target)
+	}
+}
+
+func (s *HealthCheckScheduler) check(ctx context.Context, target *healthCheckTarget) {
+	checkCtx := ctx
+	if s.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancel()
+	}
+	err := target.checker.HealthCheck(checkCtx)
+	now := s.now()
+	if err == nil {
+		if target.consecutiveFailures > 0 {
+			log.Info("DAS storage backend is healthy again", "backend", target.name, "failures", target.consecutiveFailures)
+		}
+		target.consecutiveFailures = 0
+		target.nextCheck = now
+		target.healthyGauge.Update(1)
+		target.lastSuccessGauge.Update(now.Unix())
+		return
+	}
+	target.consecutiveFailures++
+	backoff := s.backoff(target.consecutiveFailures)
+	target.nextCheck = now.Add(backoff)
+	target.healthyGauge.Update(0)
+	log.Warn("DAS storage backend health check failed", "backend", target.name, "failures", target.consecutiveFailures, "nextCheckIn", backoff, "err", err)
+}
+
+// backoff returns how long to wait before checking a backend again after the given number of
+// consecutive failures: the check interval, doubled for each failure after the first, up to MaxBackoff.
+func (s *HealthCheckScheduler) backoff(consecutiveFailures int) time.Duration {
+	backoff := s.config.Interval
+	for i := 1; i < consecutiveFailures && backoff < s.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.config.MaxBackoff && s.config.MaxBackoff > s.config.Interval {
+		backoff = s.config.MaxBackoff
+	}
+	return backoff
+}
+
+func (s *HealthCheckScheduler) Close(ctx context.Context) error {
+	s.StopAndWait()
+	return nil
+}
+
+func (s *HealthCheckScheduler) String() string {
+	names := make([]string, 0, len(s.targets))
+	for _, target := range s.targets {
+		names = append(names, target.name)
+	}
+	return fmt.Sprintf("HealthCheckScheduler(backends:%v)", names)
+}
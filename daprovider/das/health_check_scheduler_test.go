@@ -0,0 +1,128 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+type flappingHealthChecker struct {
+	healthy bool
+	calls   int
+}
+
+func (c *flappingHealthChecker) HealthCheck(ctx context.Context) error {
+	c.calls++
+	if !c.healthy {
+		return errors.New("unhealthy")
+	}
+	return nil
+}
+
+func TestHealthCheckSchedulerTracksFlappingBackend(t *testing.T) {
+	ctx := context.Background()
+	checker := &flappingHealthChecker{healthy: true}
+	config := HealthCheckSchedulerConfig{Interval: time.Second, MaxBackoff: 4 * time.Second}
+	scheduler, err := NewHealthCheckScheduler(config, "", map[string]DataAvailabilityServiceHealthChecker{"flapping-test": checker})
+	Require(t, err)
+	now := time.Unix(1000, 0)
+	scheduler.now = func() time.Time { return now }
+	target := scheduler.targets[0]
+
+	tick := func() {
+		t.Helper()
+		scheduler.checkAll(ctx)
+		now = now.Add(config.Interval)
+	}
+
+	tick()
+	if target.healthyGauge.Snapshot().Value() != 1 {
+		Fail(t, "expected healthy gauge to be set")
+	}
+	if target.lastSuccessGauge.Snapshot().Value() != 1000 {
+		Fail(t, "unexpected last success", target.lastSuccessGauge.Snapshot().Value())
+	}
+
+	checker.healthy = false
+	tick()
+	if target.healthyGauge.Snapshot().Value() != 0 {
+		Fail(t, "expected healthy gauge to be cleared")
+	}
+	if target.lastSuccessGauge.Snapshot().Value() != 1000 {
+		Fail(t, "last success shouldn't change on failure", target.lastSuccessGauge.Snapshot().Value())
+	}
+
+	// While unhealthy, the backend is checked after 1s, then 2s, then 4s, and then every 4s.
+	callsBefore := checker.calls
+	for i := 0; i < 1+2+4+4; i++ {
+		tick()
+	}
+	if checker.calls-callsBefore != 4 {
+		Fail(t, "expected unhealthy backend to be checked with backoff, got", checker.calls-callsBefore, "checks")
+	}
+
+	checker.healthy = true
+	for i := 0; i < 4 && target.healthyGauge.Snapshot().Value() != 1; i++ {
+		tick()
+	}
+	if target.healthyGauge.Snapshot().Value() != 1 {
+		Fail(t, "expected healthy gauge to be set once the backend recovers")
+	}
+	if target.consecutiveFailures != 0 {
+		Fail(t, "expected failure count to be reset", target.consecutiveFailures)
+	}
+
+	// Once healthy again it's checked on every tick.
+	callsBefore = checker.calls
+	for i := 0; i < 3; i++ {
+		tick()
+	}
+	if checker.calls-callsBefore != 3 {
+		Fail(t, "expected healthy backend to be checked every interval, got", checker.calls-callsBefore, "checks")
+	}
+}
+
+func TestHealthCheckSchedulerStopsOnClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	checker := &flappingHealthChecker{healthy: true}
+	scheduler, err := NewHealthCheckScheduler(HealthCheckSchedulerConfig{Interval: time.Millisecond}, "", map[string]DataAvailabilityServiceHealthChecker{"close-test": checker})
+	Require(t, err)
+	scheduler.Start(ctx)
+	time.Sleep(20 * time.Millisecond)
+	Require(t, scheduler.Close(ctx))
+	if scheduler.targets[0].healthyGauge.Snapshot().Value() != 1 {
+		Fail(t, "expected the scheduler to have run")
+	}
+}
+
+func TestHealthCheckSchedulerMetricsNamespace(t *testing.T) {
+	ctx := context.Background()
+	config := HealthCheckSchedulerConfig{Interval: time.Second}
+	l2Checker := &flappingHealthChecker{healthy: true}
+	l3Checker := &flappingHealthChecker{healthy: false}
+	l2, err := NewHealthCheckScheduler(config, "l2-namespace-test", map[string]DataAvailabilityServiceHealthChecker{"s3-storage": l2Checker})
+	Require(t, err)
+	l3, err := NewHealthCheckScheduler(config, "l3-namespace-test", map[string]DataAvailabilityServiceHealthChecker{"s3-storage": l3Checker})
+	Require(t, err)
+	l2.checkAll(ctx)
+	l3.checkAll(ctx)
+
+	l2Gauge, ok := metrics.DefaultRegistry.Get("arb/das/l2-namespace-test/healthcheck/s3-storage/healthy").(*metrics.Gauge)
+	if !ok {
+		Fail(t, "missing health metric of the first chain")
+	}
+	l3Gauge, ok := metrics.DefaultRegistry.Get("arb/das/l3-namespace-test/healthcheck/s3-storage/healthy").(*metrics.Gauge)
+	if !ok {
+		Fail(t, "missing health metric of the second chain")
+	}
+	if l2Gauge.Snapshot().Value() != 1 || l3Gauge.Snapshot().Value() != 0 {
+		Fail(t, "the chains' health metrics aren't independent", l2Gauge.Snapshot().Value(), l3Gauge.Snapshot().Value())
+	}
+}
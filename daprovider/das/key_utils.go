@@ -7,11 +7,16 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/offchainlabs/nitro/blsSignatures"
 )
@@ -124,3 +129,33 @@ func GenerateAndStoreECDSAKeys(dir string) error {
 	encodedPubKey := hex.EncodeToString(crypto.FromECDSAPub(&privateKey.PublicKey))
 	return os.WriteFile(filepath.Join(dir, "ecdsa.pub"), []byte(encodedPubKey), 0o600)
 }
+
+// secretKeyFromConfig parses a 32 byte secret key from a config option, which is either
+// the key itself as hex, or the path to a file containing the key as hex.
+// Reading the key from a file keeps the secret out of the command line and process args.
+func secretKeyFromConfig(keyConfig string) (common.Hash, error) {
+	keyHex := strings.TrimPrefix(keyConfig, "0x")
+	if _, err := hex.DecodeString(keyHex); err != nil || len(keyHex) != 64 {
+		info, err := os.Stat(keyConfig)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("key config is neither 32 bytes of hex nor a readable file: %w", err)
+		}
+		if info.Mode().Perm()&0o004 != 0 {
+			log.Warn("Secret key file is world-readable", "path", keyConfig, "mode", info.Mode().Perm())
+		}
+		contents, err := os.ReadFile(keyConfig)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		keyHex = strings.TrimPrefix(strings.TrimSpace(string(contents)), "0x")
+	}
+	key, err := hex.DecodeString(keyHex)
+	if err != nil || len(key) != 32 {
+		return common.Hash{}, errors.New("secret key is not 32 bytes of hex")
+	}
+	secretKey := common.BytesToHash(key)
+	if secretKey == (common.Hash{}) {
+		return common.Hash{}, errors.New("secret key must not be zero")
+	}
+	return secretKey, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+	"github.com/offchainlabs/nitro/util/pretty"
+)
+
+type KeysetCacheConfig struct {
+	Capacity int           `koanf:"capacity"`
+	MaxAge   time.Duration `koanf:"max-age"`
+	// MetricsNamespace namespaces the cache metrics, as described by dasutil.MetricName. It's taken
+	// from the metrics-namespace of the DataAvailabilityConfig.
+	MetricsNamespace string `koanf:"-"`
+}
+
+var DefaultKeysetCacheConfig = KeysetCacheConfig{
+	Capacity: 256,
+	MaxAge:   24 * time.Hour,
+}
+
+func KeysetCacheConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Int(prefix+".capacity", DefaultKeysetCacheConfig.Capacity, "maximum number of keysets to keep in the keyset cache")
+	f.Duration(prefix+".max-age", DefaultKeysetCacheConfig.MaxAge, "maximum age of a cached keyset before it is re-fetched (0 to never expire)")
+}
+
+type keysetCacheEntry struct {
+	keyset    []byte
+	fetchedAt time.Time
+}
+
+// keysetCache is a bounded LRU cache of keyset preimages. Keysets are immutable by hash,
+// so the age limit only exists to reclaim memory held by keysets that are no longer used.
+// Keysets dropped for either reason are counted as evictions.
+type keysetCache struct {
+	// mutex serializes the removal of expired entries with puts, so that a keyset fetched again
+	// after expiring isn't removed along with the stale entry.
+	mutex  sync.Mutex
+	cache  *lru.Cache[common.Hash, keysetCacheEntry]
+	maxAge time.Duration
+	now    func() time.Time
+
+	hitCounter      *metrics.Counter
+	missCounter     *metrics.Counter
+	evictionCounter *metrics.Counter
+}
+
+func newKeysetCache(config KeysetCacheConfig) *keysetCache {
+	return &keysetCache{
+		cache:           lru.NewCache[common.Hash, keysetCacheEntry](config.Capacity),
+		maxAge:          config.MaxAge,
+		now:             time.Now,
+		hitCounter:      metrics.GetOrRegisterCounter(dasutil.MetricName(config.MetricsNamespace, "keyset_cache/hits"), nil),
+		missCounter:     metrics.GetOrRegisterCounter(dasutil.MetricName(config.MetricsNamespace, "keyset_cache/misses"), nil),
+		evictionCounter: metrics.GetOrRegisterCounter(dasutil.MetricName(config.MetricsNamespace, "keyset_cache/evictions"), nil),
+	}
+}
+
+func (c *keysetCache) get(key common.Hash) ([]byte, bool) {
+	entry, ok := c.cache.Get(key)
+	if !ok {
+		c.missCounter.Inc(1)
+		return nil, false
+	}
+	if c.maxAge > 0 && c.now().Sub(entry.fetchedAt) > c.maxAge {
+		c.removeStale(key, entry)
+		c.missCounter.Inc(1)
+		return nil, false
+	}
+	c.hitCounter.Inc(1)
+	return entry.keyset, true
+}
+
+// removeStale removes the expired entry of key, unless it was replaced since it was read.
+func (c *keysetCache) removeStale(key common.Hash, stale keysetCacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if entry, ok := c.cache.Peek(key); ok && entry.fetchedAt.Equal(stale.fetchedAt) {
+		c.cache.Remove(key)
+		c.evictionCounter.Inc(1)
+	}
+}
+
+// keys returns the hashes of the cached keysets that haven't passed their maximum age.
+func (c *keysetCache) keys() []common.Hash {
+	var keys []common.Hash
+	for _, key := range c.cache.Keys() {
+		entry, ok := c.cache.Peek(key)
+		if !ok || (c.maxAge > 0 && c.now().Sub(entry.fetchedAt) > c.maxAge) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (c *keysetCache) put(key common.Hash, keyset []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if evicted := c.cache.Add(key, keysetCacheEntry{keyset: keyset, fetchedAt: c.now()}); evicted {
+		c.evictionCounter.Inc(1)
+	}
+}
+
+// CachingKeysetFetcher is a DASKeysetFetcher that caches keysets fetched from another DASKeysetFetcher.
+// Keysets are validated against their hash before being cached.
+type CachingKeysetFetcher struct {
+	fetcher dasutil.DASKeysetFetcher
+	cache   *keysetCache
+}
+
+func NewCachingKeysetFetcher(config KeysetCacheConfig, fetcher dasutil.DASKeysetFetcher) *CachingKeysetFetcher {
+	return &CachingKeysetFetcher{
+		fetcher: fetcher,
+		cache:   newKeysetCache(config),
+	}
+}
+
+func (c *CachingKeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	log.Trace("das.CachingKeysetFetcher.GetKeysetByHash", "hash", pretty.PrettyHash(hash))
+	if keyset, ok := c.cache.get(hash); ok {
+		return keyset, nil
+	}
+	keyset, err := c.fetcher.GetKeysetByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	// The cert version isn't known here, so keysets are accepted by either hash form and checked
+	// against the version of the cert when it's recovered.
+	if !dasutil.ValidKeysetHash(0, hash, keyset) {
+		return nil, errors.New("fetched keyset does not match requested hash")
+	}
+	c.cache.put(hash, keyset)
+	return keyset, nil
+}
+
+// AvailableKeysets returns the cached keysets along with those the underlying fetcher can enumerate.
+// If the underlying fetcher can't enumerate its keysets only the cached ones are returned.
+func (c *CachingKeysetFetcher) AvailableKeysets(ctx context.Context) ([]common.Hash, error) {
+	available := make(map[common.Hash]struct{})
+	for _, hash := range c.cache.keys() {
+		available[hash] = struct{}{}
+	}
+	inner, err := AvailableKeysets(ctx, c.fetcher)
+	if err != nil && !errors.Is(err, ErrKeysetEnumerationNotSupported) {
+		return nil, err
+	}
+	for _, hash := range inner {
+		available[hash] = struct{}{}
+	}
+	hashes := make([]common.Hash, 0, len(available))
+	for hash := range available {
+		hashes = append(hashes, hash)
+	}
+	sortHashes(hashes)
+	return hashes, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+type countingKeysetFetcher struct {
+	keysets map[common.Hash][]byte
+	calls   int
+}
+
+func (f *countingKeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	f.calls++
+	keyset, ok := f.keysets[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return keyset, nil
+}
+
+func TestCachingKeysetFetcherMaxAge(t *testing.T) {
+	ctx := context.Background()
+	keyset := []byte("a keyset")
+	hash := dastree.Hash(keyset)
+	inner := &countingKeysetFetcher{keysets: map[common.Hash][]byte{hash: keyset}}
+
+	fetcher := NewCachingKeysetFetcher(KeysetCacheConfig{Capacity: 8, MaxAge: time.Hour}, inner)
+	now := time.Now()
+	fetcher.cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		res, err := fetcher.GetKeysetByHash(ctx, hash)
+		Require(t, err)
+		if !bytes.Equal(res, keyset) {
+			Fail(t, "unexpected keyset", res)
+		}
+	}
+	if inner.calls != 1 {
+		Fail(t, "expected a single fetch, got", inner.calls)
+	}
+
+	now = now.Add(time.Hour + time.Second)
+	_, err := fetcher.GetKeysetByHash(ctx, hash)
+	Require(t, err)
+	if inner.calls != 2 {
+		Fail(t, "expected a re-fetch after max age, got", inner.calls)
+	}
+	_, err = fetcher.GetKeysetByHash(ctx, hash)
+	Require(t, err)
+	if inner.calls != 2 {
+		Fail(t, "expected re-fetched keyset to be cached, got", inner.calls)
+	}
+}
+
+func TestCachingKeysetFetcherCapacity(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingKeysetFetcher{keysets: make(map[common.Hash][]byte)}
+	var hashes []common.Hash
+	for i := 0; i < 3; i++ {
+		keyset := []byte{byte(i)}
+		hash := dastree.Hash(keyset)
+		inner.keysets[hash] = keyset
+		hashes = append(hashes, hash)
+	}
+
+	fetcher := NewCachingKeysetFetcher(KeysetCacheConfig{Capacity: 2}, inner)
+	for _, hash := range hashes {
+		_, err := fetcher.GetKeysetByHash(ctx, hash)
+		Require(t, err)
+	}
+	if fetcher.cache.cache.Len() != 2 {
+		Fail(t, "expected cache to be bounded to 2 entries, got", fetcher.cache.cache.Len())
+	}
+
+	// The least recently used keyset was evicted and must be fetched again.
+	_, err := fetcher.GetKeysetByHash(ctx, hashes[0])
+	Require(t, err)
+	if inner.calls != 4 {
+		Fail(t, "expected evicted keyset to be re-fetched, got", inner.calls)
+	}
+}
+
+func TestCachingKeysetFetcherMetrics(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingKeysetFetcher{keysets: make(map[common.Hash][]byte)}
+	var hashes []common.Hash
+	for i := 0; i < 3; i++ {
+		keyset := []byte{byte(i)}
+		hash := dastree.Hash(keyset)
+		inner.keysets[hash] = keyset
+		hashes = append(hashes, hash)
+	}
+	fetcher := NewCachingKeysetFetcher(KeysetCacheConfig{Capacity: 2, MaxAge: time.Hour}, inner)
+	now := time.Now()
+	fetcher.cache.now = func() time.Time { return now }
+	hitsBefore := fetcher.cache.hitCounter.Snapshot().Count()
+	missesBefore := fetcher.cache.missCounter.Snapshot().Count()
+	evictionsBefore := fetcher.cache.evictionCounter.Snapshot().Count()
+	fetch := func(hash common.Hash) {
+		t.Helper()
+		_, err := fetcher.GetKeysetByHash(ctx, hash)
+		Require(t, err)
+	}
+	check := func(hits, misses, evictions int64) {
+		t.Helper()
+		gotHits := fetcher.cache.hitCounter.Snapshot().Count() - hitsBefore
+		gotMisses := fetcher.cache.missCounter.Snapshot().Count() - missesBefore
+		gotEvictions := fetcher.cache.evictionCounter.Snapshot().Count() - evictionsBefore
+		if gotHits != hits || gotMisses != misses || gotEvictions != evictions {
+			Fail(t, "expected", hits, "hits,", misses, "misses and", evictions, "evictions, got", gotHits, gotMisses, gotEvictions)
+		}
+	}
+
+	fetch(hashes[0])
+	fetch(hashes[0])
+	fetch(hashes[1])
+	check(1, 2, 0)
+	// Fetching a third keyset evicts the least recently used one.
+	fetch(hashes[2])
+	check(1, 3, 1)
+	fetch(hashes[1])
+	check(2, 3, 1)
+	// Keysets past their maximum age are dropped too.
+	now = now.Add(time.Hour + time.Second)
+	fetch(hashes[1])
+	check(2, 4, 2)
+}
+
+func TestCachingKeysetFetcherRejectsMismatchedKeyset(t *testing.T) {
+	ctx := context.Background()
+	hash := dastree.Hash([]byte("expected"))
+	inner := &countingKeysetFetcher{keysets: map[common.Hash][]byte{hash: []byte("something else")}}
+
+	fetcher := NewCachingKeysetFetcher(DefaultKeysetCacheConfig, inner)
+	if _, err := fetcher.GetKeysetByHash(ctx, hash); err == nil {
+		Fail(t, "expected mismatched keyset to be rejected")
+	}
+	if fetcher.cache.cache.Len() != 0 {
+		Fail(t, "mismatched keyset should not be cached")
+	}
+}
+
+func TestKeysetCacheKeepsEntryReplacedWhileExpiring(t *testing.T) {
+	cache := newKeysetCache(KeysetCacheConfig{Capacity: 8, MaxAge: time.Hour})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	keyset := []byte("a keyset")
+	hash := dastree.Hash(keyset)
+	cache.put(hash, keyset)
+	stale, _ := cache.cache.Peek(hash)
+
+	// Another fetch caches the keyset again between the stale entry being read and removed.
+	now = now.Add(time.Hour + time.Second)
+	cache.put(hash, keyset)
+	cache.removeStale(hash, stale)
+	if _, ok := cache.get(hash); !ok {
+		Fail(t, "expected the keyset cached again to survive the removal of the stale entry")
+	}
+}
+
+func TestCachingKeysetFetcherAcceptsFlatKeysetHash(t *testing.T) {
+	ctx := context.Background()
+	// Keysets starting with a byte dastree.ValidHash takes for a tree node are still accepted by
+	// their flat keccak hash, as referenced by version 0 certs.
+	keyset := append([]byte{dastree.NodeByte}, []byte("keyset")...)
+	hash := crypto.Keccak256Hash(keyset)
+	inner := &countingKeysetFetcher{keysets: map[common.Hash][]byte{hash: keyset}}
+	fetcher := NewCachingKeysetFetcher(DefaultKeysetCacheConfig, inner)
+	res, err := fetcher.GetKeysetByHash(ctx, hash)
+	Require(t, err)
+	if !bytes.Equal(res, keyset) {
+		Fail(t, "unexpected keyset", res)
+	}
+}
+
+func TestKeysetCacheMetricsNamespace(t *testing.T) {
+	config := DataAvailabilityConfig{KeysetCache: DefaultKeysetCacheConfig, MetricsNamespace: "keyset-namespace-test"}
+	cache := newKeysetCache(config.keysetCacheConfig())
+	cache.get(dastree.Hash([]byte("uncached keyset")))
+	misses, ok := metrics.DefaultRegistry.Get("arb/das/keyset-namespace-test/keyset_cache/misses").(*metrics.Counter)
+	if !ok {
+		Fail(t, "expected the keyset cache misses to be counted within the namespace")
+	}
+	if got := misses.Snapshot().Count(); got != 1 {
+		Fail(t, "expected 1 namespaced miss, got", got)
+	}
+}
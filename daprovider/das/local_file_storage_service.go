@@ -134,8 +134,30 @@ func (s *LocalFileStorageService) GetByHash(ctx context.Context, key common.Hash
 	return data, nil
 }
 
+// GetByHashTo copies the value of key to w without reading all of it into memory first.
+func (s *LocalFileStorageService) GetByHashTo(ctx context.Context, key common.Hash, w io.Writer) (int64, error) {
+	log.Trace("das.LocalFileStorageService.GetByHashTo", "key", pretty.PrettyHash(key), "this", s)
+
+	f, err := os.Open(s.layout.batchPath(key))
+	if errors.Is(err, os.ErrNotExist) {
+		f, err = os.Open(s.legacyLayout.batchPath(key))
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(w, f)
+}
+
 func (s *LocalFileStorageService) Put(ctx context.Context, data []byte, expiry uint64) error {
 	logPut("das.LocalFileStorageService.Store", data, expiry, s)
+	return s.PutWithKey(ctx, dastree.Hash(data), data, expiry)
+}
+
+func (s *LocalFileStorageService) checkExpiry(expiry uint64) error {
 	if expiry > math.MaxInt64 {
 		return fmt.Errorf("request expiry time (%v) exceeds max int64", expiry)
 	}
@@ -145,8 +167,14 @@ func (s *LocalFileStorageService) Put(ctx context.Context, data []byte, expiry u
 	if expiryTime.After(currentTimePlusRetention) {
 		return fmt.Errorf("requested expiry time (%v) exceeds current time plus maximum allowed retention period(%v)", expiryTime, currentTimePlusRetention)
 	}
+	return nil
+}
+
+func (s *LocalFileStorageService) PutWithKey(ctx context.Context, key common.Hash, data []byte, expiry uint64) error {
+	if err := s.checkExpiry(expiry); err != nil {
+		return err
+	}
 
-	key := dastree.Hash(data)
 	var batchPath string
 	if !s.enableLegacyLayout {
 		s.layout.writeMutex.Lock()
@@ -220,6 +248,80 @@ func (s *LocalFileStorageService) Put(ctx context.Context, data []byte, expiry u
 	return nil
 }
 
+// ApproxEntryCount counts the stored batches by walking the data directory, so it's slow for large
+// stores. Batches put while the count is running may or may not be counted.
+func (s *LocalFileStorageService) ApproxEntryCount(ctx context.Context) (uint64, error) {
+	var count uint64
+	if s.enableLegacyLayout {
+		it, err := s.legacyLayout.iterateBatches()
+		if err != nil {
+			return 0, err
+		}
+		for _, err = it.next(); !errors.Is(err, io.EOF); _, err = it.next() {
+			if err != nil {
+				return 0, err
+			}
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+			count++
+		}
+		return count, nil
+	}
+	it, err := s.layout.iterateBatches()
+	if errors.Is(err, os.ErrNotExist) {
+		// Nothing has been stored yet.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	for _, err = it.next(); !errors.Is(err, io.EOF); _, err = it.next() {
+		if err != nil {
+			return 0, err
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ValidatePut always succeeds, as files have no size limit and the retention check depends on the expiry.
+func (s *LocalFileStorageService) ValidatePut(ctx context.Context, data []byte) error {
+	return nil
+}
+
+func (s *LocalFileStorageService) ValidatePutSize(ctx context.Context, size int) error {
+	return nil
+}
+
+// Refresh adds an expiry index entry for the new expiration time, without rewriting the batch. The
+// batch is only pruned once all of its index entries have expired, so an earlier expiration time
+// doesn't shorten its retention. The legacy layout has no index, so batches are stored again there.
+func (s *LocalFileStorageService) Refresh(ctx context.Context, key common.Hash, expiry uint64) error {
+	if s.enableLegacyLayout {
+		return refreshByRestoring(ctx, s, key, expiry)
+	}
+	if err := s.checkExpiry(expiry); err != nil {
+		return err
+	}
+	s.layout.writeMutex.Lock()
+	defer s.layout.writeMutex.Unlock()
+	batchPath := s.layout.batchPath(key)
+	if _, err := os.Stat(batchPath); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := createHardLink(batchPath, s.layout.expiryPath(key, expiry)); err != nil {
+		return fmt.Errorf("couldn't create by-expiry-path index entry: %w", err)
+	}
+	return nil
+}
+
 func (s *LocalFileStorageService) Sync(ctx context.Context) error {
 	return nil
 }
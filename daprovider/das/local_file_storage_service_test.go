@@ -226,3 +226,94 @@ func TestExpiryDuplicates(t *testing.T) {
 	pruneCountRemaining(t, &s.layout, afterNow.Add(3*time.Second*expiryDivisor), 0)
 	countTimestampEntries(t, &s.layout, afterNow.Add(1000*time.Hour), 0)
 }
+
+func TestLocalFileStorageServiceRefresh(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalFileStorageService(LocalFileStorageConfig{
+		Enable:       true,
+		DataDir:      t.TempDir(),
+		EnableExpiry: true,
+		MaxRetention: time.Hour * 10,
+	})
+	Require(t, err)
+
+	now := time.Now()
+	// #nosec G115
+	Require(t, s.Put(ctx, []byte("a"), uint64(now.Add(time.Second*expiryDivisor).Unix())))
+	// #nosec G115
+	Require(t, s.Refresh(ctx, dastree.Hash([]byte("a")), uint64(now.Add(3*time.Second*expiryDivisor).Unix())))
+	// An earlier expiry only adds an index entry, leaving the later one in place.
+	// #nosec G115
+	Require(t, s.Refresh(ctx, dastree.Hash([]byte("a")), uint64(now.Add(2*time.Second*expiryDivisor).Unix())))
+	countTimestampEntries(t, &s.layout, now.Add(1000*time.Hour), 3)
+
+	pruneCountRemaining(t, &s.layout, now.Add(2*time.Second*expiryDivisor+time.Second), 1)
+	getByHashAndCheck(t, s, "a")
+	pruneCountRemaining(t, &s.layout, now.Add(3*time.Second*expiryDivisor+time.Second), 0)
+
+	// #nosec G115
+	if err := s.Refresh(ctx, dastree.Hash([]byte("absent")), uint64(now.Add(time.Hour).Unix())); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected refreshing a missing batch to fail with ErrNotFound, got", err)
+	}
+}
+
+func TestLocalFileStorageServiceNotFound(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalFileStorageService(LocalFileStorageConfig{
+		Enable:       true,
+		DataDir:      t.TempDir(),
+		MaxRetention: time.Hour,
+	})
+	Require(t, err)
+	_, err = s.GetByHash(ctx, dastree.Hash([]byte("absent")))
+	if !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected ErrNotFound, got", err)
+	}
+}
+
+func TestLocalFileStorageServiceGetByHashTo(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalFileStorageService(LocalFileStorageConfig{
+		Enable:       true,
+		DataDir:      t.TempDir(),
+		MaxRetention: time.Hour,
+	})
+	Require(t, err)
+	val := bytes.Repeat([]byte("streamed "), 10000)
+	// #nosec G115
+	Require(t, s.Put(ctx, val, uint64(time.Now().Add(time.Minute).Unix())))
+	var buf bytes.Buffer
+	n, err := s.GetByHashTo(ctx, dastree.Hash(val), &buf)
+	Require(t, err)
+	if n != int64(len(val)) || !bytes.Equal(buf.Bytes(), val) {
+		Fail(t, "streamed", n, "bytes of wrong value")
+	}
+	if _, err := s.GetByHashTo(ctx, dastree.Hash([]byte("absent")), &buf); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected ErrNotFound, got", err)
+	}
+}
+
+func TestLocalFileStorageServiceApproxEntryCount(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalFileStorageService(LocalFileStorageConfig{
+		Enable:       true,
+		DataDir:      t.TempDir(),
+		MaxRetention: time.Hour,
+	})
+	Require(t, err)
+	count, err := ApproxEntryCount(ctx, s)
+	Require(t, err)
+	if count != 0 {
+		Fail(t, "expected an empty store, got", count, "entries")
+	}
+	// #nosec G115
+	expiry := uint64(time.Now().Add(time.Minute).Unix())
+	for _, value := range []string{"a", "b", "c", "d"} {
+		Require(t, s.Put(ctx, []byte(value), expiry))
+	}
+	count, err = ApproxEntryCount(ctx, s)
+	Require(t, err)
+	if count != 4 {
+		Fail(t, "expected 4 entries, got", count)
+	}
+}
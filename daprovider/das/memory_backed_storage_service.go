@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -19,6 +20,10 @@ type MemoryBackedStorageService struct { // intended for testing and debugging
 	contents map[[32]byte][]byte
 	rwmutex  sync.RWMutex
 	closed   bool
+
+	// expirations is only set if the service discards data once its timeout passes.
+	expirations map[[32]byte]uint64
+	now         func() time.Time
 }
 
 var ErrClosed = errors.New("cannot access a StorageService that has been Closed")
@@ -29,6 +34,33 @@ func NewMemoryBackedStorageService(ctx context.Context) StorageService {
 	}
 }
 
+// NewMemoryBackedStorageServiceWithExpiry returns a MemoryBackedStorageService that honors the
+// timeouts of stored data, treating values as not found once their timeout has passed. It's meant
+// as a base for tests of services that depend on expiry, and for ephemeral nodes.
+func NewMemoryBackedStorageServiceWithExpiry(ctx context.Context) *MemoryBackedStorageService {
+	return NewMemoryBackedStorageServiceWithClock(ctx, time.Now)
+}
+
+// NewMemoryBackedStorageServiceWithClock is NewMemoryBackedStorageServiceWithExpiry with expiry
+// decided by the given clock, so tests can advance time past the timeouts of stored values.
+func NewMemoryBackedStorageServiceWithClock(ctx context.Context, now func() time.Time) *MemoryBackedStorageService {
+	return &MemoryBackedStorageService{
+		contents:    make(map[[32]byte][]byte),
+		expirations: make(map[[32]byte]uint64),
+		now:         now,
+	}
+}
+
+// expired returns whether the value with the given key has passed its timeout. Must be called with
+// the mutex held.
+func (m *MemoryBackedStorageService) expired(key common.Hash) bool {
+	if m.expirations == nil {
+		return false
+	}
+	// #nosec G115
+	return m.expirations[key] <= uint64(m.now().Unix())
+}
+
 func (m *MemoryBackedStorageService) GetByHash(ctx context.Context, key common.Hash) ([]byte, error) {
 	log.Trace("das.MemoryBackedStorageService.GetByHash", "key", key, "this", m)
 	m.rwmutex.RLock()
@@ -37,7 +69,7 @@ func (m *MemoryBackedStorageService) GetByHash(ctx context.Context, key common.H
 		return nil, ErrClosed
 	}
 	res, found := m.contents[key]
-	if !found {
+	if !found || m.expired(key) {
 		return nil, ErrNotFound
 	}
 	return res, nil
@@ -45,12 +77,61 @@ func (m *MemoryBackedStorageService) GetByHash(ctx context.Context, key common.H
 
 func (m *MemoryBackedStorageService) Put(ctx context.Context, data []byte, expirationTime uint64) error {
 	logPut("das.MemoryBackedStorageService.Store", data, expirationTime, m)
+	return m.PutWithKey(ctx, dastree.Hash(data), data, expirationTime)
+}
+
+func (m *MemoryBackedStorageService) PutWithKey(ctx context.Context, key common.Hash, data []byte, expirationTime uint64) error {
+	m.rwmutex.Lock()
+	defer m.rwmutex.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	if m.expirations != nil {
+		// A value stored again keeps the later of its timeouts, so storing it can't shorten its retention.
+		if _, found := m.contents[key]; !found || m.expired(key) || expirationTime > m.expirations[key] {
+			m.expirations[key] = expirationTime
+		}
+	}
+	m.contents[key] = append([]byte{}, data...)
+	return nil
+}
+
+// ApproxEntryCount returns the number of stored values, including expired ones.
+func (m *MemoryBackedStorageService) ApproxEntryCount(ctx context.Context) (uint64, error) {
+	m.rwmutex.RLock()
+	defer m.rwmutex.RUnlock()
+	if m.closed {
+		return 0, ErrClosed
+	}
+	return uint64(len(m.contents)), nil
+}
+
+func (m *MemoryBackedStorageService) ValidatePut(ctx context.Context, data []byte) error {
+	return m.ValidatePutSize(ctx, len(data))
+}
+
+func (m *MemoryBackedStorageService) ValidatePutSize(ctx context.Context, size int) error {
+	m.rwmutex.RLock()
+	defer m.rwmutex.RUnlock()
+	if m.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+func (m *MemoryBackedStorageService) Refresh(ctx context.Context, key common.Hash, expirationTime uint64) error {
+	if m.expirations == nil {
+		return refreshByRestoring(ctx, m, key, expirationTime)
+	}
 	m.rwmutex.Lock()
 	defer m.rwmutex.Unlock()
 	if m.closed {
 		return ErrClosed
 	}
-	m.contents[dastree.Hash(data)] = append([]byte{}, data...)
+	if _, found := m.contents[key]; !found || m.expired(key) {
+		return ErrNotFound
+	}
+	m.expirations[key] = max(m.expirations[key], expirationTime)
 	return nil
 }
 
@@ -71,6 +152,9 @@ func (m *MemoryBackedStorageService) Close(ctx context.Context) error {
 }
 
 func (m *MemoryBackedStorageService) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
+	if m.expirations != nil {
+		return dasutil.DiscardAfterDataTimeout, nil
+	}
 	return dasutil.KeepForever, nil
 }
 
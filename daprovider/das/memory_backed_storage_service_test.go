@@ -0,0 +1,155 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+func TestMemoryBackedStorageServiceWithExpiry(t *testing.T) {
+	ctx := context.Background()
+	storageService := NewMemoryBackedStorageServiceWithExpiry(ctx)
+	now := time.Unix(1000, 0)
+	storageService.now = func() time.Time { return now }
+
+	policy, err := storageService.ExpirationPolicy(ctx)
+	Require(t, err)
+	if policy != dasutil.DiscardAfterDataTimeout {
+		Fail(t, "unexpected expiration policy", policy)
+	}
+
+	value := []byte("a value with a timeout")
+	key := dastree.Hash(value)
+	if _, err := storageService.GetByHash(ctx, key); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected ErrNotFound before storing, got", err)
+	}
+	Require(t, storageService.Put(ctx, value, 1060))
+	stored, err := storageService.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(stored, value) {
+		Fail(t, "stored value doesn't round trip", stored)
+	}
+
+	now = now.Add(30 * time.Second)
+	Require(t, storageService.Refresh(ctx, key, 1120))
+	// Neither refreshing nor storing the value again with an earlier timeout shortens its retention.
+	Require(t, storageService.Refresh(ctx, key, 1040))
+	Require(t, storageService.Put(ctx, value, 1040))
+	now = now.Add(time.Minute)
+	if _, err := storageService.GetByHash(ctx, key); err != nil {
+		Fail(t, "expected refreshed value to outlive its original timeout, got", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := storageService.GetByHash(ctx, key); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected expired value not to be found, got", err)
+	}
+	if err := storageService.Refresh(ctx, key, 2000); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected refreshing an expired value to fail with ErrNotFound, got", err)
+	}
+
+	// Storing the value again revives it.
+	Require(t, storageService.Put(ctx, value, 2000))
+	if _, err := storageService.GetByHash(ctx, key); err != nil {
+		Fail(t, "expected stored value to be found again, got", err)
+	}
+
+	Require(t, storageService.Close(ctx))
+	if _, err := storageService.GetByHash(ctx, key); !errors.Is(err, ErrClosed) {
+		Fail(t, "expected ErrClosed after close, got", err)
+	}
+}
+
+func TestMemoryBackedStorageServiceClock(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	storageService := NewMemoryBackedStorageServiceWithClock(ctx, func() time.Time { return now })
+	early, late := []byte("expires at 1010"), []byte("expires at 1020")
+	Require(t, storageService.Put(ctx, early, 1010))
+	Require(t, storageService.Put(ctx, late, 1020))
+
+	check := func(value []byte, expectFound bool) {
+		t.Helper()
+		stored, err := storageService.GetByHash(ctx, dastree.Hash(value))
+		if expectFound {
+			Require(t, err)
+			if !bytes.Equal(stored, value) {
+				Fail(t, "stored value doesn't round trip", stored)
+			}
+		} else if !errors.Is(err, ErrNotFound) {
+			Fail(t, "expected", string(value), "to have expired at", now.Unix(), "got", err)
+		}
+	}
+	now = time.Unix(1009, 0)
+	check(early, true)
+	check(late, true)
+	// A value expires once the clock reaches its timeout.
+	now = time.Unix(1010, 0)
+	check(early, false)
+	check(late, true)
+	now = time.Unix(1020, 0)
+	check(early, false)
+	check(late, false)
+}
+
+func TestPutAndHash(t *testing.T) {
+	ctx := context.Background()
+	keyed := NewMemoryBackedStorageService(ctx)
+	// Hides PutWithKey, as with the DB, S3 and redundant backends.
+	unkeyed := struct{ StorageService }{NewMemoryBackedStorageService(ctx)}
+	server, err := miniredis.Run()
+	Require(t, err)
+	defer server.Close()
+	// Redis can only store under explicit keys over a keyed base, but its Put works over any.
+	redisOverUnkeyed, err := NewRedisStorageService(RedisConfig{
+		Enable:     true,
+		Url:        "redis://" + server.Addr(),
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+	}, struct{ StorageService }{NewMemoryBackedStorageService(ctx)})
+	Require(t, err)
+	for _, storageService := range []StorageService{keyed, unkeyed, redisOverUnkeyed} {
+		value := []byte("a value to keep track of")
+		key, err := PutAndHash(ctx, storageService, value, 0)
+		Require(t, err)
+		if key != dastree.Hash(value) {
+			Fail(t, "returned key", key, "isn't the dastree hash of the value")
+		}
+		stored, err := storageService.GetByHash(ctx, key)
+		Require(t, err)
+		if !bytes.Equal(stored, value) {
+			Fail(t, "value stored under the returned key doesn't round trip", stored)
+		}
+	}
+}
+
+func TestMemoryBackedStorageServiceApproxEntryCount(t *testing.T) {
+	ctx := context.Background()
+	storageService := NewMemoryBackedStorageService(ctx)
+	for i := 0; i < 5; i++ {
+		Require(t, storageService.Put(ctx, []byte{byte(i)}, 0))
+	}
+	// Storing a value again doesn't add an entry.
+	Require(t, storageService.Put(ctx, []byte{0}, 0))
+	count, err := ApproxEntryCount(ctx, storageService)
+	Require(t, err)
+	if count != 5 {
+		Fail(t, "expected 5 entries, got", count)
+	}
+
+	// Hides ApproxEntryCount.
+	uncounted := struct{ StorageService }{storageService}
+	if _, err := ApproxEntryCount(ctx, uncounted); !errors.Is(err, ErrEntryCountNotSupported) {
+		Fail(t, "expected ErrEntryCountNotSupported, got", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+const prometheusMetricsPath = "/metrics"
+
+type PrometheusMetricsConfig struct {
+	Enable bool   `koanf:"enable"`
+	Addr   string `koanf:"addr"`
+	Port   uint64 `koanf:"port"`
+}
+
+var DefaultPrometheusMetricsConfig = PrometheusMetricsConfig{
+	Enable: false,
+	Addr:   "127.0.0.1",
+	Port:   6071,
+}
+
+func PrometheusMetricsConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultPrometheusMetricsConfig.Enable, "enable serving the DAS metrics in Prometheus text format at "+prometheusMetricsPath)
+	f.String(prefix+".addr", DefaultPrometheusMetricsConfig.Addr, "DAS Prometheus metrics server listening interface")
+	f.Uint64(prefix+".port", DefaultPrometheusMetricsConfig.Port, "DAS Prometheus metrics server listening port")
+}
+
+// NewPrometheusMetricsHandler returns a handler exposing the DAS metrics of registry in Prometheus
+// text format. It can be mounted on any of the node's HTTP servers.
+func NewPrometheusMetricsHandler(registry metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dasRegistry := metrics.NewRegistry()
+		registry.Each(func(name string, metric interface{}) {
+			if strings.HasPrefix(name, dasutil.MetricsPrefix) {
+				if err := dasRegistry.Register(name, metric); err != nil {
+					log.Warn("Couldn't expose DAS metric", "name", name, "err", err)
+				}
+			}
+		})
+		prometheus.Handler(dasRegistry).ServeHTTP(w, r)
+	})
+}
+
+// PrometheusMetricsServer serves the DAS metrics of the default registry in Prometheus text format.
+type PrometheusMetricsServer struct {
+	server *http.Server
+}
+
+func StartPrometheusMetricsServer(config PrometheusMetricsConfig) (*PrometheusMetricsServer, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Addr, config.Port))
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(prometheusMetricsPath, NewPrometheusMetricsHandler(metrics.DefaultRegistry))
+	s := &PrometheusMetricsServer{
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: genericconf.HTTPServerTimeoutConfigDefault.ReadHeaderTimeout,
+		},
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("DAS Prometheus metrics server failed", "err", err)
+		}
+	}()
+	log.Info("Serving DAS metrics in Prometheus format", "addr", listener.Addr(), "path", prometheusMetricsPath)
+	return s, nil
+}
+
+func (s *PrometheusMetricsServer) Close(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *PrometheusMetricsServer) String() string {
+	return "PrometheusMetricsServer"
+}
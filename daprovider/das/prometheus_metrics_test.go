@@ -0,0 +1,38 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func TestPrometheusMetricsHandler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	metrics.NewRegisteredGauge("arb/das/prometheus/test/gauge", registry).Update(42)
+	metrics.NewRegisteredGauge("arb/other/prometheus/test/gauge", registry).Update(7)
+
+	server := httptest.NewServer(NewPrometheusMetricsHandler(registry))
+	defer server.Close()
+	res, err := http.Get(server.URL)
+	Require(t, err)
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		Fail(t, "unexpected status", res.StatusCode)
+	}
+	body, err := io.ReadAll(res.Body)
+	Require(t, err)
+
+	if !strings.Contains(string(body), "arb_das_prometheus_test_gauge 42") {
+		Fail(t, "expected DAS metric in scrape, got", string(body))
+	}
+	if strings.Contains(string(body), "arb_other_prometheus_test_gauge") {
+		Fail(t, "non-DAS metric exposed", string(body))
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+// stubSocks5Proxy is a minimal unauthenticated SOCKS5 proxy, recording the addresses it connects to.
+type stubSocks5Proxy struct {
+	listener net.Listener
+
+	mutex sync.Mutex
+	dials []string
+}
+
+func startStubSocks5Proxy(t *testing.T) *stubSocks5Proxy {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Require(t, err)
+	p := &stubSocks5Proxy{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go p.serve(conn)
+		}
+	}()
+	return p
+}
+
+func (p *stubSocks5Proxy) url() string {
+	return "socks5://" + p.listener.Addr().String()
+}
+
+func (p *stubSocks5Proxy) dialed() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]string{}, p.dials...)
+}
+
+func (p *stubSocks5Proxy) serve(conn net.Conn) {
+	defer conn.Close()
+	// Greeting: version, number of methods and the methods, answered with "no authentication".
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return
+	}
+	// Request: version, command, reserved and address type, followed by the address and port.
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return
+		}
+		host = net.IP(ip).String()
+	case 3:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return
+		}
+		name := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, name); err != nil {
+			return
+		}
+		host = string(name)
+	default:
+		return
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+	p.mutex.Lock()
+	p.dials = append(p.dials, addr)
+	p.mutex.Unlock()
+
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+	go func() {
+		_, _ = io.Copy(target, conn)
+		target.Close()
+	}()
+	_, _ = io.Copy(conn, target)
+}
+
+func TestNewProxyDialerRejectsInvalidConfig(t *testing.T) {
+	dial, err := NewProxyDialer("")
+	Require(t, err)
+	if dial != nil {
+		Fail(t, "expected no dialer without a proxy url")
+	}
+	for _, proxyUrl := range []string{"http://localhost:8080", "socks5://", "://bad"} {
+		if _, err := NewProxyDialer(proxyUrl); err == nil {
+			Fail(t, "expected proxy url", proxyUrl, "to be rejected")
+		}
+	}
+
+	config := RestfulClientAggregatorConfig{Urls: []string{"http://localhost:9876"}, Strategy: "testing-sequential", ProxyUrl: "http://localhost:8080"}
+	if _, err := NewRestfulClientAggregator(context.Background(), &config); err == nil {
+		Fail(t, "expected REST aggregator with invalid proxy url to fail construction")
+	}
+	redisConfig := RedisConfig{
+		Enable:     true,
+		Url:        "redis://localhost:6379",
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+		ProxyUrl:   "socks5://",
+	}
+	if _, err := NewRedisStorageService(redisConfig, NewMemoryBackedStorageService(context.Background())); err == nil {
+		Fail(t, "expected Redis storage with invalid proxy url to fail construction")
+	}
+}
+
+func TestStorageConnectionsUseProxy(t *testing.T) {
+	ctx := context.Background()
+	value := []byte("data fetched through a proxy")
+	key := dastree.Hash(value)
+
+	t.Run("rest", func(t *testing.T) {
+		proxy := startStubSocks5Proxy(t)
+		storage := NewMemoryBackedStorageService(ctx)
+		Require(t, storage.Put(ctx, value, 0))
+		server, port, err := NewRestfulDasServerOnRandomPort("127.0.0.1", storage)
+		Require(t, err)
+		defer func() {
+			Require(t, server.Shutdown())
+		}()
+		serverAddr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+
+		dial, err := NewProxyDialer(proxy.url())
+		Require(t, err)
+		client, err := NewRestfulDasClientFromURLWithDialer("http://"+serverAddr, dial)
+		Require(t, err)
+		res, err := client.GetByHash(ctx, key)
+		Require(t, err)
+		if !bytes.Equal(res, value) {
+			Fail(t, res, value)
+		}
+		if dials := proxy.dialed(); len(dials) == 0 || dials[0] != serverAddr {
+			Fail(t, "expected the REST client to connect through the proxy, proxy dialed", dials)
+		}
+	})
+
+	t.Run("redis", func(t *testing.T) {
+		proxy := startStubSocks5Proxy(t)
+		server, err := miniredis.Run()
+		Require(t, err)
+		defer server.Close()
+		redisService, err := NewRedisStorageService(RedisConfig{
+			Enable:     true,
+			Url:        "redis://" + server.Addr(),
+			Expiration: time.Hour,
+			KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+			ProxyUrl:   proxy.url(),
+		}, NewMemoryBackedStorageService(ctx))
+		Require(t, err)
+		Require(t, redisService.Put(ctx, value, 0))
+		res, err := redisService.GetByHash(ctx, key)
+		Require(t, err)
+		if !bytes.Equal(res, value) {
+			Fail(t, res, value)
+		}
+		if dials := proxy.dialed(); len(dials) == 0 || dials[0] != server.Addr() {
+			Fail(t, "expected the Redis client to connect through the proxy, proxy dialed", dials)
+		}
+		Require(t, redisService.HealthCheck(ctx))
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
 )
 
@@ -28,6 +30,14 @@ func (s *readLimitedStorageService) Put(ctx context.Context, data []byte, expira
 	panic("Logic error: readLimitedStorageService.Put shouldn't be called.")
 }
 
+func (s *readLimitedStorageService) ValidatePut(ctx context.Context, data []byte) error {
+	panic("Logic error: readLimitedStorageService.ValidatePut shouldn't be called.")
+}
+
+func (s *readLimitedStorageService) Refresh(ctx context.Context, key common.Hash, expirationTime uint64) error {
+	panic("Logic error: readLimitedStorageService.Refresh shouldn't be called.")
+}
+
 func (s *readLimitedStorageService) Sync(ctx context.Context) error {
 	panic("Logic error: readLimitedStorageService.Store shouldn't be called.")
 }
@@ -8,6 +8,10 @@ import (
 	"crypto/hmac"
 	"errors"
 	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -24,56 +28,249 @@ import (
 )
 
 type RedisConfig struct {
-	Enable     bool          `koanf:"enable"`
-	Url        string        `koanf:"url"`
-	Expiration time.Duration `koanf:"expiration"`
-	KeyConfig  string        `koanf:"key-config"`
+	Enable      bool          `koanf:"enable"`
+	Url         string        `koanf:"url"`
+	Expiration  time.Duration `koanf:"expiration"`
+	KeyConfig   string        `koanf:"key-config"`
+	GetTimeout  time.Duration `koanf:"get-timeout"`
+	PutTimeout  time.Duration `koanf:"put-timeout"`
+	PingTimeout time.Duration `koanf:"ping-timeout"`
+	// ReadPreference is RedisReadCacheFirst or RedisReadBaseFirst.
+	ReadPreference string `koanf:"read-preference"`
+	ProxyUrl       string `koanf:"proxy-url"`
+	// TenantKeyConfigs are the signing keys of the tenants sharing the cache, as tenant=key-config.
+	TenantKeyConfigs []string         `koanf:"tenant-key-configs"`
+	Sweep            RedisSweepConfig `koanf:"sweep"`
+	// CredentialsProvider, if set, supplies the credentials of each new connection, so that
+	// reconnects authenticate with a rotated token instead of the password in the url.
+	CredentialsProvider redisutil.CredentialsProvider `koanf:"-"`
 }
 
+const (
+	RedisReadCacheFirst = "cache-first"
+	RedisReadBaseFirst  = "base-first"
+)
+
 var DefaultRedisConfig = RedisConfig{
-	Url:        "",
-	Expiration: time.Hour,
-	KeyConfig:  "",
+	Url:         "",
+	Expiration:  time.Hour,
+	KeyConfig:   "",
+	GetTimeout:  0,
+	PutTimeout:  0,
+	PingTimeout: 0,
+
+	ReadPreference:   RedisReadCacheFirst,
+	ProxyUrl:         "",
+	TenantKeyConfigs: nil,
+	Sweep:            DefaultRedisSweepConfig,
 }
 
 func RedisConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultRedisConfig.Enable, "enable Redis caching of sequencer batch data")
 	f.String(prefix+".url", DefaultRedisConfig.Url, "Redis url")
 	f.Duration(prefix+".expiration", DefaultRedisConfig.Expiration, "Redis expiration")
-	f.String(prefix+".key-config", DefaultRedisConfig.KeyConfig, "Redis key config")
+	f.String(prefix+".key-config", DefaultRedisConfig.KeyConfig, "Redis HMAC signing key, either as 32 bytes of hex or as the path to a file containing it")
+	f.Duration(prefix+".get-timeout", DefaultRedisConfig.GetTimeout, "timeout of each Redis read, after which the base storage is used instead (0 for no limit)")
+	f.Duration(prefix+".put-timeout", DefaultRedisConfig.PutTimeout, "timeout of each Redis write (0 for no limit)")
+	f.Duration(prefix+".ping-timeout", DefaultRedisConfig.PingTimeout, "timeout of the Redis ping of health checks (0 for no limit)")
+	f.String(prefix+".read-preference", DefaultRedisConfig.ReadPreference, "order of reads: \""+RedisReadCacheFirst+"\" reads Redis before the base storage and caches base hits, \""+RedisReadBaseFirst+"\" reads the base storage before Redis")
+	f.String(prefix+".proxy-url", DefaultRedisConfig.ProxyUrl, "SOCKS5 proxy to connect to Redis through, e.g. socks5://localhost:1080; connects directly if empty")
+	f.StringSlice(prefix+".tenant-key-configs", DefaultRedisConfig.TenantKeyConfigs, "HMAC signing keys of the tenants sharing the Redis cache, each as tenant=key, with the key given as in key-config; requests of a tenant only read and write entries signed with its key")
+	RedisSweepConfigAddOptions(prefix+".sweep", f)
+}
+
+// Validate checks the config of an enabled Redis cache, returning all problems found at once.
+func (c *RedisConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	var errs []error
+	if c.Url == "" {
+		errs = append(errs, errors.New("redis-cache.url must be set when the Redis cache is enabled"))
+	}
+	if c.KeyConfig == "" {
+		errs = append(errs, errors.New("redis-cache.key-config must be set when the Redis cache is enabled"))
+	} else if _, err := secretKeyFromConfig(c.KeyConfig); err != nil {
+		errs = append(errs, fmt.Errorf("invalid redis-cache.key-config: %w", err))
+	}
+	// Redis treats a zero expiration as never expiring, which would grow the cache without bound.
+	if c.Expiration <= 0 {
+		errs = append(errs, fmt.Errorf("redis-cache.expiration must be positive, got %v", c.Expiration))
+	}
+	// An unset preference keeps the original cache-first behavior.
+	if c.ReadPreference != "" && c.ReadPreference != RedisReadCacheFirst && c.ReadPreference != RedisReadBaseFirst {
+		errs = append(errs, fmt.Errorf("redis-cache.read-preference must be %q or %q, got %q", RedisReadCacheFirst, RedisReadBaseFirst, c.ReadPreference))
+	}
+	if _, err := NewProxyDialer(c.ProxyUrl); err != nil {
+		errs = append(errs, fmt.Errorf("invalid redis-cache.proxy-url: %w", err))
+	}
+	if _, err := parseRedisTenantKeys(c.TenantKeyConfigs); err != nil {
+		errs = append(errs, fmt.Errorf("invalid redis-cache.tenant-key-configs: %w", err))
+	}
+	if c.Sweep.Enable {
+		if c.Sweep.Interval <= 0 {
+			errs = append(errs, fmt.Errorf("redis-cache.sweep.interval must be positive, got %v", c.Sweep.Interval))
+		}
+		if c.Sweep.SampleSize <= 0 {
+			errs = append(errs, fmt.Errorf("redis-cache.sweep.sample-size must be positive, got %d", c.Sweep.SampleSize))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func parseRedisTenantKeys(tenantKeyConfigs []string) (map[string]common.Hash, error) {
+	tenantKeys := make(map[string]common.Hash, len(tenantKeyConfigs))
+	for _, tenantKeyConfig := range tenantKeyConfigs {
+		tenant, keyConfig, ok := strings.Cut(tenantKeyConfig, "=")
+		if !ok || tenant == "" {
+			return nil, fmt.Errorf("tenant key config %q isn't of the form tenant=key", tenantKeyConfig)
+		}
+		// Colons separate the tenant from the hash in the keys of its entries.
+		if strings.Contains(tenant, ":") {
+			return nil, fmt.Errorf("tenant %q contains a colon", tenant)
+		}
+		if _, exists := tenantKeys[tenant]; exists {
+			return nil, fmt.Errorf("tenant %q has more than one key", tenant)
+		}
+		key, err := secretKeyFromConfig(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("key of tenant %q: %w", tenant, err)
+		}
+		tenantKeys[tenant] = key
+	}
+	return tenantKeys, nil
+}
+
+// ErrUnknownRedisTenant is returned for requests of tenants the Redis cache has no signing key for.
+var ErrUnknownRedisTenant = errors.New("unknown Redis cache tenant")
+
+type redisTenantContextKey struct{}
+
+// WithRedisTenant returns a context whose requests to a RedisStorageService are signed and verified
+// with the key of the given tenant, so that tenants sharing the cache can't read or forge each
+// other's entries. Requests without a tenant use the cache's key-config. Each tenant's entries and
+// pins are kept under keys of its own, so tenants caching the same hash don't overwrite each other.
+func WithRedisTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, redisTenantContextKey{}, tenant)
+}
+
+func redisTenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(redisTenantContextKey{}).(string)
+	return tenant
+}
+
+// redisTenantKeyPrefix starts the keys of the entries and pins of tenants. Requests without a tenant
+// keep keying entries by the raw hash, so that caches written before tenants existed stay readable.
+const redisTenantKeyPrefix = "das-tenant:"
+
+// redisEntryKey returns the key of the tenant's entry for hash.
+func redisEntryKey(tenant string, hash common.Hash) string {
+	if tenant == "" {
+		return string(hash.Bytes())
+	}
+	return redisTenantKeyPrefix + tenant + ":" + string(hash.Bytes())
+}
+
+// parseRedisEntryKey returns the tenant and hash of an entry key, or false if key isn't one.
+func parseRedisEntryKey(key string) (string, common.Hash, bool) {
+	rest, ok := strings.CutPrefix(key, redisTenantKeyPrefix)
+	if !ok {
+		if len(key) != common.HashLength {
+			return "", common.Hash{}, false
+		}
+		return "", common.BytesToHash([]byte(key)), true
+	}
+	sep := len(rest) - common.HashLength - 1
+	if sep < 1 || rest[sep] != ':' || strings.Contains(rest[:sep], ":") {
+		return "", common.Hash{}, false
+	}
+	return rest[:sep], common.BytesToHash([]byte(rest[sep+1:])), true
+}
+
+// redisPinnedSetKeyFor returns the key of the Redis set holding the hashes pinned by the tenant.
+func redisPinnedSetKeyFor(tenant string) string {
+	if tenant == "" {
+		return redisPinnedSetKey
+	}
+	return redisTenantKeyPrefix + tenant + ":pinned"
 }
 
 type RedisStorageService struct {
 	baseStorageService StorageService
 	redisConfig        RedisConfig
 	signingKey         common.Hash
+	tenantKeys         map[string]common.Hash
 	client             redis.UniversalClient
+	// sealer, if set, encrypts the values cached in Redis.
+	sealer                      *valueSealer
+	expirationPolicyAggregation ExpirationPolicyAggregation
 }
 
 func NewRedisStorageService(redisConfig RedisConfig, baseStorageService StorageService) (StorageService, error) {
-	redisClient, err := redisutil.RedisClientFromURL(redisConfig.Url)
+	if err := redisConfig.Validate(); err != nil {
+		return nil, err
+	}
+	signingKey, err := secretKeyFromConfig(redisConfig.KeyConfig)
+	if err != nil {
+		return nil, err
+	}
+	tenantKeys, err := parseRedisTenantKeys(redisConfig.TenantKeyConfigs)
 	if err != nil {
 		return nil, err
 	}
-	signingKey := common.HexToHash(redisConfig.KeyConfig)
-	if signingKey == (common.Hash{}) {
-		return nil, errors.New("signing key file contents are not 32 bytes of hex")
+	dial, err := NewProxyDialer(redisConfig.ProxyUrl)
+	if err != nil {
+		return nil, err
+	}
+	redisClient, err := redisutil.RedisClientFromURLWithCredentials(redisConfig.Url, dial, redisConfig.CredentialsProvider)
+	if err != nil {
+		return nil, err
 	}
 	return &RedisStorageService{
 		baseStorageService: baseStorageService,
 		redisConfig:        redisConfig,
 		signingKey:         signingKey,
+		tenantKeys:         tenantKeys,
 		client:             redisClient,
 	}, nil
 }
 
-func (rs *RedisStorageService) verifyMessageSignature(data []byte) ([]byte, error) {
+// NewEncryptingRedisStorageService is NewRedisStorageService, AES-GCM encrypting the values cached in
+// Redis with the key of the encryption config, so that Redis never holds them in plaintext. The
+// base storage is written as given, so it must encrypt values itself.
+func NewEncryptingRedisStorageService(redisConfig RedisConfig, encryptionConfig EncryptionConfig, baseStorageService StorageService) (StorageService, error) {
+	sealer, err := newValueSealer(encryptionConfig)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewRedisStorageService(redisConfig, baseStorageService)
+	if err != nil {
+		return nil, err
+	}
+	s.(*RedisStorageService).sealer = sealer
+	return s, nil
+}
+
+// signingKeyFor returns the signing key of the tenant of the request.
+func (rs *RedisStorageService) signingKeyFor(ctx context.Context) (common.Hash, error) {
+	tenant := redisTenantFromContext(ctx)
+	if tenant == "" {
+		return rs.signingKey, nil
+	}
+	key, ok := rs.tenantKeys[tenant]
+	if !ok {
+		return common.Hash{}, fmt.Errorf("%w: %q", ErrUnknownRedisTenant, tenant)
+	}
+	return key, nil
+}
+
+func verifyMessageSignature(signingKey common.Hash, data []byte) ([]byte, error) {
 	if len(data) < 32 {
 		return nil, errors.New("data is too short to contain message signature")
 	}
 	message := data[:len(data)-32]
 	haveHmac := common.BytesToHash(data[len(data)-32:])
-	mac := hmac.New(sha3.NewLegacyKeccak256, rs.signingKey[:])
+	mac := hmac.New(sha3.NewLegacyKeccak256, signingKey[:])
 	mac.Write(message)
 	expectHmac := mac.Sum(nil)
 	if !hmac.Equal(haveHmac[:], expectHmac) {
@@ -82,27 +279,49 @@ func (rs *RedisStorageService) verifyMessageSignature(data []byte) ([]byte, erro
 	return message, nil
 }
 
+// ctxWithTimeout is like context.WithTimeout except a timeout of 0 means unlimited instead of instantly
+// expired. It bounds individual Redis operations, so that a slow one doesn't use up the budget of
+// the whole request.
+func ctxWithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
 func (rs *RedisStorageService) getVerifiedData(ctx context.Context, key common.Hash) ([]byte, error) {
-	data, err := rs.client.Get(ctx, string(key.Bytes())).Bytes()
+	signingKey, err := rs.signingKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := ctxWithTimeout(ctx, rs.redisConfig.GetTimeout)
+	defer cancel()
+	data, err := rs.client.Get(ctx, redisEntryKey(redisTenantFromContext(ctx), key)).Bytes()
 	if err != nil {
 		log.Error("das.RedisStorageService.getVerifiedData", "err", err)
 		return nil, err
 	}
-	data, err = rs.verifyMessageSignature(data)
+	data, err = verifyMessageSignature(signingKey, data)
 	if err != nil {
 		return nil, err
 	}
+	if rs.sealer != nil {
+		return rs.sealer.open(key, data)
+	}
 	return data, err
 }
 
-func (rs *RedisStorageService) signMessage(message []byte) []byte {
-	mac := hmac.New(sha3.NewLegacyKeccak256, rs.signingKey[:])
+func signMessage(signingKey common.Hash, message []byte) []byte {
+	mac := hmac.New(sha3.NewLegacyKeccak256, signingKey[:])
 	mac.Write(message)
 	return mac.Sum(message)
 }
 
 func (rs *RedisStorageService) GetByHash(ctx context.Context, key common.Hash) ([]byte, error) {
 	log.Trace("das.RedisStorageService.GetByHash", "key", pretty.PrettyHash(key), "this", rs)
+	if rs.redisConfig.ReadPreference == RedisReadBaseFirst {
+		return rs.getBaseFirst(ctx, key)
+	}
 	ret, err := rs.getVerifiedData(ctx, key)
 	if err != nil {
 		ret, err = rs.baseStorageService.GetByHash(ctx, key)
@@ -110,7 +329,7 @@ func (rs *RedisStorageService) GetByHash(ctx context.Context, key common.Hash) (
 			return nil, err
 		}
 
-		err = rs.client.Set(ctx, string(key.Bytes()), rs.signMessage(ret), rs.redisConfig.Expiration).Err()
+		err = rs.set(ctx, key, ret)
 		if err != nil {
 			return nil, err
 		}
@@ -120,21 +339,253 @@ func (rs *RedisStorageService) GetByHash(ctx context.Context, key common.Hash) (
 	return ret, err
 }
 
+// getBaseFirst reads the base storage, falling back to Redis if that fails. Base hits aren't cached,
+// as Redis is only read for values missing from the base.
+func (rs *RedisStorageService) getBaseFirst(ctx context.Context, key common.Hash) ([]byte, error) {
+	ret, baseErr := rs.baseStorageService.GetByHash(ctx, key)
+	if baseErr == nil {
+		return ret, nil
+	}
+	ret, err := rs.getVerifiedData(ctx, key)
+	if err != nil {
+		return nil, baseErr
+	}
+	return ret, nil
+}
+
+// GetByHashWithTimeout is a timeout-aware GetByHash. Once the cert timeout has passed, data is only
+// served from the base storage if the base keeps data forever: the Redis cache and any timeout-based
+// base may be serving data that is logically expired, so the read fails with ErrDataExpired instead.
+func (rs *RedisStorageService) GetByHashWithTimeout(ctx context.Context, key common.Hash, timeout uint64) ([]byte, error) {
+	// #nosec G115
+	if uint64(time.Now().Unix()) <= timeout {
+		return rs.GetByHash(ctx, key)
+	}
+	log.Trace("das.RedisStorageService.GetByHashWithTimeout bypassing cache for expired data", "key", pretty.PrettyHash(key), "timeout", timeout)
+	expirationPolicy, err := rs.baseStorageService.ExpirationPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if expirationPolicy != dasutil.KeepForever {
+		return nil, fmt.Errorf("%w: timeout %d passed and base storage policy is %d", ErrDataExpired, timeout, expirationPolicy)
+	}
+	return rs.baseStorageService.GetByHash(ctx, key)
+}
+
 func (rs *RedisStorageService) Put(ctx context.Context, value []byte, timeout uint64) error {
 	logPut("das.RedisStorageService.Store", value, timeout, rs)
 	err := rs.baseStorageService.Put(ctx, value, timeout)
 	if err != nil {
 		return err
 	}
-	err = rs.client.Set(
-		ctx, string(dastree.Hash(value).Bytes()), rs.signMessage(value), rs.redisConfig.Expiration,
-	).Err()
+	err = rs.set(ctx, dastree.Hash(value), value)
 	if err != nil {
 		log.Error("das.RedisStorageService.Store", "err", err)
 	}
 	return err
 }
 
+// PutWithKey stores the value under key in both the base storage, which must support explicit keys, and Redis.
+func (rs *RedisStorageService) PutWithKey(ctx context.Context, key common.Hash, value []byte, timeout uint64) error {
+	logPut("das.RedisStorageService.PutWithKey", value, timeout, rs, "key", pretty.PrettyHash(key))
+	keyedBase, ok := rs.baseStorageService.(KeyedStorageService)
+	if !ok {
+		return fmt.Errorf("base storage %v doesn't support explicit keys", rs.baseStorageService)
+	}
+	err := keyedBase.PutWithKey(ctx, key, value, timeout)
+	if err != nil {
+		return err
+	}
+	err = rs.set(ctx, key, value)
+	if err != nil {
+		log.Error("das.RedisStorageService.PutWithKey", "err", err)
+	}
+	return err
+}
+
+// redisPinnedSetKey is the Redis set holding the keys of values pinned by requests without a tenant.
+const redisPinnedSetKey = "das-pinned"
+
+// set caches the signed value in Redis, with the configured expiration unless it's pinned. The
+// value is written along with the check of its pin in a single transaction, so that writes of
+// unpinned values, by far the most common, take a single round-trip, and a concurrent Pin is
+// either seen by the check or persists the value after it's written.
+func (rs *RedisStorageService) set(ctx context.Context, key common.Hash, value []byte) error {
+	signingKey, err := rs.signingKeyFor(ctx)
+	if err != nil {
+		return err
+	}
+	if rs.sealer != nil {
+		value, err = rs.sealer.seal(key, value)
+		if err != nil {
+			return err
+		}
+	}
+	ctx, cancel := ctxWithTimeout(ctx, rs.redisConfig.PutTimeout)
+	defer cancel()
+	tenant := redisTenantFromContext(ctx)
+	entryKey := redisEntryKey(tenant, key)
+	var pinned *redis.BoolCmd
+	_, err = rs.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, entryKey, signMessage(signingKey, value), rs.redisConfig.Expiration)
+		pinned = pipe.SIsMember(ctx, redisPinnedSetKeyFor(tenant), key.Bytes())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if pinned.Val() {
+		return rs.client.Persist(ctx, entryKey).Err()
+	}
+	return nil
+}
+
+// Pin keeps the value in Redis without expiration and pins it in the base storage. Bases that
+// don't support pinning must keep data forever.
+func (rs *RedisStorageService) Pin(ctx context.Context, key common.Hash) error {
+	if err := rs.pinBase(ctx, key); err != nil {
+		return err
+	}
+	ctx, cancel := ctxWithTimeout(ctx, rs.redisConfig.PutTimeout)
+	defer cancel()
+	tenant := redisTenantFromContext(ctx)
+	if err := rs.client.SAdd(ctx, redisPinnedSetKeyFor(tenant), key.Bytes()).Err(); err != nil {
+		return err
+	}
+	return rs.client.Persist(ctx, redisEntryKey(tenant, key)).Err()
+}
+
+func (rs *RedisStorageService) pinBase(ctx context.Context, key common.Hash) error {
+	if pinnableBase, ok := rs.baseStorageService.(PinnableStorageService); ok {
+		return pinnableBase.Pin(ctx, key)
+	}
+	expirationPolicy, err := rs.baseStorageService.ExpirationPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	if expirationPolicy != dasutil.KeepForever {
+		return fmt.Errorf("base storage %v doesn't support pinning", rs.baseStorageService)
+	}
+	_, err = rs.baseStorageService.GetByHash(ctx, key)
+	return err
+}
+
+// Unpin restores the configured Redis expiration of the value and unpins it in the base storage.
+func (rs *RedisStorageService) Unpin(ctx context.Context, key common.Hash) error {
+	if err := rs.unpinCached(ctx, key); err != nil {
+		return err
+	}
+	if pinnableBase, ok := rs.baseStorageService.(PinnableStorageService); ok {
+		return pinnableBase.Unpin(ctx, key)
+	}
+	return nil
+}
+
+func (rs *RedisStorageService) unpinCached(ctx context.Context, key common.Hash) error {
+	ctx, cancel := ctxWithTimeout(ctx, rs.redisConfig.PutTimeout)
+	defer cancel()
+	tenant := redisTenantFromContext(ctx)
+	if err := rs.client.SRem(ctx, redisPinnedSetKeyFor(tenant), key.Bytes()).Err(); err != nil {
+		return err
+	}
+	return rs.client.Expire(ctx, redisEntryKey(tenant, key), rs.redisConfig.Expiration).Err()
+}
+
+// redisMaxValueSize is the maximum size of a Redis string value.
+const redisMaxValueSize = 512 << 20
+
+// ValidatePut checks the value fits in Redis along with its HMAC and encryption overhead, and that
+// the base storage accepts it.
+func (rs *RedisStorageService) ValidatePut(ctx context.Context, value []byte) error {
+	if err := rs.checkValueSize(len(value)); err != nil {
+		return err
+	}
+	return rs.baseStorageService.ValidatePut(ctx, value)
+}
+
+// ValidatePutSize checks a value of the given size fits in Redis, and that the base storage, which
+// must support explicit keys, accepts it.
+func (rs *RedisStorageService) ValidatePutSize(ctx context.Context, size int) error {
+	keyedBase, ok := rs.baseStorageService.(KeyedStorageService)
+	if !ok {
+		return fmt.Errorf("base storage %v doesn't support explicit keys", rs.baseStorageService)
+	}
+	if err := rs.checkValueSize(size); err != nil {
+		return err
+	}
+	return keyedBase.ValidatePutSize(ctx, size)
+}
+
+func (rs *RedisStorageService) checkValueSize(size int) error {
+	maxSize := redisMaxValueSize - int64(len(common.Hash{}))
+	if rs.sealer != nil {
+		maxSize -= int64(rs.sealer.overhead())
+	}
+	return checkPutSize(size, maxSize)
+}
+
+// Refresh extends the retention of the value in the base storage and restarts its Redis expiration,
+// unless it's pinned.
+func (rs *RedisStorageService) Refresh(ctx context.Context, key common.Hash, timeout uint64) error {
+	if err := rs.baseStorageService.Refresh(ctx, key, timeout); err != nil {
+		return err
+	}
+	putCtx, cancel := ctxWithTimeout(ctx, rs.redisConfig.PutTimeout)
+	defer cancel()
+	tenant := redisTenantFromContext(ctx)
+	pinned, err := rs.client.SIsMember(putCtx, redisPinnedSetKeyFor(tenant), key.Bytes()).Result()
+	if err == nil && !pinned {
+		err = rs.client.Expire(putCtx, redisEntryKey(tenant, key), rs.redisConfig.Expiration).Err()
+	}
+	if err != nil {
+		log.Error("das.RedisStorageService.Refresh", "err", err)
+	}
+	return err
+}
+
+// redisEntryCountSampleSize is the number of keys ApproxEntryCount samples to estimate the share of
+// the Redis database used by the cache.
+const redisEntryCountSampleSize = 1000
+
+// isRedisEntryKey tells the keys of cached values, which are raw 32 byte hashes or tenant entry keys,
+// apart from the other keys of the database, such as pinned sets or keys of other applications sharing it.
+func isRedisEntryKey(key string) bool {
+	_, _, ok := parseRedisEntryKey(key)
+	return ok
+}
+
+// ApproxEntryCount estimates the number of values cached in Redis, not counting the base storage. As
+// the database may hold other keys, the database size is scaled by the share of cache keys among a
+// sample of keys. The count is exact for databases smaller than the sample.
+func (rs *RedisStorageService) ApproxEntryCount(ctx context.Context) (uint64, error) {
+	ctx, cancel := ctxWithTimeout(ctx, rs.redisConfig.GetTimeout)
+	defer cancel()
+	size, err := rs.client.DBSize(ctx).Result()
+	if err != nil {
+		return 0, err
+	}
+	var sampled, entries, cursor uint64
+	for sampled < redisEntryCountSampleSize {
+		var keys []string
+		keys, cursor, err = rs.client.Scan(ctx, cursor, "", redisEntryCountSampleSize).Result()
+		if err != nil {
+			return 0, err
+		}
+		for _, key := range keys {
+			sampled++
+			if isRedisEntryKey(key) {
+				entries++
+			}
+		}
+		if cursor == 0 {
+			// The whole database was scanned.
+			return entries, nil
+		}
+	}
+	// #nosec G115
+	return uint64(size) * entries / sampled, nil
+}
+
 func (rs *RedisStorageService) Sync(ctx context.Context) error {
 	return rs.baseStorageService.Sync(ctx)
 }
@@ -147,16 +598,35 @@ func (rs *RedisStorageService) Close(ctx context.Context) error {
 	return rs.baseStorageService.Close(ctx)
 }
 
+// SetExpirationPolicyAggregation sets how the policy of the Redis cache is aggregated with the
+// policy of its base, AggregateMostDurable by default. It must be called before the service is used.
+func (rs *RedisStorageService) SetExpirationPolicyAggregation(aggregation ExpirationPolicyAggregation) {
+	rs.expirationPolicyAggregation = aggregation
+}
+
 func (rs *RedisStorageService) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
-	return rs.baseStorageService.ExpirationPolicy(ctx)
+	return chainedExpirationPolicy(ctx, rs.expirationPolicyAggregation, cacheTierExpirationPolicy, rs.baseStorageService)
 }
 
+// String describes the cache without its signing keys or the credentials of its url, so that it can
+// be logged.
 func (rs *RedisStorageService) String() string {
-	return fmt.Sprintf("RedisStorageService(%+v)", rs.redisConfig)
+	tenants := slices.Sorted(maps.Keys(rs.tenantKeys))
+	return fmt.Sprintf("RedisStorageService(url:%s, expiration:%v, readPreference:%s, tenants:%v)", redactedRedisUrl(rs.redisConfig.Url), rs.redisConfig.Expiration, rs.redisConfig.ReadPreference, tenants)
+}
+
+func redactedRedisUrl(redisUrl string) string {
+	u, err := url.Parse(redisUrl)
+	if err != nil {
+		return "<unparseable>"
+	}
+	return u.Redacted()
 }
 
 func (rs *RedisStorageService) HealthCheck(ctx context.Context) error {
-	err := rs.client.Ping(ctx).Err()
+	pingCtx, cancel := ctxWithTimeout(ctx, rs.redisConfig.PingTimeout)
+	defer cancel()
+	err := rs.client.Ping(pingCtx).Err()
 	if err != nil {
 		return err
 	}
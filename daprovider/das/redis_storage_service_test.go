@@ -7,12 +7,21 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
 )
 
 func TestRedisStorageService(t *testing.T) {
@@ -100,3 +109,648 @@ func TestRedisStorageService(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestRedisSigningKeyFromFile(t *testing.T) {
+	keyHex := "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9"
+	dir := t.TempDir()
+
+	keyFile := filepath.Join(dir, "redis.key")
+	Require(t, os.WriteFile(keyFile, []byte(keyHex+"\n"), 0o600))
+	fromFile, err := secretKeyFromConfig(keyFile)
+	Require(t, err)
+	inline, err := secretKeyFromConfig(keyHex)
+	Require(t, err)
+	if fromFile != inline || fromFile != common.HexToHash(keyHex) {
+		Fail(t, "key loaded from file doesn't match inline key", fromFile, inline)
+	}
+
+	shortFile := filepath.Join(dir, "short.key")
+	Require(t, os.WriteFile(shortFile, []byte(keyHex[:32]), 0o600))
+	if _, err := secretKeyFromConfig(shortFile); err == nil {
+		Fail(t, "expected short key file to be rejected")
+	}
+
+	if _, err := secretKeyFromConfig(filepath.Join(dir, "missing.key")); err == nil {
+		Fail(t, "expected missing key file to be rejected")
+	}
+}
+
+func TestRedisStorageServiceGetByHashWithTimeout(t *testing.T) {
+	ctx := context.Background()
+	server, err := miniredis.Run()
+	Require(t, err)
+	redisConfig := RedisConfig{
+		Enable:     true,
+		Url:        "redis://" + server.Addr(),
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+	}
+	// #nosec G115
+	expired := uint64(time.Now().Add(-time.Hour).Unix())
+	// #nosec G115
+	unexpired := uint64(time.Now().Add(time.Hour).Unix())
+
+	val := []byte("The cached value")
+	key := dastree.Hash(val)
+
+	// Populate the Redis cache through a service whose base is then discarded.
+	populatingService, err := NewRedisStorageService(redisConfig, NewMemoryBackedStorageService(ctx))
+	Require(t, err)
+	Require(t, populatingService.Put(ctx, val, unexpired))
+
+	durableBase := NewMemoryBackedStorageService(ctx)
+	redisService, err := NewRedisStorageService(redisConfig, durableBase)
+	Require(t, err)
+	rs, ok := redisService.(*RedisStorageService)
+	if !ok {
+		Fail(t, "unexpected storage service type")
+	}
+
+	// Before the timeout the cached value is served.
+	res, err := rs.GetByHashWithTimeout(ctx, key, unexpired)
+	Require(t, err)
+	if !bytes.Equal(res, val) {
+		Fail(t, res, val)
+	}
+
+	// After the timeout the cache is bypassed and the durable base doesn't have it.
+	_, err = rs.GetByHashWithTimeout(ctx, key, expired)
+	if !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected ErrNotFound from durable base, got", err)
+	}
+
+	Require(t, durableBase.Put(ctx, val, expired))
+	res, err = rs.GetByHashWithTimeout(ctx, key, expired)
+	Require(t, err)
+	if !bytes.Equal(res, val) {
+		Fail(t, res, val)
+	}
+
+	// A timeout-based base refuses to serve expired data.
+	timeoutBase := withPolicy(ctx, dasutil.DiscardAfterDataTimeout)
+	Require(t, timeoutBase.Put(ctx, val, expired))
+	timeoutService, err := NewRedisStorageService(redisConfig, timeoutBase)
+	Require(t, err)
+	_, err = timeoutService.(*RedisStorageService).GetByHashWithTimeout(ctx, key, expired)
+	if !errors.Is(err, ErrDataExpired) {
+		Fail(t, "expected ErrDataExpired, got", err)
+	}
+}
+
+func TestRedisConfigValidate(t *testing.T) {
+	valid := RedisConfig{
+		Enable:     true,
+		Url:        "redis://localhost:6379",
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+	}
+	Require(t, valid.Validate())
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*RedisConfig)
+		errs   []string
+	}{
+		{"disabled", func(c *RedisConfig) { *c = RedisConfig{} }, nil},
+		{"empty url", func(c *RedisConfig) { c.Url = "" }, []string{"url"}},
+		{"missing key", func(c *RedisConfig) { c.KeyConfig = "" }, []string{"key-config must be set"}},
+		{"short key", func(c *RedisConfig) { c.KeyConfig = "b561f5d5" }, []string{"invalid redis-cache.key-config"}},
+		{"zero expiration", func(c *RedisConfig) { c.Expiration = 0 }, []string{"expiration"}},
+		{"negative expiration", func(c *RedisConfig) { c.Expiration = -time.Second }, []string{"expiration"}},
+		{"unknown read preference", func(c *RedisConfig) { c.ReadPreference = "redis-first" }, []string{"read-preference"}},
+		{"sweep without sample", func(c *RedisConfig) { c.Sweep = RedisSweepConfig{Enable: true, Interval: time.Minute} }, []string{"sweep.sample-size"}},
+		{"everything", func(c *RedisConfig) { *c = RedisConfig{Enable: true} }, []string{"url", "key-config must be set", "expiration"}},
+	} {
+		config := valid
+		tc.modify(&config)
+		err := config.Validate()
+		if len(tc.errs) == 0 {
+			Require(t, err, tc.name)
+			continue
+		}
+		if err == nil {
+			Fail(t, tc.name, "expected validation to fail")
+		}
+		for _, expected := range tc.errs {
+			if !strings.Contains(err.Error(), expected) {
+				Fail(t, tc.name, "expected error to mention", expected, "got", err)
+			}
+		}
+		if _, err := NewRedisStorageService(config, NewMemoryBackedStorageService(context.Background())); err == nil {
+			Fail(t, tc.name, "expected NewRedisStorageService to reject the config")
+		}
+	}
+}
+
+func TestRedisStorageServicePin(t *testing.T) {
+	ctx := context.Background()
+	server, err := miniredis.Run()
+	Require(t, err)
+	redisConfig := RedisConfig{
+		Enable:     true,
+		Url:        "redis://" + server.Addr(),
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+	}
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	val := []byte("a preimage needed for a challenge")
+	key := dastree.Hash(val)
+	cacheKey := string(key.Bytes())
+
+	base := NewMemoryBackedStorageService(ctx)
+	redisService, err := NewRedisStorageService(redisConfig, base)
+	Require(t, err)
+	rs := redisService.(*RedisStorageService)
+	Require(t, rs.Put(ctx, val, timeout))
+	Require(t, rs.Pin(ctx, key))
+	server.FastForward(2 * time.Hour)
+	if !server.Exists(cacheKey) {
+		Fail(t, "expected pinned value to outlive the cache expiration")
+	}
+
+	// A new service over the same Redis keeps caching the value without expiration.
+	server.Del(cacheKey)
+	restarted, err := NewRedisStorageService(redisConfig, base)
+	Require(t, err)
+	res, err := restarted.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(res, val) {
+		Fail(t, res, val)
+	}
+	if ttl := server.TTL(cacheKey); ttl != 0 {
+		Fail(t, "expected pinned value read from the base to be cached without expiration, got TTL", ttl)
+	}
+	Require(t, restarted.Refresh(ctx, key, timeout))
+	if ttl := server.TTL(cacheKey); ttl != 0 {
+		Fail(t, "expected pinned value to be cached without expiration, got TTL", ttl)
+	}
+
+	Require(t, restarted.(*RedisStorageService).Unpin(ctx, key))
+	if ttl := server.TTL(cacheKey); ttl != redisConfig.Expiration {
+		Fail(t, "expected unpinned value to get the cache expiration, got TTL", ttl)
+	}
+
+	// Timeout-based bases which can't pin refuse it.
+	timeoutBase := withPolicy(ctx, dasutil.DiscardAfterDataTimeout)
+	Require(t, timeoutBase.Put(ctx, val, timeout))
+	timeoutService, err := NewRedisStorageService(redisConfig, timeoutBase)
+	Require(t, err)
+	if err := timeoutService.(*RedisStorageService).Pin(ctx, key); err == nil {
+		Fail(t, "expected pinning over a base that can't pin to fail")
+	}
+}
+
+// delayingRedisHook delays the Redis commands with the given name until the delay passes or the
+// command's context is done.
+type delayingRedisHook struct {
+	command string
+	delay   time.Duration
+}
+
+func (h delayingRedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h delayingRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == h.command {
+			select {
+			case <-time.After(h.delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h delayingRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestRedisStorageServiceOperationTimeouts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	server, err := miniredis.Run()
+	Require(t, err)
+	redisConfig := RedisConfig{
+		Enable:     true,
+		Url:        "redis://" + server.Addr(),
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+		GetTimeout: 50 * time.Millisecond,
+	}
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	val := []byte("a value cached in a slow Redis")
+	key := dastree.Hash(val)
+
+	redisService, err := NewRedisStorageService(redisConfig, NewMemoryBackedStorageService(ctx))
+	Require(t, err)
+	rs := redisService.(*RedisStorageService)
+	Require(t, rs.Put(ctx, val, timeout))
+	rs.client.AddHook(delayingRedisHook{command: "get", delay: 10 * time.Second})
+
+	// The slow Get times out on its own budget, well before the parent context.
+	start := time.Now()
+	if _, err := rs.getVerifiedData(ctx, key); !errors.Is(err, context.DeadlineExceeded) {
+		Fail(t, "expected the Redis read to time out, got", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		Fail(t, "Redis read wasn't bounded by its timeout, took", elapsed)
+	}
+	if ctx.Err() != nil {
+		Fail(t, "parent context expired", ctx.Err())
+	}
+
+	// GetByHash falls back to the base storage once the Redis read times out.
+	res, err := rs.GetByHash(ctx, key)
+	Require(t, err)
+	if !bytes.Equal(res, val) {
+		Fail(t, res, val)
+	}
+}
+
+// readOrderRecorder records the reads of a RedisStorageService from Redis and its base storage, in order.
+type readOrderRecorder struct {
+	mutex sync.Mutex
+	calls []string
+}
+
+func (r *readOrderRecorder) record(call string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *readOrderRecorder) take() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	calls := r.calls
+	r.calls = nil
+	return calls
+}
+
+type recordingBaseStorageService struct {
+	StorageService
+	recorder *readOrderRecorder
+}
+
+func (s *recordingBaseStorageService) GetByHash(ctx context.Context, key common.Hash) ([]byte, error) {
+	s.recorder.record("base get")
+	return s.StorageService.GetByHash(ctx, key)
+}
+
+type recordingRedisHook struct {
+	recorder *readOrderRecorder
+}
+
+func (h recordingRedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h recordingRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "get" || cmd.Name() == "set" {
+			h.recorder.record("redis " + cmd.Name())
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h recordingRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestRedisStorageServiceReadPreference(t *testing.T) {
+	ctx := context.Background()
+	server, err := miniredis.Run()
+	Require(t, err)
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	val := []byte("a value read in a configured order")
+	key := dastree.Hash(val)
+
+	newService := func(readPreference string, base StorageService) (*RedisStorageService, *readOrderRecorder) {
+		recorder := &readOrderRecorder{}
+		redisService, err := NewRedisStorageService(RedisConfig{
+			Enable:         true,
+			Url:            "redis://" + server.Addr(),
+			Expiration:     time.Hour,
+			KeyConfig:      "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+			ReadPreference: readPreference,
+		}, &recordingBaseStorageService{StorageService: base, recorder: recorder})
+		Require(t, err)
+		rs := redisService.(*RedisStorageService)
+		rs.client.AddHook(recordingRedisHook{recorder: recorder})
+		return rs, recorder
+	}
+	expectReads := func(rs *RedisStorageService, recorder *readOrderRecorder, expected ...string) {
+		t.Helper()
+		res, err := rs.GetByHash(ctx, key)
+		Require(t, err)
+		if !bytes.Equal(res, val) {
+			Fail(t, res, val)
+		}
+		if calls := recorder.take(); strings.Join(calls, ",") != strings.Join(expected, ",") {
+			Fail(t, rs.redisConfig.ReadPreference, "expected calls", expected, "got", calls)
+		}
+	}
+
+	base := NewMemoryBackedStorageService(ctx)
+	Require(t, base.Put(ctx, val, timeout))
+
+	// Cache-first reads Redis, then caches the base hit so the next read is served by Redis alone.
+	cacheFirst, recorder := newService(RedisReadCacheFirst, base)
+	expectReads(cacheFirst, recorder, "redis get", "base get", "redis set")
+	expectReads(cacheFirst, recorder, "redis get")
+
+	// Base-first doesn't touch Redis while the base has the value.
+	baseFirst, recorder := newService(RedisReadBaseFirst, base)
+	expectReads(baseFirst, recorder, "base get")
+
+	// Base-first falls back to Redis when the base is missing the value.
+	baseFirst, recorder = newService(RedisReadBaseFirst, NewMemoryBackedStorageService(ctx))
+	expectReads(baseFirst, recorder, "base get", "redis get")
+
+	// Reads missing from both fail with the base's error.
+	server.FlushAll()
+	if _, err := baseFirst.GetByHash(ctx, key); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected ErrNotFound, got", err)
+	}
+}
+
+func TestRedisStorageServiceApproxEntryCount(t *testing.T) {
+	ctx := context.Background()
+	server, err := miniredis.Run()
+	Require(t, err)
+	defer server.Close()
+	redisService, err := NewRedisStorageService(RedisConfig{
+		Enable:     true,
+		Url:        "redis://" + server.Addr(),
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+	}, NewMemoryBackedStorageService(ctx))
+	Require(t, err)
+	rs := redisService.(*RedisStorageService)
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	for i := 0; i < 5; i++ {
+		Require(t, rs.Put(ctx, []byte{byte(i), 'e', 'n', 't', 'r', 'y'}, timeout))
+	}
+	// Neither the set of pinned keys nor keys of other applications are counted.
+	Require(t, rs.Pin(ctx, dastree.Hash([]byte{0, 'e', 'n', 't', 'r', 'y'})))
+	Require(t, server.Set("unrelated-key", "value"))
+
+	count, err := ApproxEntryCount(ctx, rs)
+	Require(t, err)
+	if count != 5 {
+		Fail(t, "expected 5 cached entries, got", count)
+	}
+}
+
+func TestRedisStorageServiceExportImport(t *testing.T) {
+	ctx := context.Background()
+	newService := func(keyConfig string) (*RedisStorageService, *miniredis.Miniredis) {
+		server, err := miniredis.Run()
+		Require(t, err)
+		t.Cleanup(server.Close)
+		redisService, err := NewRedisStorageService(RedisConfig{
+			Enable:     true,
+			Url:        "redis://" + server.Addr(),
+			Expiration: time.Hour,
+			KeyConfig:  keyConfig,
+		}, NewMemoryBackedStorageService(ctx))
+		Require(t, err)
+		return redisService.(*RedisStorageService), server
+	}
+	source, sourceServer := newService("b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9")
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	var values [][]byte
+	for i := 0; i < 5; i++ {
+		value := []byte{byte(i), 'e', 'x', 'p', 'o', 'r', 't', 'e', 'd'}
+		values = append(values, value)
+		Require(t, source.Put(ctx, value, timeout))
+	}
+	// Only cache entries with a valid signature are exported.
+	Require(t, sourceServer.Set("unrelated-key", "value"))
+	Require(t, sourceServer.Set(string(dastree.Hash([]byte("forged")).Bytes()), "forged value without a valid signature"))
+
+	var export bytes.Buffer
+	var progress []uint64
+	exported, err := source.ExportTo(ctx, &export, func(exported uint64) { progress = append(progress, exported) })
+	Require(t, err)
+	if exported != 5 || len(progress) == 0 || progress[len(progress)-1] != 5 {
+		Fail(t, "expected 5 entries to be exported, got", exported, "progress", progress)
+	}
+
+	// The import is signed with the key of the cache it's imported to.
+	target, _ := newService("0000000000000000000000000000000000000000000000000000000000000001")
+	imported, skipped, err := target.ImportFrom(ctx, bytes.NewReader(export.Bytes()), RedisImportOptions{})
+	Require(t, err)
+	if imported != 5 || skipped != 0 {
+		Fail(t, "expected 5 entries to be imported, got", imported, "with", skipped, "skipped")
+	}
+	for _, value := range values {
+		cached, err := target.getVerifiedData(ctx, dastree.Hash(value))
+		Require(t, err)
+		if !bytes.Equal(cached, value) {
+			Fail(t, "imported wrong value", cached)
+		}
+	}
+
+	// Corrupt the value of the first entry, following its key and length.
+	corrupt := bytes.Clone(export.Bytes())
+	corrupt[common.HashLength+8] ^= 0xff
+
+	target, _ = newService("0000000000000000000000000000000000000000000000000000000000000001")
+	if _, _, err := target.ImportFrom(ctx, bytes.NewReader(corrupt), RedisImportOptions{}); !errors.Is(err, ErrCorruptRedisExportEntry) {
+		Fail(t, "expected the import of a corrupt entry to fail, got", err)
+	}
+
+	target, _ = newService("0000000000000000000000000000000000000000000000000000000000000001")
+	var importProgress [][2]uint64
+	imported, skipped, err = target.ImportFrom(ctx, bytes.NewReader(corrupt), RedisImportOptions{
+		SkipCorrupt: true,
+		Progress: func(imported uint64, skipped uint64) {
+			importProgress = append(importProgress, [2]uint64{imported, skipped})
+		},
+	})
+	Require(t, err)
+	if imported != 4 || skipped != 1 {
+		Fail(t, "expected 4 entries to be imported and 1 skipped, got", imported, skipped)
+	}
+	if len(importProgress) == 0 || importProgress[len(importProgress)-1] != [2]uint64{4, 1} {
+		Fail(t, "unexpected import progress", importProgress)
+	}
+
+	// Truncated exports can't be skipped past.
+	if _, _, err := target.ImportFrom(ctx, bytes.NewReader(export.Bytes()[:export.Len()-1]), RedisImportOptions{SkipCorrupt: true}); err == nil {
+		Fail(t, "expected the import of a truncated export to fail")
+	}
+}
+
+func TestRedisStorageServiceTenants(t *testing.T) {
+	ctx := context.Background()
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	server, err := miniredis.Run()
+	Require(t, err)
+	redisService, err := NewRedisStorageService(
+		RedisConfig{
+			Enable:     true,
+			Url:        "redis://" + server.Addr(),
+			Expiration: time.Hour,
+			KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+			TenantKeyConfigs: []string{
+				"chain-a=0x1111111111111111111111111111111111111111111111111111111111111111",
+				"chain-b=0x2222222222222222222222222222222222222222222222222222222222222222",
+			},
+		}, NewMemoryBackedStorageService(ctx))
+	Require(t, err)
+	rs := redisService.(*RedisStorageService)
+	ctxA := WithRedisTenant(ctx, "chain-a")
+	ctxB := WithRedisTenant(ctx, "chain-b")
+
+	value := []byte("batch of chain b")
+	key := dastree.Hash(value)
+	Require(t, redisService.Put(ctxB, value, timeout))
+	data, err := rs.getVerifiedData(ctxB, key)
+	Require(t, err)
+	if !bytes.Equal(data, value) {
+		Fail(t, "tenant read back wrong data", string(data))
+	}
+	// Other tenants don't see the entry of chain-b.
+	for _, otherCtx := range []context.Context{ctxA, ctx} {
+		if _, err := rs.getVerifiedData(otherCtx, key); err == nil {
+			Fail(t, "entry of tenant chain-b was read by another tenant")
+		}
+	}
+
+	// Tenants caching the same hash don't overwrite each other.
+	Require(t, rs.set(ctxA, key, []byte("cached by chain-a")))
+	data, err = rs.getVerifiedData(ctxB, key)
+	Require(t, err)
+	if !bytes.Equal(data, value) {
+		Fail(t, "entry of tenant chain-b was overwritten by chain-a", string(data))
+	}
+
+	// An entry forged with the key of chain-a isn't served to chain-b.
+	forged := []byte("forged batch")
+	Require(t, server.Set(redisEntryKey("chain-b", key), string(signMessage(rs.tenantKeys["chain-a"], forged))))
+	data, err = redisService.GetByHash(ctxB, key)
+	Require(t, err)
+	if !bytes.Equal(data, value) {
+		Fail(t, "tenant chain-b was served an entry of chain-a", string(data))
+	}
+
+	// Pins are kept per tenant.
+	Require(t, rs.Pin(ctxA, key))
+	if ttl := server.TTL(redisEntryKey("chain-a", key)); ttl != 0 {
+		Fail(t, "expected the entry pinned by chain-a to be kept without expiration, got TTL", ttl)
+	}
+	Require(t, rs.set(ctxB, key, value))
+	if ttl := server.TTL(redisEntryKey("chain-b", key)); ttl == 0 {
+		Fail(t, "entry of tenant chain-b was pinned by chain-a")
+	}
+	for _, tenant := range []string{"", "chain-a"} {
+		parsedTenant, hash, ok := parseRedisEntryKey(redisEntryKey(tenant, key))
+		if !ok || parsedTenant != tenant || hash != key {
+			Fail(t, "entry key of tenant", tenant, "parsed as tenant", parsedTenant, "hash", hash)
+		}
+		if isRedisEntryKey(redisPinnedSetKeyFor(tenant)) {
+			Fail(t, "pinned set key of tenant", tenant, "taken for an entry key")
+		}
+	}
+
+	if _, err := redisService.GetByHash(WithRedisTenant(ctx, "chain-c"), key); !errors.Is(err, ErrUnknownRedisTenant) {
+		Fail(t, "expected request of an unknown tenant to fail, got", err)
+	}
+
+	for _, invalid := range [][]string{{"chain-a"}, {"=0x1111111111111111111111111111111111111111111111111111111111111111"}, {"chain-a=0x11"}, {"chain:a=0x1111111111111111111111111111111111111111111111111111111111111111"}} {
+		config := DefaultRedisConfig
+		config.Enable = true
+		config.Url = "redis://" + server.Addr()
+		config.KeyConfig = "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9"
+		config.TenantKeyConfigs = invalid
+		if err := config.Validate(); err == nil {
+			Fail(t, "expected tenant key configs", invalid, "to be rejected")
+		}
+	}
+}
+
+func TestRedisStorageServiceStringRedactsSecrets(t *testing.T) {
+	ctx := context.Background()
+	server, err := miniredis.Run()
+	Require(t, err)
+	defer server.Close()
+	keyConfig := "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9"
+	tenantKey := "1111111111111111111111111111111111111111111111111111111111111111"
+	redisService, err := NewRedisStorageService(RedisConfig{
+		Enable:           true,
+		Url:              "redis://user:hunter2@" + server.Addr(),
+		Expiration:       time.Hour,
+		KeyConfig:        keyConfig,
+		TenantKeyConfigs: []string{"chain-a=" + tenantKey},
+	}, NewMemoryBackedStorageService(ctx))
+	Require(t, err)
+	str := redisService.String()
+	for _, secret := range []string{keyConfig, tenantKey, "hunter2"} {
+		if strings.Contains(str, secret) {
+			Fail(t, "String of the Redis storage service leaks a secret:", str)
+		}
+	}
+	if !strings.Contains(str, "chain-a") {
+		Fail(t, "expected the tenants to be listed, got", str)
+	}
+}
+
+func TestRedisStorageServiceCredentialsProvider(t *testing.T) {
+	ctx := context.Background()
+	server, err := miniredis.Run()
+	Require(t, err)
+	var mutex sync.Mutex
+	tokens := 0
+	var lastToken string
+	provider := func(ctx context.Context) (string, string, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		tokens++
+		lastToken = "token-" + strconv.Itoa(tokens)
+		return "", lastToken, nil
+	}
+	server.RequireAuth("token-1")
+	redisService, err := NewRedisStorageService(RedisConfig{
+		Enable:              true,
+		Url:                 "redis://" + server.Addr(),
+		Expiration:          time.Hour,
+		KeyConfig:           "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+		CredentialsProvider: provider,
+	}, NewMemoryBackedStorageService(ctx))
+	Require(t, err)
+	rs := redisService.(*RedisStorageService)
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	val := []byte("cached with the first token")
+	Require(t, rs.Put(ctx, val, timeout))
+	if !server.Exists(string(dastree.Hash(val).Bytes())) {
+		Fail(t, "expected the value to be cached when authenticated with the first token")
+	}
+
+	// The token rotates and the server drops its connections, so the client must reconnect with it.
+	server.Close()
+	server.RequireAuth("token-2")
+	Require(t, server.Restart())
+	val = []byte("cached after the token rotated")
+	Require(t, rs.Put(ctx, val, timeout))
+	if !server.Exists(string(dastree.Hash(val).Bytes())) {
+		Fail(t, "expected the value to be cached after reconnecting with the rotated token")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if lastToken != "token-2" {
+		Fail(t, "expected the reconnect to use the latest token, last provided", lastToken)
+	}
+}
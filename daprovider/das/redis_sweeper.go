@@ -0,0 +1,192 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+type RedisSweepConfig struct {
+	Enable     bool          `koanf:"enable"`
+	Interval   time.Duration `koanf:"interval"`
+	SampleSize int64         `koanf:"sample-size"`
+}
+
+var DefaultRedisSweepConfig = RedisSweepConfig{
+	Enable:     false,
+	Interval:   time.Minute,
+	SampleSize: 100,
+}
+
+func RedisSweepConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultRedisSweepConfig.Enable, "enable periodically re-verifying the signatures of cached values, repairing corrupt ones from the base storage")
+	f.Duration(prefix+".interval", DefaultRedisSweepConfig.Interval, "interval between sweeps of cached values")
+	f.Int64(prefix+".sample-size", DefaultRedisSweepConfig.SampleSize, "approximate number of keys scanned by each sweep, bounding its load on Redis")
+}
+
+// RedisSweeper periodically re-verifies the HMACs of the values cached by a RedisStorageService, as
+// values are otherwise only verified when read, so corruption of cold values would go unnoticed until
+// they're needed. Each sweep scans the next sample of keys, resuming where the last one stopped and
+// starting over once the whole database has been scanned. Corrupt values are deleted and cached again
+// from the base storage. As the database may be shared with other nodes, or hold the entries of
+// tenants this node has no key for, only values this node can tell are corrupt are deleted; see check.
+type RedisSweeper struct {
+	stopwaiter.StopWaiter
+	rs     *RedisStorageService
+	config RedisSweepConfig
+	cursor uint64
+
+	checkedCounter  *metrics.Counter
+	corruptCounter  *metrics.Counter
+	repairedCounter *metrics.Counter
+	skippedCounter  *metrics.Counter
+}
+
+// NewRedisSweeper returns a sweeper of the values cached by rs, exporting its metrics within
+// metricsNamespace (see dasutil.MetricName).
+func NewRedisSweeper(rs *RedisStorageService, config RedisSweepConfig, metricsNamespace string) *RedisSweeper {
+	return &RedisSweeper{
+		rs:              rs,
+		config:          config,
+		checkedCounter:  metrics.GetOrRegisterCounter(dasutil.MetricName(metricsNamespace, "redis/sweep/checked"), nil),
+		corruptCounter:  metrics.GetOrRegisterCounter(dasutil.MetricName(metricsNamespace, "redis/sweep/corrupt"), nil),
+		repairedCounter: metrics.GetOrRegisterCounter(dasutil.MetricName(metricsNamespace, "redis/sweep/repaired"), nil),
+		skippedCounter:  metrics.GetOrRegisterCounter(dasutil.MetricName(metricsNamespace, "redis/sweep/skipped"), nil),
+	}
+}
+
+func (s *RedisSweeper) Start(ctx context.Context) {
+	s.StopWaiter.Start(ctx, s)
+	s.CallIteratively(func(ctx context.Context) time.Duration {
+		if _, _, err := s.sweep(ctx); err != nil {
+			log.Warn("Failed to sweep Redis cache", "err", err)
+		}
+		return s.config.Interval
+	})
+}
+
+// sweep verifies the values of the next sample of keys, returning the numbers of values checked and
+// of corrupt values found.
+func (s *RedisSweeper) sweep(ctx context.Context) (uint64, uint64, error) {
+	scanCtx, cancel := ctxWithTimeout(ctx, s.rs.redisConfig.GetTimeout)
+	keys, nextCursor, err := s.rs.client.Scan(scanCtx, s.cursor, "", s.config.SampleSize).Result()
+	cancel()
+	if err != nil {
+		return 0, 0, err
+	}
+	var entryKeys []string
+	for _, key := range keys {
+		if isRedisEntryKey(key) {
+			entryKeys = append(entryKeys, key)
+		}
+	}
+	values, err := s.rs.getBatch(ctx, entryKeys)
+	if err != nil {
+		return 0, 0, err
+	}
+	var checked, corrupt, skipped uint64
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			// The value expired since the scan.
+			continue
+		}
+		tenant, key, _ := parseRedisEntryKey(entryKeys[i])
+		switch s.check(tenant, key, []byte(str)) {
+		case redisEntryValid:
+			checked++
+		case redisEntryCorrupt:
+			checked++
+			corrupt++
+			s.repair(WithRedisTenant(ctx, tenant), entryKeys[i], key)
+		case redisEntryForeign:
+			skipped++
+		}
+	}
+	s.checkedCounter.Inc(int64(checked))
+	s.corruptCounter.Inc(int64(corrupt))
+	s.skippedCounter.Inc(int64(skipped))
+	s.cursor = nextCursor
+	return checked, corrupt, nil
+}
+
+type redisEntryState int
+
+const (
+	redisEntryValid redisEntryState = iota
+	redisEntryCorrupt
+	// redisEntryForeign entries may be valid entries signed with a key this node doesn't have.
+	redisEntryForeign
+)
+
+// check returns the state of the cached value of the tenant's entry for key. Entries of tenants this
+// node has no key for are foreign. A value whose HMAC doesn't verify with the tenant's key is foreign
+// if its content is intact, as another node sharing the database signed it with its own key, and is
+// otherwise corrupt. The content of sealed values can only be checked with this node's encryption key,
+// so nodes sharing a database must seal values with the same key, if any.
+func (s *RedisSweeper) check(tenant string, key common.Hash, data []byte) redisEntryState {
+	signingKey, err := s.rs.signingKeyFor(WithRedisTenant(context.Background(), tenant))
+	if err != nil {
+		return redisEntryForeign
+	}
+	if _, err := verifyMessageSignature(signingKey, data); err == nil {
+		return redisEntryValid
+	}
+	if len(data) < common.HashLength {
+		return redisEntryCorrupt
+	}
+	message := data[:len(data)-common.HashLength]
+	if s.rs.sealer != nil {
+		if _, err := s.rs.sealer.open(key, message); err == nil {
+			return redisEntryForeign
+		}
+	} else if dastree.ValidHash(key, message) {
+		return redisEntryForeign
+	}
+	return redisEntryCorrupt
+}
+
+// repair deletes the corrupt value at entryKey and caches it again from the base storage, if it's
+// there, for the tenant of the context.
+func (s *RedisSweeper) repair(ctx context.Context, entryKey string, key common.Hash) {
+	log.Warn("Found corrupt value in Redis cache", "key", key, "tenant", redisTenantFromContext(ctx))
+	delCtx, cancel := ctxWithTimeout(ctx, s.rs.redisConfig.PutTimeout)
+	err := s.rs.client.Del(delCtx, entryKey).Err()
+	cancel()
+	if err != nil {
+		log.Warn("Failed to delete corrupt value from Redis cache", "key", key, "err", err)
+		return
+	}
+	value, err := s.rs.baseStorageService.GetByHash(ctx, key)
+	if err != nil {
+		log.Warn("Couldn't get corrupt Redis value from base storage, leaving it uncached", "key", key, "err", err)
+		return
+	}
+	if err := s.rs.set(ctx, key, value); err != nil {
+		log.Warn("Failed to cache repaired value in Redis", "key", key, "err", err)
+		return
+	}
+	s.repairedCounter.Inc(1)
+}
+
+func (s *RedisSweeper) Close(ctx context.Context) error {
+	s.StopAndWait()
+	return nil
+}
+
+func (s *RedisSweeper) String() string {
+	return fmt.Sprintf("RedisSweeper(%+v)", s.config)
+}
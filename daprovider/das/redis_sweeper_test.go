@@ -0,0 +1,85 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+func TestRedisSweeperRepairsCorruptValues(t *testing.T) {
+	ctx := context.Background()
+	server, err := miniredis.Run()
+	Require(t, err)
+	defer server.Close()
+	base := NewMemoryBackedStorageService(ctx)
+	redisService, err := NewRedisStorageService(RedisConfig{
+		Enable:     true,
+		Url:        "redis://" + server.Addr(),
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+	}, base)
+	Require(t, err)
+	rs := redisService.(*RedisStorageService)
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+
+	intact := []byte("a value left alone")
+	corrupted := []byte("a value corrupted in the cache")
+	Require(t, rs.Put(ctx, intact, timeout))
+	Require(t, rs.Put(ctx, corrupted, timeout))
+	corruptedKey := dastree.Hash(corrupted)
+	Require(t, server.Set(string(corruptedKey.Bytes()), string(bytes.Repeat([]byte{0xab}, 64))))
+	// A corrupt value missing from the base storage can only be removed.
+	uncached := dastree.Hash([]byte("a value only in the cache"))
+	Require(t, server.Set(string(uncached.Bytes()), "garbage"))
+	// Entries of other nodes sharing the database, signed with their keys, and of tenants this node
+	// has no key for are left alone.
+	foreign := []byte("a value cached by another node")
+	foreignKey := dastree.Hash(foreign)
+	foreignValue := string(signMessage(common.HexToHash("0x1234"), foreign))
+	Require(t, server.Set(string(foreignKey.Bytes()), foreignValue))
+	unknownTenantEntry := redisEntryKey("unknown", dastree.Hash([]byte("a value of another tenant")))
+	Require(t, server.Set(unknownTenantEntry, "garbage of another tenant"))
+
+	sweeper := NewRedisSweeper(rs, RedisSweepConfig{Enable: true, Interval: time.Hour, SampleSize: 100}, "sweeper_test")
+	corruptBefore := sweeper.corruptCounter.Snapshot().Count()
+	checked, corrupt, err := sweeper.sweep(ctx)
+	Require(t, err)
+	if checked != 3 || corrupt != 2 {
+		Fail(t, "expected the sweep to find 2 corrupt values among 3, got", corrupt, "among", checked)
+	}
+	if found := sweeper.corruptCounter.Snapshot().Count() - corruptBefore; found != 2 {
+		Fail(t, "expected the corrupt values to be counted, got", found)
+	}
+	if value, err := server.Get(string(foreignKey.Bytes())); err != nil || value != foreignValue {
+		Fail(t, "expected the value signed by another node to be left alone, got", value, err)
+	}
+	if !server.Exists(unknownTenantEntry) {
+		Fail(t, "expected the entry of a tenant without a key to be left alone")
+	}
+
+	repaired, err := rs.getVerifiedData(ctx, corruptedKey)
+	Require(t, err)
+	if !bytes.Equal(repaired, corrupted) {
+		Fail(t, "expected the corrupt value to be cached again from the base storage, got", repaired)
+	}
+	if server.Exists(string(uncached.Bytes())) {
+		Fail(t, "expected the corrupt value missing from the base storage to be deleted")
+	}
+
+	_, corrupt, err = sweeper.sweep(ctx)
+	Require(t, err)
+	if corrupt != 0 {
+		Fail(t, "expected no corrupt values after the repair, got", corrupt)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Exports of the Redis cache are a sequence of entries, each made of the 32 byte key, the 8 byte
+// big-endian length of the value, the value itself and the keccak hash of the key and value. Values
+// are exported without their HMAC, so that they can be imported into a cache with another key. Values
+// of caches encrypting them are exported encrypted, so they can only be imported into a cache with
+// the same encryption key.
+
+// redisTransferBatchSize is the number of entries ExportTo and ImportFrom hold in memory at once.
+const redisTransferBatchSize = 1000
+
+// redisImportMaxBatchBytes bounds the size of the values ImportFrom holds in memory at once, which
+// may be reached before redisTransferBatchSize entries for large values.
+const redisImportMaxBatchBytes = 64 << 20
+
+var ErrCorruptRedisExportEntry = errors.New("corrupt entry in Redis export")
+
+type RedisImportOptions struct {
+	// SkipCorrupt logs and skips entries whose checksum doesn't match, instead of failing the import.
+	// Exports that can't be parsed any further, such as truncated ones, always fail the import.
+	SkipCorrupt bool
+	// Progress, if set, is called with the total numbers of entries imported and skipped so far after
+	// each batch is written.
+	Progress func(imported uint64, skipped uint64)
+}
+
+func redisExportChecksum(key common.Hash, value []byte) common.Hash {
+	return crypto.Keccak256Hash(key.Bytes(), value)
+}
+
+// ExportTo writes the values cached in Redis to w, scanning the database in batches so that large
+// caches are never held in memory, and returns the number of entries written. Only the entries of the
+// context's tenant are exported, and those whose HMAC doesn't verify with its key are skipped. As
+// Redis scans may return a key more than once, so may exports. Pins
+// and expirations aren't exported. If set, progress is called with the number of entries written so
+// far after each batch.
+func (rs *RedisStorageService) ExportTo(ctx context.Context, w io.Writer, progress func(exported uint64)) (uint64, error) {
+	signingKey, err := rs.signingKeyFor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriter(w)
+	var exported, cursor uint64
+	for {
+		keys, hashes, nextCursor, err := rs.scanEntryKeys(ctx, cursor)
+		if err != nil {
+			return exported, err
+		}
+		values, err := rs.getBatch(ctx, keys)
+		if err != nil {
+			return exported, err
+		}
+		for i, value := range values {
+			str, ok := value.(string)
+			if !ok {
+				// The value expired since the scan.
+				continue
+			}
+			message, err := verifyMessageSignature(signingKey, []byte(str))
+			if err != nil {
+				log.Warn("Not exporting Redis value with invalid signature", "key", hashes[i], "err", err)
+				continue
+			}
+			if err := writeRedisExportEntry(bw, hashes[i], message); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+		if progress != nil {
+			progress(exported)
+		}
+		if nextCursor == 0 {
+			return exported, bw.Flush()
+		}
+		cursor = nextCursor
+	}
+}
+
+// scanEntryKeys returns the keys of the next batch of entries of the context's tenant, along with
+// their hashes.
+func (rs *RedisStorageService) scanEntryKeys(ctx context.Context, cursor uint64) ([]string, []common.Hash, uint64, error) {
+	tenant := redisTenantFromContext(ctx)
+	ctx, cancel := ctxWithTimeout(ctx, rs.redisConfig.GetTimeout)
+	defer cancel()
+	keys, nextCursor, err := rs.client.Scan(ctx, cursor, "", redisTransferBatchSize).Result()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	var entryKeys []string
+	var hashes []common.Hash
+	for _, key := range keys {
+		if keyTenant, hash, ok := parseRedisEntryKey(key); ok && keyTenant == tenant {
+			entryKeys = append(entryKeys, key)
+			hashes = append(hashes, hash)
+		}
+	}
+	return entryKeys, hashes, nextCursor, nil
+}
+
+func (rs *RedisStorageService) getBatch(ctx context.Context, keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := ctxWithTimeout(ctx, rs.redisConfig.GetTimeout)
+	defer cancel()
+	return rs.client.MGet(ctx, keys...).Result()
+}
+
+func writeRedisExportEntry(w io.Writer, key common.Hash, value []byte) error {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(value)))
+	checksum := redisExportChecksum(key, value)
+	for _, part := range [][]byte{key.Bytes(), length[:], value, checksum.Bytes()} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRedisExportEntry reads the next entry of an export, returning io.EOF at its end. Entries with
+// a mismatching checksum are returned along with an ErrCorruptRedisExportEntry error.
+func readRedisExportEntry(r io.Reader) (common.Hash, []byte, error) {
+	var header [common.HashLength + 8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return common.Hash{}, nil, fmt.Errorf("truncated Redis export entry header: %w", err)
+		}
+		return common.Hash{}, nil, err
+	}
+	key := common.BytesToHash(header[:common.HashLength])
+	length := binary.BigEndian.Uint64(header[common.HashLength:])
+	if length > redisMaxValueSize {
+		return common.Hash{}, nil, fmt.Errorf("length %d of Redis export entry %v exceeds the maximum Redis value size", length, key)
+	}
+	value := make([]byte, length)
+	var checksum common.Hash
+	for _, buf := range [][]byte{value, checksum[:]} {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			// The export ended in the middle of the entry.
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return common.Hash{}, nil, fmt.Errorf("truncated Redis export entry %v: %w", key, err)
+		}
+	}
+	if checksum != redisExportChecksum(key, value) {
+		return key, value, fmt.Errorf("%w: checksum mismatch for key %v", ErrCorruptRedisExportEntry, key)
+	}
+	return key, value, nil
+}
+
+type redisImportEntry struct {
+	key   common.Hash
+	value []byte
+}
+
+// ImportFrom caches the entries of an export written by ExportTo in Redis as entries of the context's
+// tenant, signing them with its key and giving them the configured expiration. Entries are read and written in batches,
+// so that large exports are never held in memory. The base storage isn't written to. It returns the
+// numbers of entries imported and of corrupt entries skipped.
+func (rs *RedisStorageService) ImportFrom(ctx context.Context, r io.Reader, opts RedisImportOptions) (uint64, uint64, error) {
+	br := bufio.NewReader(r)
+	var imported, skipped uint64
+	var batch []redisImportEntry
+	batchBytes := 0
+	flush := func() error {
+		if err := rs.setBatch(ctx, batch); err != nil {
+			return err
+		}
+		imported += uint64(len(batch))
+		batch = batch[:0]
+		batchBytes = 0
+		if opts.Progress != nil {
+			opts.Progress(imported, skipped)
+		}
+		return nil
+	}
+	for {
+		key, value, err := readRedisExportEntry(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, ErrCorruptRedisExportEntry) && opts.SkipCorrupt {
+			log.Warn("Skipping corrupt entry of Redis import", "key", key, "err", err)
+			skipped++
+			continue
+		}
+		if err != nil {
+			return imported, skipped, err
+		}
+		batch = append(batch, redisImportEntry{key: key, value: value})
+		batchBytes += len(value)
+		if len(batch) >= redisTransferBatchSize || batchBytes >= redisImportMaxBatchBytes {
+			if err := flush(); err != nil {
+				return imported, skipped, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return imported, skipped, err
+	}
+	return imported, skipped, nil
+}
+
+func (rs *RedisStorageService) setBatch(ctx context.Context, batch []redisImportEntry) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	signingKey, err := rs.signingKeyFor(ctx)
+	if err != nil {
+		return err
+	}
+	tenant := redisTenantFromContext(ctx)
+	ctx, cancel := ctxWithTimeout(ctx, rs.redisConfig.PutTimeout)
+	defer cancel()
+	_, err = rs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, entry := range batch {
+			pipe.Set(ctx, redisEntryKey(tenant, entry.key), signMessage(signingKey, entry.value), rs.redisConfig.Expiration)
+		}
+		return nil
+	})
+	return err
+}
@@ -6,6 +6,7 @@ package das
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -19,13 +20,14 @@ import (
 // The implementation assumes that there won't be a large number of replicas.
 
 type RedundantStorageService struct {
-	innerServices []StorageService
+	innerServices               []StorageService
+	expirationPolicyAggregation ExpirationPolicyAggregation
 }
 
-func NewRedundantStorageService(ctx context.Context, services []StorageService) (StorageService, error) {
+func NewRedundantStorageService(ctx context.Context, services []StorageService, expirationPolicyAggregation ExpirationPolicyAggregation) (StorageService, error) {
 	innerServices := make([]StorageService, len(services))
 	copy(innerServices, services)
-	return &RedundantStorageService{innerServices}, nil
+	return &RedundantStorageService{innerServices, expirationPolicyAggregation}, nil
 }
 
 type readResponse struct {
@@ -82,6 +84,27 @@ func (r *RedundantStorageService) Put(ctx context.Context, data []byte, expirati
 	return anyError
 }
 
+// ValidatePut checks the value would be accepted by every inner service, as Put fails if any of them fails.
+func (r *RedundantStorageService) ValidatePut(ctx context.Context, data []byte) error {
+	var errs []error
+	for _, s := range r.innerServices {
+		if err := s.ValidatePut(ctx, data); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", s, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *RedundantStorageService) Refresh(ctx context.Context, key common.Hash, expirationTime uint64) error {
+	var errs []error
+	for _, s := range r.innerServices {
+		if err := s.Refresh(ctx, key, expirationTime); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", s, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (r *RedundantStorageService) Sync(ctx context.Context) error {
 	var wg sync.WaitGroup
 	var errorMutex sync.Mutex
@@ -122,38 +145,19 @@ func (r *RedundantStorageService) Close(ctx context.Context) error {
 	return anyError
 }
 
+// ExpirationPolicy aggregates the policies of the inner services according to the configured
+// ExpirationPolicyAggregation. By default the most durable policy is reported: if at least one inner
+// service keeps data forever, the whole redundant service can serve it after the timeout.
 func (r *RedundantStorageService) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
-	// If at least one inner service has KeepForever,
-	// then whole redundant service can serve after timeout.
-
-	// If no inner service has KeepForever,
-	// but at least one inner service has DiscardAfterArchiveTimeout,
-	// then whole redundant service can serve till archive timeout.
-
-	// If no inner service has KeepForever, DiscardAfterArchiveTimeout,
-	// but at least one inner service has DiscardAfterDataTimeout,
-	// then whole redundant service can serve till data timeout.
-	var res dasutil.ExpirationPolicy = -1
+	policies := make([]dasutil.ExpirationPolicy, 0, len(r.innerServices))
 	for _, serv := range r.innerServices {
 		expirationPolicy, err := serv.ExpirationPolicy(ctx)
 		if err != nil {
 			return -1, err
 		}
-		switch expirationPolicy {
-		case dasutil.KeepForever:
-			return dasutil.KeepForever, nil
-		case dasutil.DiscardAfterArchiveTimeout:
-			res = dasutil.DiscardAfterArchiveTimeout
-		case dasutil.DiscardAfterDataTimeout:
-			if res != dasutil.DiscardAfterArchiveTimeout {
-				res = dasutil.DiscardAfterDataTimeout
-			}
-		}
-	}
-	if res == -1 {
-		return -1, errors.New("unknown expiration policy")
+		policies = append(policies, expirationPolicy)
 	}
-	return res, nil
+	return AggregateExpirationPolicies(r.expirationPolicyAggregation, policies...)
 }
 
 func (r *RedundantStorageService) String() string {
@@ -23,7 +23,7 @@ func TestRedundantStorageService(t *testing.T) {
 	for i := 0; i < NumServices; i++ {
 		services = append(services, NewMemoryBackedStorageService(ctx))
 	}
-	redundantService, err := NewRedundantStorageService(ctx, services)
+	redundantService, err := NewRedundantStorageService(ctx, services, AggregateMostDurable)
 	Require(t, err)
 
 	val1 := []byte("The first value")
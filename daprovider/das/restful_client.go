@@ -21,44 +21,79 @@ import (
 
 // RestfulDasClient implements dasutil.DASReader
 type RestfulDasClient struct {
-	url string
+	url    string
+	client *http.Client
 }
 
 func NewRestfulDasClient(protocol string, host string, port int) *RestfulDasClient {
 	return &RestfulDasClient{
-		url: fmt.Sprintf("%s://%s:%d", protocol, host, port),
+		url:    fmt.Sprintf("%s://%s:%d", protocol, host, port),
+		client: http.DefaultClient,
 	}
 }
 
 func NewRestfulDasClientFromURL(url string) (*RestfulDasClient, error) {
+	return NewRestfulDasClientFromURLWithDialer(url, nil)
+}
+
+// NewRestfulDasClientFromURLWithDialer is NewRestfulDasClientFromURL with the client's connections
+// made by dial, e.g. to route them through a proxy. A nil dial connects directly.
+func NewRestfulDasClientFromURLWithDialer(url string, dial DialContextFunc) (*RestfulDasClient, error) {
 	if !(strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")) {
 		return nil, fmt.Errorf("protocol prefix 'http://' or 'https://' must be specified for RestfulDasClient; got '%s'", url)
 
 	}
 	return &RestfulDasClient{
-		url: url,
+		url:    url,
+		client: httpClientWithDialer(dial),
 	}, nil
 }
 
 func (c *RestfulDasClient) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	return c.getByHash(ctx, hash, 0)
+}
+
+// GetByHashWithSizeLimit stops reading the response once it's too large to hold a value of maxSize bytes.
+func (c *RestfulDasClient) GetByHashWithSizeLimit(ctx context.Context, hash common.Hash, maxSize uint64) ([]byte, error) {
+	return c.getByHash(ctx, hash, maxSize)
+}
+
+// restfulResponseOverhead bounds the size of the JSON wrapping the base64 encoded value in a response.
+const restfulResponseOverhead = 1024
+
+func (c *RestfulDasClient) getByHash(ctx context.Context, hash common.Hash, maxSize uint64) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", c.url+getByHashRequestPath+EncodeStorageServiceKey(hash), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: HTTP error with status %d returned by server: %s", ErrNotFound, res.StatusCode, http.StatusText(res.StatusCode))
+	}
 	if res.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("HTTP error with status %d returned by server: %s", res.StatusCode, http.StatusText(res.StatusCode))
 	}
 
-	jsonDecoder := json.NewDecoder(res.Body)
+	var body io.Reader = res.Body
+	var limitedBody *io.LimitedReader
+	if maxSize > 0 {
+		// #nosec G115
+		maxResponseSize := int64(base64.StdEncoding.EncodedLen(int(maxSize))) + restfulResponseOverhead
+		limitedBody = &io.LimitedReader{R: res.Body, N: maxResponseSize + 1}
+		body = limitedBody
+	}
+	jsonDecoder := json.NewDecoder(body)
 	var response RestfulDasServerResponse
 	if err := jsonDecoder.Decode(&response); err != nil {
+		if limitedBody != nil && limitedBody.N == 0 {
+			return nil, fmt.Errorf("%w: response for %v is too large for a value of at most %d bytes", dasutil.ErrPayloadTooLarge, hash, maxSize)
+		}
 		return nil, err
 	}
 
@@ -67,6 +102,9 @@ func (c *RestfulDasClient) GetByHash(ctx context.Context, hash common.Hash) ([]b
 	if err != nil {
 		return nil, err
 	}
+	if maxSize > 0 && uint64(len(decodedBytes)) > maxSize {
+		return nil, fmt.Errorf("%w: got %d bytes, limit is %d", dasutil.ErrPayloadTooLarge, len(decodedBytes), maxSize)
+	}
 	if !dastree.ValidHash(hash, decodedBytes) {
 		return nil, dasutil.ErrHashMismatch
 	}
@@ -75,7 +113,7 @@ func (c *RestfulDasClient) GetByHash(ctx context.Context, hash common.Hash) ([]b
 }
 
 func (c *RestfulDasClient) HealthCheck(ctx context.Context) error {
-	res, err := http.Get(c.url + healthRequestPath)
+	res, err := c.client.Get(c.url + healthRequestPath)
 	if err != nil {
 		return err
 	}
@@ -86,7 +124,7 @@ func (c *RestfulDasClient) HealthCheck(ctx context.Context) error {
 }
 
 func (c *RestfulDasClient) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
-	res, err := http.Get(c.url + expirationPolicyRequestPath)
+	res, err := c.client.Get(c.url + expirationPolicyRequestPath)
 	if err != nil {
 		return -1, err
 	}
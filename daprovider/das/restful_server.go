@@ -14,7 +14,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 
@@ -152,19 +151,14 @@ func (rds *RestfulDasServer) GetByHashHandler(w http.ResponseWriter, r *http.Req
 		restGetByHashDurationHistogram.Update(time.Since(start).Nanoseconds())
 	}()
 
-	hashBytes, err := DecodeStorageServiceKey(strings.TrimPrefix(requestPath, "/get-by-hash/"))
+	hash, err := ParseHashKey(strings.TrimPrefix(requestPath, "/get-by-hash/"))
 	if err != nil {
 		log.Warn("Failed to decode hex-encoded hash", "path", requestPath, "err", err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	if len(hashBytes) < 32 {
-		log.Warn("Decoded hash was too short", "path", requestPath, "len(hashBytes)", len(hashBytes))
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
 
-	responseData, err := rds.daReader.GetByHash(r.Context(), common.BytesToHash(hashBytes[:32]))
+	responseData, err := rds.daReader.GetByHash(r.Context(), hash)
 	if err != nil {
 		log.Warn("Unable to find data", "path", requestPath, "err", err, "remoteAddr", r.RemoteAddr)
 		w.WriteHeader(http.StatusNotFound)
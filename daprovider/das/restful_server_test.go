@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
@@ -65,6 +66,19 @@ func TestRestfulClientServer(t *testing.T) {
 	if err == nil || !strings.Contains(err.Error(), "404") {
 		Fail(t, "Expected a 404 error")
 	}
+	if !errors.Is(err, ErrNotFound) {
+		Fail(t, "Expected a 404 error to be reported as ErrNotFound, got", err)
+	}
+
+	for _, key := range []string{"0x1234", strings.Repeat("zz", 32)} {
+		// #nosec G107
+		res, err := http.Get(fmt.Sprintf("http://%s:%d/get-by-hash/%s", LocalServerAddressForTest, port, key))
+		Require(t, err)
+		res.Body.Close()
+		if res.StatusCode != http.StatusBadRequest {
+			Fail(t, "expected malformed key", key, "to be a bad request, got", res.StatusCode)
+		}
+	}
 
 	err = server.Shutdown()
 	Require(t, err)
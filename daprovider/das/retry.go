@@ -0,0 +1,37 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+// permanentErrors won't go away by retrying the same request: the data is missing, doesn't
+// match its hash, can't be stored, or the caller has given up.
+var permanentErrors = []error{
+	ErrNotFound,
+	ErrValueTooLarge,
+	ErrContentHashMismatch,
+	dasutil.ErrHashMismatch,
+	dasutil.ErrPayloadTooLarge,
+	context.Canceled,
+}
+
+// IsTransient returns whether a DAS operation that failed with err is worth retrying.
+// Deadlines, connection failures and any other errors not known to be permanent are transient,
+// so that classification never stops a retry that would have happened without it.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, permanent := range permanentErrors {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+	return true
+}
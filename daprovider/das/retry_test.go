@@ -0,0 +1,45 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+func TestIsTransient(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	for _, tc := range []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"nil", nil, false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"wrapped deadline", fmt.Errorf("storing batch: %w", context.DeadlineExceeded), true},
+		{"connection refused", dialErr, true},
+		{"connection reset", fmt.Errorf("reading response: %w", syscall.ECONNRESET), true},
+		{"truncated response", io.ErrUnexpectedEOF, true},
+		{"unknown", errors.New("backend returned status 503"), true},
+		{"canceled", context.Canceled, false},
+		{"not found", fmt.Errorf("%w: key 0x1234", ErrNotFound), false},
+		{"hash mismatch", fmt.Errorf("reader returned bad data: %w", dasutil.ErrHashMismatch), false},
+		{"content hash mismatch", ErrContentHashMismatch, false},
+		{"payload too large", dasutil.ErrPayloadTooLarge, false},
+		{"value too large", ErrValueTooLarge, false},
+		{"joined", errors.Join(syscall.ECONNRESET, ErrNotFound), false},
+	} {
+		if IsTransient(tc.err) != tc.transient {
+			Fail(t, tc.name, "error", tc.err, "classified as transient:", !tc.transient)
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
 	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/util/metricsutil"
@@ -132,6 +133,12 @@ func ParseServices(config AggregatorConfig, signer signature.DataSignerFunc) ([]
 }
 
 func KeysetHashFromServices(services []ServiceDetails, assumedHonest uint64) ([32]byte, []byte, error) {
+	return KeysetHashFromServicesWithMaxSize(services, assumedHonest, dastree.BinSize)
+}
+
+// KeysetHashFromServicesWithMaxSize is KeysetHashFromServices, limiting the serialized keyset to
+// maxKeysetSize bytes as described by DataAvailabilityKeyset.HashWithMaxSize.
+func KeysetHashFromServicesWithMaxSize(services []ServiceDetails, assumedHonest uint64, maxKeysetSize int) ([32]byte, []byte, error) {
 	var aggSignersMask uint64
 	pubKeys := []blsSignatures.PublicKey{}
 	for _, d := range services {
@@ -153,7 +160,7 @@ func KeysetHashFromServices(services []ServiceDetails, assumedHonest uint64) ([3
 	if err := keyset.Serialize(ksBuf); err != nil {
 		return [32]byte{}, nil, err
 	}
-	keysetHash, err := keyset.Hash()
+	keysetHash, err := keyset.HashWithMaxSize(maxKeysetSize)
 	if err != nil {
 		return [32]byte{}, nil, err
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	flag "github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -76,6 +77,10 @@ func (s3s *S3StorageService) GetByHash(ctx context.Context, key common.Hash) ([]
 		Bucket: aws.String(s3s.bucket),
 		Key:    aws.String(s3s.objectPrefix + EncodeStorageServiceKey(key)),
 	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
 	return buf.Bytes(), err
 }
 
@@ -92,6 +97,17 @@ func (s3s *S3StorageService) Put(ctx context.Context, value []byte, _ uint64) er
 	return err
 }
 
+// s3MaxObjectSize is the maximum size of an S3 object uploaded in parts.
+const s3MaxObjectSize = 5 << 40
+
+func (s3s *S3StorageService) ValidatePut(ctx context.Context, value []byte) error {
+	return checkPutSize(len(value), s3MaxObjectSize)
+}
+
+func (s3s *S3StorageService) Refresh(ctx context.Context, key common.Hash, timeout uint64) error {
+	return refreshByRestoring(ctx, s3s, key, timeout)
+}
+
 func (s3s *S3StorageService) Sync(ctx context.Context) error {
 	return nil
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/daprovider/das/dastree"
@@ -43,6 +44,10 @@ func (m *mockS3FullClient) Download(ctx context.Context, w io.WriterAt, input *s
 		return 0, err
 	}
 	res, err := m.mockStorageService.GetByHash(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		// S3 reports absent objects as NoSuchKey
+		return 0, &types.NoSuchKey{}
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -13,11 +13,11 @@ import (
 
 	flag "github.com/spf13/pflag"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/offchainlabs/nitro/blsSignatures"
-	"github.com/offchainlabs/nitro/daprovider/das/dastree"
 	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
 	"github.com/offchainlabs/nitro/util/pretty"
 )
@@ -105,17 +105,27 @@ func NewSignAfterStoreDASWriter(ctx context.Context, config DataAvailabilityConf
 	}, nil
 }
 
-func (d *SignAfterStoreDASWriter) Store(ctx context.Context, message []byte, timeout uint64) (c *dasutil.DataAvailabilityCertificate, err error) {
+func (d *SignAfterStoreDASWriter) Store(ctx context.Context, message []byte, timeout uint64) (*dasutil.DataAvailabilityCertificate, error) {
+	return d.StoreWithVersion(ctx, message, timeout, dasutil.DefaultCertVersion)
+}
+
+func (d *SignAfterStoreDASWriter) StoreWithVersion(ctx context.Context, message []byte, timeout uint64, version uint8) (c *dasutil.DataAvailabilityCertificate, err error) {
 	// #nosec G115
-	log.Trace("das.SignAfterStoreDASWriter.Store", "message", pretty.FirstFewBytes(message), "timeout", time.Unix(int64(timeout), 0), "this", d)
+	log.Trace("das.SignAfterStoreDASWriter.Store", "message", pretty.FirstFewBytes(message), "timeout", time.Unix(int64(timeout), 0), "version", version, "this", d)
+	if err := dasutil.ValidateWritableCertVersion(version); err != nil {
+		return nil, err
+	}
 	c = &dasutil.DataAvailabilityCertificate{
 		Timeout:     timeout,
-		DataHash:    dastree.Hash(message),
-		Version:     1,
+		DataHash:    dasutil.CertDataHash(message, version),
+		Version:     version,
 		SignersMask: 1, // The aggregator will override this if we're part of a committee.
 	}
 
-	fields := c.SerializeSignableFields()
+	fields, err := c.SerializeSignableFields()
+	if err != nil {
+		return nil, err
+	}
 	c.Sig, err = blsSignatures.SignMessage(d.privKey, fields)
 	if err != nil {
 		return nil, err
@@ -135,6 +145,19 @@ func (d *SignAfterStoreDASWriter) Store(ctx context.Context, message []byte, tim
 	return c, nil
 }
 
+// GetKeysetByHash serves the single-key keyset of the writer, so that certificates it signs can be
+// verified without fetching the keyset from the parent chain.
+func (d *SignAfterStoreDASWriter) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	if hash != d.keysetHash {
+		return nil, fmt.Errorf("%w: keyset %v", ErrNotFound, hash)
+	}
+	return d.keysetBytes, nil
+}
+
+func (d *SignAfterStoreDASWriter) AvailableKeysets(ctx context.Context) ([]common.Hash, error) {
+	return []common.Hash{d.keysetHash}, nil
+}
+
 func (d *SignAfterStoreDASWriter) String() string {
 	return fmt.Sprintf("SignAfterStoreDASWriter{%v}", hexutil.Encode(blsSignatures.PublicKeyToBytes(*d.pubKey)))
 }
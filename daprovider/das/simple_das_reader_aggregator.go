@@ -37,6 +37,7 @@ type RestfulClientAggregatorConfig struct {
 	MaxPerEndpointStats          int                                `koanf:"max-per-endpoint-stats"`
 	SimpleExploreExploitStrategy SimpleExploreExploitStrategyConfig `koanf:"simple-explore-exploit-strategy"`
 	SyncToStorage                SyncToStorageConfig                `koanf:"sync-to-storage"`
+	ProxyUrl                     string                             `koanf:"proxy-url"`
 }
 
 var DefaultRestfulClientAggregatorConfig = RestfulClientAggregatorConfig{
@@ -49,6 +50,7 @@ var DefaultRestfulClientAggregatorConfig = RestfulClientAggregatorConfig{
 	MaxPerEndpointStats:          20,
 	SimpleExploreExploitStrategy: DefaultSimpleExploreExploitStrategyConfig,
 	SyncToStorage:                DefaultSyncToStorageConfig,
+	ProxyUrl:                     "",
 }
 
 type SimpleExploreExploitStrategyConfig struct {
@@ -72,6 +74,7 @@ func RestfulClientAggregatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Int(prefix+".max-per-endpoint-stats", DefaultRestfulClientAggregatorConfig.MaxPerEndpointStats, "number of stats entries (latency and success rate) to keep for each REST endpoint; controls whether strategy is faster or slower to respond to changing conditions")
 	SimpleExploreExploitStrategyConfigAddOptions(prefix+".simple-explore-exploit-strategy", f)
 	SyncToStorageConfigAddOptions(prefix+".sync-to-storage", f)
+	f.String(prefix+".proxy-url", DefaultRestfulClientAggregatorConfig.ProxyUrl, "SOCKS5 proxy to connect to the REST endpoints through, e.g. socks5://localhost:1080; connects directly if empty")
 }
 
 func SimpleExploreExploitStrategyConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -80,8 +83,13 @@ func SimpleExploreExploitStrategyConfigAddOptions(prefix string, f *flag.FlagSet
 }
 
 func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggregatorConfig) (*SimpleDASReaderAggregator, error) {
+	dial, err := NewProxyDialer(config.ProxyUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rest-aggregator.proxy-url: %w", err)
+	}
 	a := SimpleDASReaderAggregator{
 		config: config,
+		dial:   dial,
 		stats:  make(map[dasutil.DASReader]readerStats),
 	}
 
@@ -110,7 +118,7 @@ func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggreg
 	log.Info("REST Aggregator URLs", "urls", urls)
 
 	for _, url := range urls {
-		reader, err := NewRestfulDasClientFromURL(url)
+		reader, err := NewRestfulDasClientFromURLWithDialer(url, dial)
 		if err != nil {
 			return nil, err
 		}
@@ -169,6 +177,7 @@ type SimpleDASReaderAggregator struct {
 	stopwaiter.StopWaiter
 
 	config *RestfulClientAggregatorConfig
+	dial   DialContextFunc
 
 	readersMutex sync.RWMutex
 	// readers and stats are only to be updated by the stats goroutine
@@ -256,7 +265,7 @@ func (a *SimpleDASReaderAggregator) tryGetByHash(
 		if dastree.ValidHash(hash, result) {
 			stat.success = true
 		} else {
-			err = fmt.Errorf("SimpleDASReaderAggregator got result from reader(%v) not matching hash", reader)
+			err = fmt.Errorf("%w: SimpleDASReaderAggregator got result from reader(%v) not matching hash", dasutil.ErrHashMismatch, reader)
 		}
 	}
 	stat.latency = time.Since(start)
@@ -282,7 +291,7 @@ func (a *SimpleDASReaderAggregator) Start(ctx context.Context) {
 		combinedUrls = append(combinedUrls, urls...)
 		combinedReaders := make(map[dasutil.DASReader]bool)
 		for _, url := range combinedUrls {
-			reader, err := NewRestfulDasClientFromURL(url)
+			reader, err := NewRestfulDasClientFromURLWithDialer(url, a.dial)
 			if err != nil {
 				return
 			}
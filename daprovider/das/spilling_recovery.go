@@ -0,0 +1,260 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+// StreamingDASReader is implemented by DASReaders that can write a value as it's read, rather than
+// returning all of it at once.
+type StreamingDASReader interface {
+	// GetByHashTo writes the value of hash to w, returning the number of bytes written. If it fails,
+	// part of the value may have been written.
+	GetByHashTo(ctx context.Context, hash common.Hash, w io.Writer) (int64, error)
+}
+
+type SpillOptions struct {
+	// Threshold, if non-zero, makes RecoverPayloadReader write payloads larger than this many bytes
+	// to a temporary file instead of holding them in memory.
+	Threshold uint64
+	// Dir is the directory of the temporary files, os.TempDir if empty.
+	Dir string
+}
+
+// RecoverPayloadReader is dasutil.RecoverPayloadFromDasBatchWithOptions for payloads that may be too
+// large to hold in memory, returning a reader over the payload along with its size. Payloads larger
+// than spill.Threshold are written to a temporary file in spill.Dir as they're fetched, and their
+// hash is computed as they're written. The file is removed if recovery fails, and otherwise once the
+// reader is closed. Memory is only bounded if the DAS reader implements StreamingDASReader, as other
+// readers return each value whole. Preimages aren't recorded, as they'd hold the payload in memory,
+// and opts.PostProcess isn't applied. The reader is nil if the message is to be ignored.
+//
+// It lives outside of dasutil, which the replay binary imports, as it does file I/O.
+func RecoverPayloadReader(
+	ctx context.Context,
+	batchNum uint64,
+	sequencerMsg []byte,
+	dasReader dasutil.DASReader,
+	keysetFetcher dasutil.DASKeysetFetcher,
+	validateSeqMsg bool,
+	opts dasutil.RecoveryOptions,
+	spill SpillOptions,
+) (io.ReadCloser, uint64, error) {
+	cert, err := dasutil.RecoverCertFromDasBatch(ctx, batchNum, sequencerMsg, keysetFetcher, validateSeqMsg, opts)
+	if cert == nil {
+		return nil, 0, err
+	}
+	r := &spillingRecovery{
+		cert:      cert,
+		dasReader: dasutil.ReaderForCert(dasReader, cert),
+		opts:      opts,
+		buf:       &spillBuffer{threshold: spill.Threshold, dir: spill.Dir, maxSize: opts.MaxPayloadSize},
+	}
+	return r.fetch(ctx)
+}
+
+// spillingRecovery fetches the payload of a verified cert into a spillBuffer.
+type spillingRecovery struct {
+	cert      *dasutil.DataAvailabilityCertificate
+	dasReader dasutil.DASReader
+	opts      dasutil.RecoveryOptions
+	buf       *spillBuffer
+}
+
+func (r *spillingRecovery) fetch(ctx context.Context) (io.ReadCloser, uint64, error) {
+	buf := r.buf
+	var err error
+	if r.cert.Version == dasutil.MultiChunkCertVersion {
+		// The concatenation of the chunks is hashed as they're written, to check it against the
+		// cert's hash of the whole payload.
+		whole := dastree.NewHasher()
+		for i, chunkHash := range r.cert.DataHashes {
+			if err = r.fetchValueTo(ctx, chunkHash, whole); err != nil {
+				err = fmt.Errorf("chunk %d of %d: %w", i, len(r.cert.DataHashes), err)
+				break
+			}
+		}
+		if err == nil && whole.Sum() != r.cert.DataHash {
+			log.Error("preimage mismatch for hash of concatenated chunks", "hash", common.Hash(r.cert.DataHash), "err", dasutil.ErrHashMismatch, "chunks", len(r.cert.DataHashes), "length", buf.size)
+			err = dasutil.ErrHashMismatch
+		}
+	} else {
+		err = r.fetchValueTo(ctx, r.cert.DataHash, nil)
+	}
+	if err != nil {
+		buf.discard()
+		log.Error("Couldn't fetch DAS batch contents", "err", err, "claimedSigners", r.cert.NumClaimedSigners())
+		return nil, 0, err
+	}
+	reader, err := buf.reader()
+	if err != nil {
+		buf.discard()
+		return nil, 0, err
+	}
+	dasutil.RecordRecoveredPayloadSize(r.opts.MetricsNamespace, r.cert.Version, buf.size)
+	return reader, buf.size, nil
+}
+
+// fetchValueTo fetches the value of hash into the buffer, checking it against the hash as it's
+// written, and also writes it to whole if set. A failed fetch of a version 0 value under its dastree
+// hash is rewound and retried under its flat hash, so whole must only be set for other versions.
+func (r *spillingRecovery) fetchValueTo(ctx context.Context, hash common.Hash, whole io.Writer) error {
+	version := r.cert.Version
+	fetch := func(fetchHash common.Hash) (common.Hash, error) {
+		var hasher io.Writer
+		var sum func() common.Hash
+		if version == 0 {
+			keccak := crypto.NewKeccakState()
+			hasher, sum = keccak, func() common.Hash { return common.BytesToHash(keccak.Sum(nil)) }
+		} else {
+			tree := dastree.NewHasher()
+			hasher, sum = tree, tree.Sum
+		}
+		writers := []io.Writer{r.buf, hasher}
+		if whole != nil {
+			writers = append(writers, whole)
+		}
+		start := r.buf.size
+		if err := r.getByHashTo(ctx, fetchHash, io.MultiWriter(writers...)); err != nil {
+			return common.Hash{}, errors.Join(err, r.buf.truncate(start))
+		}
+		return sum(), nil
+	}
+
+	fetchHash := hash
+	if version == 0 {
+		fetchHash = dastree.FlatHashToTreeHash(hash)
+	}
+	got, err := fetch(fetchHash)
+	if err != nil && hash != fetchHash && !errors.Is(err, dasutil.ErrPayloadTooLarge) {
+		log.Debug("error fetching new style hash, trying old", "new", fetchHash, "old", hash, "err", err)
+		got, err = fetch(hash)
+	}
+	if err != nil {
+		return err
+	}
+	if got != hash {
+		log.Error("preimage mismatch for hash", "hash", hash, "err", dasutil.ErrHashMismatch, "version", version)
+		return dasutil.ErrHashMismatch
+	}
+	return nil
+}
+
+// getByHashTo writes the value of hash to w, streaming it if the DAS reader supports it.
+func (r *spillingRecovery) getByHashTo(ctx context.Context, hash common.Hash, w io.Writer) error {
+	if streamingReader, ok := r.dasReader.(StreamingDASReader); ok {
+		_, err := streamingReader.GetByHashTo(ctx, hash, w)
+		return err
+	}
+	var value []byte
+	var err error
+	if limitedReader, ok := r.dasReader.(dasutil.SizeLimitedDASReader); ok && r.opts.MaxPayloadSize != 0 {
+		value, err = limitedReader.GetByHashWithSizeLimit(ctx, hash, r.opts.MaxPayloadSize)
+	} else {
+		value, err = r.dasReader.GetByHash(ctx, hash)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(value)
+	return err
+}
+
+// spillBuffer holds the data written to it in memory until it exceeds the threshold, if non-zero,
+// and then moves it to a temporary file in dir, where the rest is written.
+type spillBuffer struct {
+	threshold uint64
+	dir       string
+	// maxSize, if non-zero, fails writes which would make the data larger with ErrPayloadTooLarge.
+	maxSize uint64
+
+	mem  []byte
+	file *os.File
+	size uint64
+}
+
+func (b *spillBuffer) Write(data []byte) (int, error) {
+	if b.maxSize != 0 && b.size+uint64(len(data)) > b.maxSize {
+		return 0, fmt.Errorf("%w: got more than %d bytes, limit is %d", dasutil.ErrPayloadTooLarge, b.size, b.maxSize)
+	}
+	if b.file == nil && b.threshold != 0 && b.size+uint64(len(data)) > b.threshold {
+		file, err := os.CreateTemp(b.dir, "das-payload-*")
+		if err != nil {
+			return 0, fmt.Errorf("failed to create file to spill DAS payload to: %w", err)
+		}
+		b.file = file
+		if _, err := file.Write(b.mem); err != nil {
+			return 0, err
+		}
+		b.mem = nil
+	}
+	if b.file != nil {
+		n, err := b.file.Write(data)
+		// #nosec G115
+		b.size += uint64(n)
+		return n, err
+	}
+	b.mem = append(b.mem, data...)
+	b.size += uint64(len(data))
+	return len(data), nil
+}
+
+// truncate discards the data written after the first size bytes.
+func (b *spillBuffer) truncate(size uint64) error {
+	b.size = size
+	if b.file == nil {
+		b.mem = b.mem[:size]
+		return nil
+	}
+	// #nosec G115
+	if err := b.file.Truncate(int64(size)); err != nil {
+		return err
+	}
+	// #nosec G115
+	_, err := b.file.Seek(int64(size), io.SeekStart)
+	return err
+}
+
+// discard removes the temporary file, if any.
+func (b *spillBuffer) discard() {
+	if b.file == nil {
+		return
+	}
+	if err := (spillFile{b.file}).Close(); err != nil {
+		log.Warn("Couldn't remove file DAS payload was spilled to", "file", b.file.Name(), "err", err)
+	}
+	b.file = nil
+}
+
+// reader returns a reader over the data, which removes the temporary file, if any, once closed.
+func (b *spillBuffer) reader() (io.ReadCloser, error) {
+	if b.file == nil {
+		return io.NopCloser(bytes.NewReader(b.mem)), nil
+	}
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return spillFile{b.file}, nil
+}
+
+type spillFile struct {
+	*os.File
+}
+
+func (f spillFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.Name()))
+}
@@ -0,0 +1,126 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+)
+
+// streamingDASReader writes the values of a DASReader in small pieces, flipping their last byte if
+// corrupt is set.
+type streamingDASReader struct {
+	dasutil.DASReader
+	corrupt bool
+}
+
+func (r *streamingDASReader) GetByHashTo(ctx context.Context, hash common.Hash, w io.Writer) (int64, error) {
+	data, err := r.GetByHash(ctx, hash)
+	if err != nil {
+		return 0, err
+	}
+	if r.corrupt {
+		data = bytes.Clone(data)
+		data[len(data)-1] ^= 0xff
+	}
+	var written int64
+	for len(data) > 0 {
+		n, err := w.Write(data[:min(len(data), 1000)])
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		data = data[n:]
+	}
+	return written, nil
+}
+
+func spillDirEntries(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	Require(t, err)
+	return len(entries)
+}
+
+func TestRecoverPayloadReaderSpilling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	keyDir := t.TempDir()
+	_, _, err := GenerateAndStoreKeys(keyDir)
+	Require(t, err)
+	config := DefaultDataAvailabilityConfig
+	config.Key = KeyConfig{KeyDir: keyDir}
+	storageService := NewMemoryBackedStorageService(ctx)
+	signer, err := NewSignAfterStoreDASWriter(ctx, config, storageService)
+	Require(t, err)
+	reader := &streamingDASReader{DASReader: storageService}
+
+	payload := make([]byte, 3*dastree.BinSize+5)
+	_, err = rand.Read(payload)
+	Require(t, err)
+	// #nosec G115
+	timeout := uint64(time.Now().Add(time.Hour * 24).Unix())
+	store := func(version uint8, message []byte) []byte {
+		writer := dasutil.NewWriterForDAS(signer)
+		Require(t, writer.PinCertVersion(version))
+		certBytes, _, err := writer.StoreWithDataHash(ctx, message, timeout, true)
+		Require(t, err)
+		return append(make([]byte, 40), certBytes...)
+	}
+
+	for _, version := range dasutil.WritableCertVersions() {
+		reader.corrupt = false
+		dir := t.TempDir()
+		spill := SpillOptions{Threshold: 4096, Dir: dir}
+		sequencerMsg := store(version, payload)
+
+		recovered, size, err := RecoverPayloadReader(ctx, 1, sequencerMsg, reader, signer, true, dasutil.RecoveryOptions{}, spill)
+		Require(t, err)
+		if size != uint64(len(payload)) {
+			Fail(t, "version", version, "got size", size, "expected", len(payload))
+		}
+		if spillDirEntries(t, dir) != 1 {
+			Fail(t, "version", version, "expected the payload to be spilled to a file")
+		}
+		data, err := io.ReadAll(recovered)
+		Require(t, err)
+		if !bytes.Equal(data, payload) {
+			Fail(t, "version", version, "recovered wrong payload")
+		}
+		Require(t, recovered.Close())
+		if spillDirEntries(t, dir) != 0 {
+			Fail(t, "version", version, "expected the spilled payload to be removed once the reader is closed")
+		}
+
+		// Payloads below the threshold stay in memory.
+		small := []byte("small payload")
+		recovered, _, err = RecoverPayloadReader(ctx, 1, store(version, small), reader, signer, true, dasutil.RecoveryOptions{}, spill)
+		Require(t, err)
+		data, err = io.ReadAll(recovered)
+		Require(t, err)
+		if !bytes.Equal(data, small) || spillDirEntries(t, dir) != 0 {
+			Fail(t, "version", version, "expected the small payload to be recovered without spilling")
+		}
+
+		// The spilled file of a payload failing its hash check is removed.
+		reader.corrupt = true
+		if _, _, err := RecoverPayloadReader(ctx, 1, sequencerMsg, reader, signer, true, dasutil.RecoveryOptions{}, spill); err == nil {
+			Fail(t, "version", version, "expected the corrupted payload to be rejected")
+		}
+		if spillDirEntries(t, dir) != 0 {
+			Fail(t, "version", version, "expected the spilled corrupted payload to be removed")
+		}
+	}
+}
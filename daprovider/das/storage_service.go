@@ -5,6 +5,7 @@ package das
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
@@ -13,26 +14,120 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
 	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
 )
 
 var ErrNotFound = errors.New("not found")
+var ErrDataExpired = errors.New("data has passed its timeout")
+var ErrValueTooLarge = errors.New("value is too large for the storage backend")
+var ErrEntryCountNotSupported = errors.New("storage backend can't report its entry count")
 
 type StorageService interface {
 	dasutil.DASReader
 	Put(ctx context.Context, data []byte, expirationTime uint64) error
+	// ValidatePut runs the checks Put would make before writing the value, without storing it.
+	ValidatePut(ctx context.Context, data []byte) error
+	// Refresh extends the retention of an already stored value to expirationTime. Values already
+	// retained longer keep their retention.
+	Refresh(ctx context.Context, key common.Hash, expirationTime uint64) error
 	Sync(ctx context.Context) error
 	Closer
 	fmt.Stringer
 	HealthCheck(ctx context.Context) error
 }
 
+// KeyedStorageService is a StorageService which can also store a value under an explicit key,
+// rather than under the dastree hash of the value. This lets decorators that transform values,
+// such as EncryptingStorageService, keep them addressable by the hash of the original value.
+type KeyedStorageService interface {
+	StorageService
+	PutWithKey(ctx context.Context, key common.Hash, value []byte, expirationTime uint64) error
+	// ValidatePutSize runs the checks ValidatePut would make for a value of the given size, letting
+	// decorators which store a transformed value check its size without building it.
+	ValidatePutSize(ctx context.Context, size int) error
+}
+
+// PinnableStorageService is a StorageService with timeout-based retention that can keep chosen
+// values regardless of their timeout, e.g. preimages needed for an ongoing challenge. Pins are
+// persisted by the service and survive restarts.
+type PinnableStorageService interface {
+	StorageService
+	// Pin keeps the stored value with the given key until it's unpinned.
+	Pin(ctx context.Context, key common.Hash) error
+	// Unpin restores the timeout-based retention of a pinned value.
+	Unpin(ctx context.Context, key common.Hash) error
+}
+
+// EntryCountingStorageService is a StorageService that can report roughly how many values it holds,
+// e.g. for capacity dashboards.
+type EntryCountingStorageService interface {
+	StorageService
+	// ApproxEntryCount estimates the number of stored values. The estimate may include values that
+	// have expired but haven't been deleted yet.
+	ApproxEntryCount(ctx context.Context) (uint64, error)
+}
+
+// ApproxEntryCount returns the approximate number of values stored in s, or ErrEntryCountNotSupported
+// if it can't report one.
+func ApproxEntryCount(ctx context.Context, s StorageService) (uint64, error) {
+	counting, ok := s.(EntryCountingStorageService)
+	if !ok {
+		return 0, fmt.Errorf("%w: %v", ErrEntryCountNotSupported, s)
+	}
+	return counting.ApproxEntryCount(ctx)
+}
+
+// PutAndHash stores value in s with Put and returns the key it's stored under, its dastree hash,
+// sparing callers that keep track of stored values from hashing them again.
+func PutAndHash(ctx context.Context, s StorageService, value []byte, expirationTime uint64) (common.Hash, error) {
+	return dastree.Hash(value), s.Put(ctx, value, expirationTime)
+}
+
+// checkPutSize is the size check of the ValidatePut implementations of storage backends.
+func checkPutSize(size int, maxSize int64) error {
+	if maxSize > 0 && int64(size) > maxSize {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes", ErrValueTooLarge, size, maxSize)
+	}
+	return nil
+}
+
+// refreshByRestoring is the Refresh implementation of storage services with no cheaper way to extend
+// the retention of a value than storing it again. The stored value is checked against its key first.
+// It relies on Put keeping the later of the timeouts of a value stored again, so that an earlier
+// expirationTime doesn't shorten its retention.
+func refreshByRestoring(ctx context.Context, s StorageService, key common.Hash, expirationTime uint64) error {
+	value, err := s.GetByHash(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !dastree.ValidHash(key, value) {
+		return fmt.Errorf("%w: stored value for key %v", ErrContentHashMismatch, key)
+	}
+	return s.Put(ctx, value, expirationTime)
+}
+
 const defaultStorageRetention = time.Hour * 24 * 21 // 6 days longer than the batch poster default
 
 func EncodeStorageServiceKey(key common.Hash) string {
 	return key.Hex()[2:]
 }
 
+// ParseHashKey parses a hash key received from outside the node, such as in a request path or on
+// the command line, which must be exactly 32 hex-encoded bytes with or without a 0x prefix. Unlike
+// DecodeStorageServiceKey, keys of any other length are rejected rather than padded or truncated.
+func ParseHashKey(input string) (common.Hash, error) {
+	hexKey := strings.TrimPrefix(input, "0x")
+	if len(hexKey) != 2*common.HashLength {
+		return common.Hash{}, fmt.Errorf("hash key %q isn't %d hex-encoded bytes", input, common.HashLength)
+	}
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("hash key %q isn't hex-encoded: %w", input, err)
+	}
+	return common.BytesToHash(key), nil
+}
+
 func DecodeStorageServiceKey(input string) (common.Hash, error) {
 	if !strings.HasPrefix(input, "0x") {
 		input = "0x" + input
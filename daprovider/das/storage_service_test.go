@@ -0,0 +1,116 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+func TestParseHashKey(t *testing.T) {
+	hash := common.HexToHash("0x0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20")
+	for _, input := range []string{hash.Hex(), EncodeStorageServiceKey(hash), strings.ToUpper(EncodeStorageServiceKey(hash))} {
+		parsed, err := ParseHashKey(input)
+		Require(t, err)
+		if parsed != hash {
+			Fail(t, "parsed", input, "as", parsed)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"0x",
+		"0x1234",
+		hash.Hex()[:65],
+		hash.Hex() + "00",
+		"0x" + strings.Repeat("zz", 32),
+		"0x0x" + hash.Hex()[4:],
+		" " + hash.Hex()[1:],
+	}
+	for _, input := range invalid {
+		if _, err := ParseHashKey(input); err == nil {
+			Fail(t, "expected hash key", input, "to be rejected")
+		}
+	}
+}
+
+func TestStorageServiceEmptyValue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// #nosec G115
+	timeout := uint64(time.Now().Add(30 * time.Minute).Unix())
+	emptyHash := dastree.Hash([]byte{})
+
+	dbConfig := DefaultLocalDBStorageConfig
+	dbConfig.DataDir = t.TempDir()
+	dbService, err := NewDBStorageService(ctx, &dbConfig, nil)
+	Require(t, err)
+	defer func() {
+		Require(t, dbService.Close(ctx))
+	}()
+	localFileService, err := NewLocalFileStorageService(LocalFileStorageConfig{
+		Enable:       true,
+		DataDir:      t.TempDir(),
+		MaxRetention: time.Hour,
+	})
+	Require(t, err)
+	server, err := miniredis.Run()
+	Require(t, err)
+	defer server.Close()
+	redisService, err := NewRedisStorageService(RedisConfig{
+		Enable:     true,
+		Url:        "redis://" + server.Addr(),
+		Expiration: time.Hour,
+		KeyConfig:  "b561f5d5d98debc783aa8a1472d67ec3bcd532a1c8d95e5cb23caa70c649f7c9",
+	}, NewMemoryBackedStorageService(ctx))
+	Require(t, err)
+
+	services := []StorageService{
+		NewMemoryBackedStorageService(ctx),
+		dbService,
+		localFileService,
+		redisService,
+		NewCacheStorageService(TestCacheConfig, NewMemoryBackedStorageService(ctx)),
+	}
+	for _, service := range services {
+		if _, err := service.GetByHash(ctx, emptyHash); !errors.Is(err, ErrNotFound) {
+			Fail(t, service, "expected an empty value that wasn't stored to be not found, got", err)
+		}
+		Require(t, service.Put(ctx, []byte{}, timeout))
+		data, err := service.GetByHash(ctx, emptyHash)
+		if err != nil {
+			Fail(t, service, "expected the stored empty value to be found, got", err)
+		}
+		if len(data) != 0 || !dastree.ValidHash(emptyHash, data) {
+			Fail(t, service, "expected an empty value, got", data)
+		}
+	}
+
+	// Empty values are served over REST as such too.
+	storage := NewMemoryBackedStorageService(ctx)
+	restServer, port, err := NewRestfulDasServerOnRandomPort(LocalServerAddressForTest, storage)
+	Require(t, err)
+	defer func() {
+		Require(t, restServer.Shutdown())
+	}()
+	client := NewRestfulDasClient("http", LocalServerAddressForTest, port)
+	if _, err := client.GetByHash(ctx, emptyHash); !errors.Is(err, ErrNotFound) {
+		Fail(t, "expected an empty value that wasn't stored to be not found over REST, got", err)
+	}
+	Require(t, storage.Put(ctx, []byte{}, timeout))
+	data, err := client.GetByHash(ctx, emptyHash)
+	Require(t, err)
+	if len(data) != 0 {
+		Fail(t, "expected an empty value over REST, got", data)
+	}
+}
@@ -172,7 +172,7 @@ func newl1SyncService(config *SyncToStorageConfig, syncTo StorageService, dataSo
 	if err != nil {
 		return nil, err
 	}
-	keysetFetcher, err := NewKeysetFetcher(l1Client, inboxAddr)
+	keysetFetcher, err := NewKeysetFetcher(l1Client, inboxAddr, DefaultKeysetCacheConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -378,7 +378,9 @@ func (s *l1SyncService) mainThread(ctx context.Context) {
 				return
 			}
 			errCount++
-			if errCount > 5 {
+			if errCount > 5 || !IsTransient(err) {
+				// Permanent errors, like a batch whose data can't be recovered, won't clear up
+				// by themselves, so report them without waiting for them to repeat.
 				log.Error("error trying to sync from L1", "err", err)
 			}
 			select {
@@ -435,6 +437,8 @@ func NewSyncingFallbackStorageService(ctx context.Context,
 			true,
 			make(map[[32]byte]bool),
 			sync.RWMutex{},
+			nil,
+			AggregateMostDurable,
 		},
 		syncService,
 	}, nil
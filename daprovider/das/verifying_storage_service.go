@@ -0,0 +1,153 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+	"github.com/offchainlabs/nitro/util/pretty"
+)
+
+var ErrContentHashMismatch = errors.New("value does not match its expected content hash")
+
+// PreimageHashType is the hash function the values of a storage service are keyed by, which depends
+// on the version of the certs the values are stored for.
+type PreimageHashType uint8
+
+const (
+	// AnyPreimageHash keys values by their dastree hash, but accepts values under any key when reading.
+	AnyPreimageHash PreimageHashType = iota
+	// KeccakPreimageHash keys values by their flat keccak hash, as version 0 certs reference them.
+	KeccakPreimageHash
+	// DastreePreimageHash keys values by their dastree hash, as version 1 certs reference them.
+	DastreePreimageHash
+)
+
+func (t PreimageHashType) String() string {
+	switch t {
+	case AnyPreimageHash:
+		return "any"
+	case KeccakPreimageHash:
+		return "keccak"
+	case DastreePreimageHash:
+		return "dastree"
+	default:
+		return fmt.Sprintf("PreimageHashType(%d)", uint8(t))
+	}
+}
+
+// PreimageHashTypeFromString parses the names returned by PreimageHashType.String, for config.
+func PreimageHashTypeFromString(s string) (PreimageHashType, error) {
+	for _, t := range []PreimageHashType{AnyPreimageHash, KeccakPreimageHash, DastreePreimageHash} {
+		if s == t.String() {
+			return t, nil
+		}
+	}
+	return AnyPreimageHash, fmt.Errorf("invalid preimage hash type %q", s)
+}
+
+func (t PreimageHashType) hash(value []byte) common.Hash {
+	if t == KeccakPreimageHash {
+		return crypto.Keccak256Hash(value)
+	}
+	return dastree.Hash(value)
+}
+
+// VerifyingStorageService is a StorageService that lets callers which track the hash of a value
+// separately check it against the value's content address before storing, so that mislabeled data
+// is rejected when it's written rather than surfacing as a hash mismatch when it's read back. If
+// it's given the PreimageHashType of its values, values read back are checked against their keys
+// too, so that a value keyed by one hash function is never served where another one is expected.
+type VerifyingStorageService struct {
+	baseStorageService StorageService
+	preimageType       PreimageHashType
+}
+
+func NewVerifyingStorageService(baseStorageService StorageService) *VerifyingStorageService {
+	return NewVerifyingStorageServiceWithPreimageType(baseStorageService, AnyPreimageHash)
+}
+
+// NewVerifyingStorageServiceWithPreimageType returns a VerifyingStorageService keying values by the
+// given hash function. Keccak keyed values can only be stored in bases supporting explicit keys.
+func NewVerifyingStorageServiceWithPreimageType(baseStorageService StorageService, preimageType PreimageHashType) *VerifyingStorageService {
+	return &VerifyingStorageService{
+		baseStorageService: baseStorageService,
+		preimageType:       preimageType,
+	}
+}
+
+func (v *VerifyingStorageService) GetByHash(ctx context.Context, key common.Hash) ([]byte, error) {
+	log.Trace("das.VerifyingStorageService.GetByHash", "key", pretty.PrettyHash(key), "this", v)
+	value, err := v.baseStorageService.GetByHash(ctx, key)
+	if err != nil || v.preimageType == AnyPreimageHash {
+		return value, err
+	}
+	if hash := v.preimageType.hash(value); hash != key {
+		log.Warn("das.VerifyingStorageService.GetByHash rejecting mismatched value", "key", pretty.PrettyHash(key), "hash", pretty.PrettyHash(hash), "preimageType", v.preimageType)
+		return nil, fmt.Errorf("%w: stored value for key %v has %v hash %v", ErrContentHashMismatch, key, v.preimageType, hash)
+	}
+	return value, nil
+}
+
+func (v *VerifyingStorageService) Put(ctx context.Context, value []byte, timeout uint64) error {
+	logPut("das.VerifyingStorageService.Store", value, timeout, v)
+	return v.put(ctx, v.preimageType.hash(value), value, timeout)
+}
+
+// PutWithExpectedHash stores the value only if its hash is the one the caller expects.
+func (v *VerifyingStorageService) PutWithExpectedHash(ctx context.Context, expectedHash common.Hash, value []byte, timeout uint64) error {
+	logPut("das.VerifyingStorageService.PutWithExpectedHash", value, timeout, v, "expectedHash", pretty.PrettyHash(expectedHash))
+	hash := v.preimageType.hash(value)
+	if hash != expectedHash {
+		return fmt.Errorf("%w: expected %v, got %v", ErrContentHashMismatch, expectedHash, hash)
+	}
+	return v.put(ctx, hash, value, timeout)
+}
+
+func (v *VerifyingStorageService) put(ctx context.Context, key common.Hash, value []byte, timeout uint64) error {
+	if v.preimageType != KeccakPreimageHash {
+		return v.baseStorageService.Put(ctx, value, timeout)
+	}
+	keyedBase, ok := v.baseStorageService.(KeyedStorageService)
+	if !ok {
+		return fmt.Errorf("base storage %v doesn't support explicit keys", v.baseStorageService)
+	}
+	return keyedBase.PutWithKey(ctx, key, value, timeout)
+}
+
+func (v *VerifyingStorageService) ValidatePut(ctx context.Context, value []byte) error {
+	return v.baseStorageService.ValidatePut(ctx, value)
+}
+
+func (v *VerifyingStorageService) Refresh(ctx context.Context, key common.Hash, timeout uint64) error {
+	return v.baseStorageService.Refresh(ctx, key, timeout)
+}
+
+func (v *VerifyingStorageService) Sync(ctx context.Context) error {
+	return v.baseStorageService.Sync(ctx)
+}
+
+func (v *VerifyingStorageService) Close(ctx context.Context) error {
+	return v.baseStorageService.Close(ctx)
+}
+
+func (v *VerifyingStorageService) ExpirationPolicy(ctx context.Context) (dasutil.ExpirationPolicy, error) {
+	return v.baseStorageService.ExpirationPolicy(ctx)
+}
+
+func (v *VerifyingStorageService) String() string {
+	return fmt.Sprintf("VerifyingStorageService(%v, %v)", v.baseStorageService, v.preimageType)
+}
+
+func (v *VerifyingStorageService) HealthCheck(ctx context.Context) error {
+	return v.baseStorageService.HealthCheck(ctx)
+}
@@ -0,0 +1,98 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+func TestVerifyingStorageServiceRejectsMismatchedHash(t *testing.T) {
+	ctx := context.Background()
+	base := NewMemoryBackedStorageService(ctx)
+	service := NewVerifyingStorageService(base)
+
+	value := []byte("some batch data")
+	otherValue := []byte("some other batch data")
+
+	err := service.PutWithExpectedHash(ctx, dastree.Hash(otherValue), value, 1)
+	if !errors.Is(err, ErrContentHashMismatch) {
+		Fail(t, "expected mismatched hash to be rejected, got", err)
+	}
+	for _, key := range [][]byte{value, otherValue} {
+		if _, err := base.GetByHash(ctx, dastree.Hash(key)); !errors.Is(err, ErrNotFound) {
+			Fail(t, "rejected value was stored", err)
+		}
+	}
+
+	Require(t, service.PutWithExpectedHash(ctx, dastree.Hash(value), value, 1))
+	stored, err := service.GetByHash(ctx, dastree.Hash(value))
+	Require(t, err)
+	if !bytes.Equal(stored, value) {
+		Fail(t, "unexpected stored value", stored)
+	}
+}
+
+func TestVerifyingStorageServicePreimageType(t *testing.T) {
+	ctx := context.Background()
+	base := NewMemoryBackedStorageService(ctx)
+	keccakValue := []byte("version 0 batch data")
+	dastreeValue := []byte("version 1 batch data")
+	Require(t, base.(KeyedStorageService).PutWithKey(ctx, crypto.Keccak256Hash(keccakValue), keccakValue, 1))
+	Require(t, base.Put(ctx, dastreeValue, 1))
+
+	keccakService := NewVerifyingStorageServiceWithPreimageType(base, KeccakPreimageHash)
+	dastreeService := NewVerifyingStorageServiceWithPreimageType(base, DastreePreimageHash)
+	anyService := NewVerifyingStorageService(base)
+	testCases := []struct {
+		service *VerifyingStorageService
+		key     common.Hash
+		valid   bool
+	}{
+		{keccakService, crypto.Keccak256Hash(keccakValue), true},
+		{keccakService, dastree.Hash(dastreeValue), false},
+		{dastreeService, dastree.Hash(dastreeValue), true},
+		{dastreeService, crypto.Keccak256Hash(keccakValue), false},
+		{anyService, crypto.Keccak256Hash(keccakValue), true},
+		{anyService, dastree.Hash(dastreeValue), true},
+	}
+	for _, tc := range testCases {
+		_, err := tc.service.GetByHash(ctx, tc.key)
+		if tc.valid {
+			Require(t, err, tc.service, tc.key)
+		} else if !errors.Is(err, ErrContentHashMismatch) {
+			Fail(t, tc.service, "served a value of another preimage type for", tc.key, "err", err)
+		}
+	}
+
+	// Values are stored under the hash of the service's preimage type.
+	value := []byte("another version 0 batch")
+	Require(t, keccakService.Put(ctx, value, 1))
+	stored, err := keccakService.GetByHash(ctx, crypto.Keccak256Hash(value))
+	Require(t, err)
+	if !bytes.Equal(stored, value) {
+		Fail(t, "unexpected stored value", stored)
+	}
+	if err := keccakService.PutWithExpectedHash(ctx, dastree.Hash(value), value, 1); !errors.Is(err, ErrContentHashMismatch) {
+		Fail(t, "expected the dastree hash of a keccak keyed value to be rejected, got", err)
+	}
+
+	for _, name := range []string{"any", "keccak", "dastree"} {
+		parsed, err := PreimageHashTypeFromString(name)
+		Require(t, err)
+		if parsed.String() != name {
+			Fail(t, "parsed", name, "as", parsed)
+		}
+	}
+	if _, err := PreimageHashTypeFromString("sha256"); err == nil {
+		Fail(t, "expected an unknown preimage type to be rejected")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+	"github.com/offchainlabs/nitro/daprovider/das/dasutil"
+	"github.com/offchainlabs/nitro/util/pretty"
+)
+
+// Version0MigrationResult reports the progress of MigrateVersion0Data.
+type Version0MigrationResult struct {
+	Migrated int
+	// Skipped counts entries the target already held under both of their new keys.
+	Skipped int
+	// Missing counts entries the source doesn't have, e.g. because they expired.
+	Missing int
+	// Last is the flat hash of the last entry processed, from which an interrupted migration can
+	// be resumed.
+	Last common.Hash
+}
+
+// MigrateVersion0Data re-indexes data stored under the flat keccak hashes of version 0 certs.
+// Each entry is read from source and written to target under both the key version 0 recovery
+// looks up first, dastree.FlatHashToTreeHash of its flat hash, and its dastree hash, under which
+// version 1 certs for the same data find it.
+//
+// The flat hashes are processed in ascending order, starting after resumeAfter if it's set. Entries
+// the target already has are skipped, so rerunning a migration, interrupted or not, is safe. On error,
+// the result reports the progress made before it.
+func MigrateVersion0Data(
+	ctx context.Context,
+	source DataAvailabilityServiceReader,
+	target KeyedStorageService,
+	flatHashes []common.Hash,
+	resumeAfter *common.Hash,
+	timeout uint64,
+) (Version0MigrationResult, error) {
+	var result Version0MigrationResult
+	hashes := append([]common.Hash{}, flatHashes...)
+	sortHashes(hashes)
+	for _, flatHash := range hashes {
+		if resumeAfter != nil && flatHash.Cmp(*resumeAfter) <= 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		migrated, err := migrateVersion0Entry(ctx, source, target, flatHash, timeout)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			log.Warn("Version 0 data missing from migration source", "hash", pretty.PrettyHash(flatHash))
+			result.Missing++
+		case err != nil:
+			return result, fmt.Errorf("failed to migrate version 0 data %v: %w", flatHash, err)
+		case migrated:
+			result.Migrated++
+		default:
+			result.Skipped++
+		}
+		result.Last = flatHash
+	}
+	log.Info("Version 0 data migration complete", "migrated", result.Migrated, "skipped", result.Skipped, "missing", result.Missing)
+	return result, nil
+}
+
+func migrateVersion0Entry(
+	ctx context.Context,
+	source DataAvailabilityServiceReader,
+	target KeyedStorageService,
+	flatHash common.Hash,
+	timeout uint64,
+) (bool, error) {
+	treeKey := dastree.FlatHashToTreeHash(flatHash)
+	if data, err := target.GetByHash(ctx, treeKey); err == nil {
+		if _, err := target.GetByHash(ctx, dastree.Hash(data)); err == nil {
+			return false, nil
+		}
+	}
+	data, err := source.GetByHash(ctx, flatHash)
+	if err != nil {
+		return false, err
+	}
+	if crypto.Keccak256Hash(data) != flatHash {
+		return false, fmt.Errorf("%w: source data doesn't match its flat hash", dasutil.ErrHashMismatch)
+	}
+	if err := target.PutWithKey(ctx, treeKey, data, timeout); err != nil {
+		return false, err
+	}
+	if err := target.Put(ctx, data, timeout); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/daprovider/das/dastree"
+)
+
+func TestMigrateVersion0Data(t *testing.T) {
+	ctx := context.Background()
+	source := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+	target := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+
+	var values [][]byte
+	var flatHashes []common.Hash
+	for i := 0; i < 5; i++ {
+		value := []byte{byte(i), 'v', '0'}
+		flatHash := crypto.Keccak256Hash(value)
+		Require(t, source.PutWithKey(ctx, flatHash, value, 0))
+		values = append(values, value)
+		flatHashes = append(flatHashes, flatHash)
+	}
+	missing := crypto.Keccak256Hash([]byte("expired v0 data"))
+	flatHashes = append(flatHashes, missing)
+
+	// Migrate part of the data, as if interrupted, then resume from where it stopped.
+	sorted := append([]common.Hash{}, flatHashes...)
+	sortHashes(sorted)
+	partial, err := MigrateVersion0Data(ctx, source, target, sorted[:2], nil, 0)
+	Require(t, err)
+	resumed, err := MigrateVersion0Data(ctx, source, target, flatHashes, &partial.Last, 0)
+	Require(t, err)
+	if partial.Migrated+resumed.Migrated != len(values) || resumed.Missing != 1 || resumed.Skipped != 0 {
+		Fail(t, "unexpected migration results", partial, resumed)
+	}
+
+	for i, value := range values {
+		for _, key := range []common.Hash{dastree.FlatHashToTreeHash(flatHashes[i]), dastree.Hash(value)} {
+			data, err := target.GetByHash(ctx, key)
+			Require(t, err)
+			if !bytes.Equal(data, value) {
+				Fail(t, "migrated data doesn't match", data, value)
+			}
+		}
+	}
+
+	// Rerunning the whole migration changes nothing.
+	rerun, err := MigrateVersion0Data(ctx, source, target, flatHashes, nil, 0)
+	Require(t, err)
+	if rerun.Migrated != 0 || rerun.Skipped != len(values) || rerun.Missing != 1 {
+		Fail(t, "expected rerun to skip migrated data", rerun)
+	}
+
+	// Corrupt source data isn't migrated.
+	corrupt := crypto.Keccak256Hash([]byte("original"))
+	Require(t, source.PutWithKey(ctx, corrupt, []byte("corrupted"), 0))
+	if _, err := MigrateVersion0Data(ctx, source, target, []common.Hash{corrupt}, nil, 0); err == nil {
+		Fail(t, "expected corrupt source data to fail the migration")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package daprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrUnknownProvider = errors.New("unknown DA provider")
+
+// ProviderFactory constructs the Reader and Writer of a DA provider.
+// Either may be nil if the provider doesn't support reading or writing.
+type ProviderFactory func(ctx context.Context) (Reader, Writer, error)
+
+// Registry allows a node to select among multiple DA provider implementations by name.
+type Registry struct {
+	mutex     sync.RWMutex
+	factories map[string]ProviderFactory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]ProviderFactory),
+	}
+}
+
+// Register adds a DA provider under the given name, failing if the name is already taken.
+func (r *Registry) Register(name string, factory ProviderFactory) error {
+	if name == "" {
+		return errors.New("DA provider name must not be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("DA provider %s has no factory", name)
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, exists := r.factories[name]; exists {
+		return fmt.Errorf("DA provider %s is already registered", name)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// Names returns the sorted names of all registered DA providers.
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve constructs the Reader and Writer of the DA provider registered under name.
+func (r *Registry) Resolve(ctx context.Context, name string) (Reader, Writer, error) {
+	r.mutex.RLock()
+	factory, ok := r.factories[name]
+	r.mutex.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return factory(ctx)
+}
@@ -0,0 +1,86 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package daprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type stubProvider struct {
+	name string
+}
+
+func (s *stubProvider) IsValidHeaderByte(ctx context.Context, headerByte byte) bool {
+	return false
+}
+
+func (s *stubProvider) RecoverPayloadFromBatch(
+	ctx context.Context,
+	batchNum uint64,
+	batchBlockHash common.Hash,
+	sequencerMsg []byte,
+	preimages PreimagesMap,
+	validateSeqMsg bool,
+) ([]byte, PreimagesMap, error) {
+	return []byte(s.name), preimages, nil
+}
+
+func (s *stubProvider) Store(ctx context.Context, message []byte, timeout uint64, disableFallbackStoreDataOnChain bool) ([]byte, error) {
+	return []byte(s.name), nil
+}
+
+func stubFactory(name string) ProviderFactory {
+	return func(ctx context.Context) (Reader, Writer, error) {
+		provider := &stubProvider{name: name}
+		return provider, provider, nil
+	}
+}
+
+func TestRegistryResolvesByName(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	if err := registry.Register("first", stubFactory("first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("second", stubFactory("second")); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("first", stubFactory("other")); err == nil {
+		t.Fatal("expected duplicate registration to fail")
+	}
+
+	names := registry.Names()
+	if len(names) != 2 || names[0] != "first" || names[1] != "second" {
+		t.Fatal("unexpected provider names", names)
+	}
+
+	for _, name := range names {
+		reader, writer, err := registry.Resolve(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload, _, err := reader.RecoverPayloadFromBatch(ctx, 0, common.Hash{}, nil, nil, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(payload) != name {
+			t.Fatal("reader resolved to wrong provider", string(payload), name)
+		}
+		msg, err := writer.Store(ctx, nil, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg) != name {
+			t.Fatal("writer resolved to wrong provider", string(msg), name)
+		}
+	}
+
+	if _, _, err := registry.Resolve(ctx, "missing"); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatal("expected ErrUnknownProvider, got", err)
+	}
+}
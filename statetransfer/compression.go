@@ -0,0 +1,92 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package statetransfer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// InitDataCompression selects how WriteJsonInitData compresses the files it writes. Readers detect
+// compressed files from their contents, so compressed and uncompressed files can be mixed.
+type InitDataCompression uint8
+
+const (
+	NoInitDataCompression InitDataCompression = iota
+	GzipInitDataCompression
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// maybeDecompress returns a reader of the decompressed contents of r if they start with the gzip
+// magic bytes, along with the decompressor to close once done, or a reader of r's contents as is.
+func maybeDecompress(r io.Reader) (io.Reader, io.Closer, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	if !bytes.Equal(magic, gzipMagic) {
+		return buffered, nil, nil
+	}
+	decompressor, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decompressor, decompressor, nil
+}
+
+func readMaybeCompressedFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	input, decompressor, err := maybeDecompress(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	if decompressor != nil {
+		defer decompressor.Close()
+	}
+	return io.ReadAll(input)
+}
+
+// compressingFile is a file written through the compression of WriteJsonInitData.
+type compressingFile struct {
+	io.Writer
+	file       *os.File
+	compressor *gzip.Writer
+}
+
+func createCompressingFile(path string, compression InitDataCompression) (*compressingFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	switch compression {
+	case NoInitDataCompression:
+		return &compressingFile{Writer: file, file: file}, nil
+	case GzipInitDataCompression:
+		compressor := gzip.NewWriter(file)
+		return &compressingFile{Writer: compressor, file: file, compressor: compressor}, nil
+	default:
+		file.Close()
+		return nil, fmt.Errorf("unknown init data compression %d", compression)
+	}
+}
+
+func (f *compressingFile) Close() error {
+	if f.compressor != nil {
+		if err := f.compressor.Close(); err != nil {
+			f.file.Close()
+			return err
+		}
+	}
+	return f.file.Close()
+}
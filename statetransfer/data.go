@@ -15,6 +15,11 @@ type ArbosInitializationInfo struct {
 	RetryableData        []InitializationDataForRetryable
 	Accounts             []AccountInitializationInfo
 	ChainOwner           common.Address
+	// PrecompileStorageOverrides maps a precompile (or ArbOS state) address to storage slots
+	// that are written after ArbOS is initialized, overriding the values it set up.
+	PrecompileStorageOverrides map[common.Address]map[common.Hash]common.Hash `json:",omitempty"`
+	// CodeBlobs holds the code of contracts referencing it by hash rather than including it inline.
+	CodeBlobs map[common.Hash][]byte `json:",omitempty"`
 }
 
 type InitializationDataForRetryable struct {
@@ -38,7 +43,10 @@ type AccountInitializationInfo struct {
 }
 
 type AccountInitContractInfo struct {
-	Code            []byte
+	Code []byte
+	// CodeHash, if set without inline Code, references the code by its keccak hash in the code blobs
+	// of the init data, so that code shared by many contracts is only included once.
+	CodeHash        *common.Hash `json:",omitempty"`
 	ContractStorage map[common.Hash]common.Hash
 }
 
@@ -18,6 +18,9 @@ type InitDataReader interface {
 	GetRetryableDataReader() (RetryableDataReader, error)
 	GetAccountDataReader() (AccountDataReader, error)
 	GetChainOwner() (common.Address, error)
+	GetPrecompileStorageOverrides() (map[common.Address]map[common.Hash]common.Hash, error)
+	// GetCodeByHash returns the code blob with the given hash, which callers are left to validate.
+	GetCodeByHash(hash common.Hash) ([]byte, error)
 }
 
 type ListReader interface {
@@ -4,11 +4,15 @@
 package statetransfer
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
 	"path"
+	"sort"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -18,6 +22,19 @@ type ArbosInitFileContents struct {
 	AddressTableContentsPath string `json:"AddressTableContentsPath"`
 	RetryableDataPath        string `json:"RetryableDataPath"`
 	AccountsPath             string `json:"AccountsPath"`
+
+	PrecompileStorageOverrides map[common.Address]map[common.Hash]common.Hash `json:"PrecompileStorageOverrides,omitempty"`
+
+	// The lists can instead be split across shard files, which are read in order as a single list. A
+	// shard that's a directory stands for the files in it, ordered by name with runs of digits
+	// compared as numbers, so that accounts-10.json follows accounts-9.json.
+	AddressTableContentsShards []string `json:"AddressTableContentsShards,omitempty"`
+	RetryableDataShards        []string `json:"RetryableDataShards,omitempty"`
+	AccountsShards             []string `json:"AccountsShards,omitempty"`
+
+	// CodeBlobsDir is the directory holding the code referenced by hash from the accounts, in files
+	// named by the hex encoding of the code's keccak hash, without a 0x prefix.
+	CodeBlobsDir string `json:"CodeBlobsDir,omitempty"`
 }
 
 type JsonInitDataReader struct {
@@ -32,17 +49,62 @@ func (r *JsonInitDataReader) GetNextBlockNumber() (uint64, error) {
 type JsonListReader struct {
 	input *json.Decoder
 	file  *os.File
+	// decompressor is the reader decompressing the file, if it's compressed.
+	decompressor io.Closer
+	// shards are the files to read once the current one is exhausted.
+	shards []string
+	// err is the failure to open the next shard, returned by the following GetNext.
+	err error
 }
 
 func (l *JsonListReader) More() bool {
-	if l.input == nil {
-		return false
+	for {
+		if l.err != nil {
+			return true
+		}
+		if l.input != nil && l.input.More() {
+			return true
+		}
+		if len(l.shards) == 0 {
+			return false
+		}
+		l.err = l.openNextShard()
+	}
+}
+
+func (l *JsonListReader) openNextShard() error {
+	if err := l.closeFile(); err != nil {
+		return err
+	}
+	var shard string
+	shard, l.shards = l.shards[0], l.shards[1:]
+	file, err := os.OpenFile(shard, os.O_RDONLY, 0664)
+	if err != nil {
+		return err
 	}
-	return l.input.More()
+	l.file = file
+	input, decompressor, err := maybeDecompress(file)
+	if err != nil {
+		return fmt.Errorf("failed to decompress %s: %w", shard, err)
+	}
+	l.decompressor = decompressor
+	l.input = json.NewDecoder(input)
+	return nil
 }
 
 func (l *JsonListReader) Close() error {
+	l.shards = nil
+	return l.closeFile()
+}
+
+func (l *JsonListReader) closeFile() error {
 	l.input = nil
+	if l.decompressor != nil {
+		if err := l.decompressor.Close(); err != nil {
+			return err
+		}
+		l.decompressor = nil
+	}
 	if l.file != nil {
 		if err := l.file.Close(); err != nil {
 			return err
@@ -52,24 +114,80 @@ func (l *JsonListReader) Close() error {
 	return nil
 }
 
-func (r *JsonInitDataReader) getListReader(fileName string) (JsonListReader, error) {
-	if fileName == "" {
-		return JsonListReader{}, nil
+func (r *JsonInitDataReader) getListReader(fileName string, shards []string) (JsonListReader, error) {
+	if fileName != "" && len(shards) > 0 {
+		return JsonListReader{}, fmt.Errorf("both a list file %s and shards %v given", fileName, shards)
 	}
-	filePath := path.Join(r.basePath, fileName)
-	inboundFile, err := os.OpenFile(filePath, os.O_RDONLY, 0664)
-	if err != nil {
-		return JsonListReader{}, err
+	if fileName != "" {
+		shards = []string{fileName}
 	}
-	res := JsonListReader{
-		file:  inboundFile,
-		input: json.NewDecoder(inboundFile),
+	var files []string
+	for _, shard := range shards {
+		shardPath := path.Join(r.basePath, shard)
+		info, err := os.Stat(shardPath)
+		if err != nil {
+			return JsonListReader{}, err
+		}
+		if !info.IsDir() {
+			files = append(files, shardPath)
+			continue
+		}
+		entries, err := os.ReadDir(shardPath)
+		if err != nil {
+			return JsonListReader{}, err
+		}
+		var names []string
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		sort.Slice(names, func(i, j int) bool { return shardNameLess(names[i], names[j]) })
+		for _, name := range names {
+			files = append(files, path.Join(shardPath, name))
+		}
+	}
+	return JsonListReader{shards: files}, nil
+}
+
+// shardNameLess orders shard file names with runs of digits compared as numbers.
+func shardNameLess(a, b string) bool {
+	for a != "" && b != "" {
+		aDigits, bDigits := leadingDigits(a), leadingDigits(b)
+		if aDigits != "" && bDigits != "" {
+			aNum, bNum := strings.TrimLeft(aDigits, "0"), strings.TrimLeft(bDigits, "0")
+			if len(aNum) != len(bNum) {
+				return len(aNum) < len(bNum)
+			}
+			if aNum != bNum {
+				return aNum < bNum
+			}
+			if aDigits != bDigits {
+				return aDigits < bDigits
+			}
+			a, b = a[len(aDigits):], b[len(bDigits):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
 	}
-	return res, nil
+	return s[:i]
 }
 
+// NewJsonInitDataReader reads the init file at filepath and the files it references, any of which
+// may be gzip compressed, as detected from their contents.
 func NewJsonInitDataReader(filepath string) (InitDataReader, error) {
-	data, err := os.ReadFile(filepath)
+	data, err := readMaybeCompressedFile(filepath)
 	if err != nil {
 		return nil, err
 	}
@@ -112,6 +230,9 @@ func (r *JsonRetryableDataReader) GetNext() (*InitializationDataForRetryable, er
 	if !r.More() {
 		return nil, errNoMore
 	}
+	if r.err != nil {
+		return nil, r.err
+	}
 	var elem InitializationDataForRetryableJson
 	if err := r.input.Decode(&elem); err != nil {
 		return nil, fmt.Errorf("decoding retryable: %w", err)
@@ -132,7 +253,7 @@ func (r *JsonRetryableDataReader) GetNext() (*InitializationDataForRetryable, er
 }
 
 func (r *JsonInitDataReader) GetRetryableDataReader() (RetryableDataReader, error) {
-	listreader, err := r.getListReader(r.data.RetryableDataPath)
+	listreader, err := r.getListReader(r.data.RetryableDataPath, r.data.RetryableDataShards)
 	if err != nil {
 		return nil, err
 	}
@@ -149,6 +270,9 @@ func (r *JsonAddressReader) GetNext() (*common.Address, error) {
 	if !r.More() {
 		return nil, errNoMore
 	}
+	if r.err != nil {
+		return nil, r.err
+	}
 	var elem common.Address
 	if err := r.input.Decode(&elem); err != nil {
 		return nil, err
@@ -157,7 +281,7 @@ func (r *JsonAddressReader) GetNext() (*common.Address, error) {
 }
 
 func (r *JsonInitDataReader) GetAddressTableReader() (AddressReader, error) {
-	listreader, err := r.getListReader(r.data.AddressTableContentsPath)
+	listreader, err := r.getListReader(r.data.AddressTableContentsPath, r.data.AddressTableContentsShards)
 	if err != nil {
 		return nil, err
 	}
@@ -182,6 +306,9 @@ func (r *JsonAccountDataReaderr) GetNext() (*AccountInitializationInfo, error) {
 	if !r.More() {
 		return nil, errNoMore
 	}
+	if r.err != nil {
+		return nil, r.err
+	}
 	var elem AccountInitializationInfoJson
 	if err := r.input.Decode(&elem); err != nil {
 		return nil, err
@@ -202,7 +329,7 @@ func (r *JsonAccountDataReaderr) GetNext() (*AccountInitializationInfo, error) {
 }
 
 func (r *JsonInitDataReader) GetAccountDataReader() (AccountDataReader, error) {
-	listreader, err := r.getListReader(r.data.AccountsPath)
+	listreader, err := r.getListReader(r.data.AccountsPath, r.data.AccountsShards)
 	if err != nil {
 		return nil, err
 	}
@@ -214,3 +341,18 @@ func (r *JsonInitDataReader) GetAccountDataReader() (AccountDataReader, error) {
 func (r *JsonInitDataReader) GetChainOwner() (common.Address, error) {
 	return common.Address{}, nil
 }
+
+func (r *JsonInitDataReader) GetPrecompileStorageOverrides() (map[common.Address]map[common.Hash]common.Hash, error) {
+	return r.data.PrecompileStorageOverrides, nil
+}
+
+func (r *JsonInitDataReader) GetCodeByHash(hash common.Hash) ([]byte, error) {
+	if r.data.CodeBlobsDir == "" {
+		return nil, fmt.Errorf("code blob %v referenced, but no code blob directory given", hash)
+	}
+	code, err := readMaybeCompressedFile(path.Join(r.basePath, r.data.CodeBlobsDir, hex.EncodeToString(hash.Bytes())))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read code blob %v: %w", hash, err)
+	}
+	return code, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package statetransfer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+)
+
+// WriteJsonInitData writes init data to dir in the format read by NewJsonInitDataReader, returning the
+// path of the init file to read it from. The files are compressed as selected by compression, and are
+// given a .gz suffix if they're compressed, except code blobs, whose names are their hashes. Compressed
+// files decompress to exactly the files written without compression. The chain owner isn't part of
+// the format, so it isn't written.
+func WriteJsonInitData(dir string, data *ArbosInitializationInfo, compression InitDataCompression) (string, error) {
+	suffix := ""
+	if compression == GzipInitDataCompression {
+		suffix = ".gz"
+	}
+	contents := ArbosInitFileContents{
+		NextBlockNumber:            data.NextBlockNumber,
+		AddressTableContentsPath:   "addresses.json" + suffix,
+		RetryableDataPath:          "retryables.json" + suffix,
+		AccountsPath:               "accounts.json" + suffix,
+		PrecompileStorageOverrides: data.PrecompileStorageOverrides,
+	}
+	if err := writeJsonInitList(filepath.Join(dir, contents.AddressTableContentsPath), compression, data.AddressTableContents); err != nil {
+		return "", err
+	}
+	retryables := make([]InitializationDataForRetryableJson, 0, len(data.RetryableData))
+	for _, retryable := range data.RetryableData {
+		retryables = append(retryables, InitializationDataForRetryableJson{
+			Id:          retryable.Id,
+			Timeout:     retryable.Timeout,
+			From:        retryable.From,
+			To:          retryable.To,
+			Callvalue:   bigToString(retryable.Callvalue),
+			Beneficiary: retryable.Beneficiary,
+			Calldata:    retryable.Calldata,
+		})
+	}
+	if err := writeJsonInitList(filepath.Join(dir, contents.RetryableDataPath), compression, retryables); err != nil {
+		return "", err
+	}
+	accounts := make([]AccountInitializationInfoJson, 0, len(data.Accounts))
+	for _, account := range data.Accounts {
+		accounts = append(accounts, AccountInitializationInfoJson{
+			Addr:         account.Addr,
+			Nonce:        account.Nonce,
+			Balance:      bigToString(account.EthBalance),
+			ContractInfo: account.ContractInfo,
+			ClassicHash:  account.ClassicHash,
+		})
+	}
+	if err := writeJsonInitList(filepath.Join(dir, contents.AccountsPath), compression, accounts); err != nil {
+		return "", err
+	}
+	if len(data.CodeBlobs) > 0 {
+		contents.CodeBlobsDir = "code"
+		codeDir := filepath.Join(dir, contents.CodeBlobsDir)
+		if err := os.MkdirAll(codeDir, 0755); err != nil {
+			return "", err
+		}
+		for hash, code := range data.CodeBlobs {
+			if err := writeCompressedFile(filepath.Join(codeDir, hex.EncodeToString(hash.Bytes())), compression, code); err != nil {
+				return "", err
+			}
+		}
+	}
+	initFileContents, err := json.Marshal(&contents)
+	if err != nil {
+		return "", err
+	}
+	initFile := filepath.Join(dir, "init.json"+suffix)
+	if err := writeCompressedFile(initFile, compression, initFileContents); err != nil {
+		return "", err
+	}
+	return initFile, nil
+}
+
+func bigToString(value *big.Int) string {
+	if value == nil {
+		return "0"
+	}
+	return value.String()
+}
+
+func writeJsonInitList[T any](path string, compression InitDataCompression, values []T) error {
+	file, err := createCompressingFile(path, compression)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for i := range values {
+		if err := encoder.Encode(&values[i]); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+func writeCompressedFile(path string, compression InitDataCompression, data []byte) error {
+	file, err := createCompressingFile(path, compression)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
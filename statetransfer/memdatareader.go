@@ -4,6 +4,8 @@
 package statetransfer
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -103,6 +105,18 @@ func (r *MemoryInitDataReader) GetChainOwner() (common.Address, error) {
 	return r.d.ChainOwner, nil
 }
 
+func (r *MemoryInitDataReader) GetPrecompileStorageOverrides() (map[common.Address]map[common.Hash]common.Hash, error) {
+	return r.d.PrecompileStorageOverrides, nil
+}
+
+func (r *MemoryInitDataReader) GetCodeByHash(hash common.Hash) ([]byte, error) {
+	code, ok := r.d.CodeBlobs[hash]
+	if !ok {
+		return nil, fmt.Errorf("code blob %v not found", hash)
+	}
+	return code, nil
+}
+
 func (r *MemoryInitDataReader) Close() error {
 	return nil
 }
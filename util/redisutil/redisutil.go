@@ -1,6 +1,8 @@
 package redisutil
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
@@ -15,6 +17,24 @@ import (
 // RedisClientFromURL creates a new Redis client based on the provided URL.
 // The URL scheme can be either `redis` or `redis+sentinel`.
 func RedisClientFromURL(redisUrl string) (redis.UniversalClient, error) {
+	return RedisClientFromURLWithDialer(redisUrl, nil)
+}
+
+// RedisClientFromURLWithDialer is RedisClientFromURL with the client's connections made by dialer,
+// e.g. to route them through a proxy. A nil dialer connects directly.
+func RedisClientFromURLWithDialer(redisUrl string, dialer func(ctx context.Context, network, addr string) (net.Conn, error)) (redis.UniversalClient, error) {
+	return RedisClientFromURLWithCredentials(redisUrl, dialer, nil)
+}
+
+// CredentialsProvider returns the username and password to authenticate a new connection with, such
+// as a short-lived IAM token. An empty username authenticates as the default user.
+type CredentialsProvider func(ctx context.Context) (username string, password string, err error)
+
+// RedisClientFromURLWithCredentials is RedisClientFromURLWithDialer with each new connection, including
+// reconnects, authenticated with the credentials returned by credentials at the time, which take
+// precedence over those in the URL. A nil provider authenticates with the URL's credentials. Providers
+// aren't supported for `redis+sentinel` URLs.
+func RedisClientFromURLWithCredentials(redisUrl string, dialer func(ctx context.Context, network, addr string) (net.Conn, error), credentials CredentialsProvider) (redis.UniversalClient, error) {
 	if redisUrl == "" {
 		return nil, nil
 	}
@@ -23,16 +43,24 @@ func RedisClientFromURL(redisUrl string) (redis.UniversalClient, error) {
 		return nil, err
 	}
 	if u.Scheme == "redis+sentinel" {
+		if credentials != nil {
+			return nil, errors.New("redis: credentials providers aren't supported for redis+sentinel urls")
+		}
 		redisOptions, err := parseFailoverRedisUrl(redisUrl)
 		if err != nil {
 			return nil, err
 		}
+		redisOptions.Dialer = dialer
 		return redis.NewFailoverClient(redisOptions), nil
 	}
 	redisOptions, err := redis.ParseURL(redisUrl)
 	if err != nil {
 		return nil, err
 	}
+	redisOptions.Dialer = dialer
+	if credentials != nil {
+		redisOptions.CredentialsProviderContext = credentials
+	}
 	return redis.NewClient(redisOptions), nil
 }
 
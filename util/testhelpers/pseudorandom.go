@@ -16,7 +16,7 @@ type PseudoRandomDataSource struct {
 
 // NewPseudoRandomDataSource is the pseudorandom source that repeats on different executions
 // T param is to make sure it's only used in testing
-func NewPseudoRandomDataSource(_ *testing.T, seed int64) *PseudoRandomDataSource {
+func NewPseudoRandomDataSource(_ testing.TB, seed int64) *PseudoRandomDataSource {
 	return &PseudoRandomDataSource{
 		rand: rand.New(rand.NewSource(seed)),
 	}